@@ -1,11 +1,23 @@
 package main
 
 import (
+	"context"
 	routes "github.com/gatimugabriel/hotel-reservation-system/internal/api/router"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/config"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment"
+	paymentRepository "github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment/repository"
+	reservationRepository "github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/repository"
+	reservationServices "github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/services"
+	roomRepository "github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/repository"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/infrastructure/database"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/middleware"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/server/httpServer"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 )
 
 var configurations *config.Config
@@ -19,16 +31,52 @@ func init() {
 }
 
 func main() {
+	// stop background jobs & the server on Ctrl+C or when the container is stopped
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// connect to DB
 	dbService, err := database.NewDatabaseService(configurations)
 	if err != nil {
 		panic("failed to connect to database!")
 	}
 
+	// payment processor charging guests, chosen from the configuration
+	gateway, err := payment.NewGateway(configurations.Payment.Gateway, configurations.Server.Environment)
+	if err != nil {
+		panic("failed to set up payment gateway: " + err.Error())
+	}
+
+	// background jobs
+	var jobs sync.WaitGroup
+	reservationRepo := reservationRepository.NewReservationRepository(dbService)
+	waitlistService := reservationServices.NewWaitlistService(
+		reservationRepository.NewWaitlistRepository(dbService),
+		reservationRepo,
+		roomRepository.NewRoomTypeRepository(dbService),
+		configurations.Reservation.WaitlistOfferTTL,
+	)
+	paymentService := payment.NewPaymentService(paymentRepository.NewPaymentRepository(dbService), gateway)
+	holdSweeper := reservationServices.NewHoldSweeper(reservationRepo, waitlistService, paymentService, configurations.Reservation.HoldSweepInterval)
+	roomAllocator := reservationServices.NewRoomAllocator(reservationRepo, roomRepository.NewRoomRepository(dbService))
+	idempotencyStore := database.NewIdempotencyStore(dbService)
+	purgeIdempotencyKeys := func(ctx context.Context) { idempotencyStore.Run(ctx, time.Hour) }
+	for _, job := range []func(context.Context){holdSweeper.Run, roomAllocator.Run, purgeIdempotencyKeys} {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			job(ctx)
+		}()
+	}
+
 	//router setup
 	router := http.NewServeMux()
-	routes.RegisterRouter(dbService, router)
+	routes.RegisterRouter(configurations, dbService, router, gateway)
+
+	// start server; retried POSTs carrying an Idempotency-Key get the first response back
+	httpServer.StartServer(ctx, configurations, middleware.Idempotency(idempotencyStore)(router))
 
-	// start server
-	httpServer.StartServer(configurations, router)
+	// let running jobs finish their current pass before exiting
+	stop()
+	jobs.Wait()
 }
\ No newline at end of file
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/services"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils/input"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+type CancellationPolicyHandler struct {
+	policyService services.CancellationPolicyService
+}
+
+func NewCancellationPolicyHandler(policyService services.CancellationPolicyService) *CancellationPolicyHandler {
+	return &CancellationPolicyHandler{
+		policyService: policyService,
+	}
+}
+
+// CreateCancellationPolicy creates a new cancellation policy
+func (h *CancellationPolicyHandler) CreateCancellationPolicy(w http.ResponseWriter, r *http.Request) {
+	var policy entity.CancellationPolicy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	policy.Name = input.SanitizeString(policy.Name)
+	policy.Description = input.SanitizeString(policy.Description)
+	if validationErrors := input.ValidateStruct(policy); validationErrors != nil {
+		utils.RespondJSON(w, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	created, err := h.policyService.CreateCancellationPolicy(r.Context(), &policy)
+	if err != nil {
+		respondCancellationPolicyError(w, err, "Failed to create cancellation policy")
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusCreated, created)
+}
+
+// ListCancellationPolicies retrieves all cancellation policies
+func (h *CancellationPolicyHandler) ListCancellationPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.policyService.ListCancellationPolicies(r.Context())
+	if err != nil {
+		utils.RespondError(w, http.StatusInternalServerError, "Failed to retrieve cancellation policies")
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, policies)
+}
+
+// GetCancellationPolicy retrieves a single cancellation policy
+func (h *CancellationPolicyHandler) GetCancellationPolicy(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(utils.GetResourceIDFromURL(r))
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid cancellation policy ID")
+		return
+	}
+
+	policy, err := h.policyService.GetCancellationPolicy(r.Context(), id)
+	if err != nil {
+		respondCancellationPolicyError(w, err, "Failed to retrieve cancellation policy")
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, policy)
+}
+
+// DeleteCancellationPolicy removes a cancellation policy
+func (h *CancellationPolicyHandler) DeleteCancellationPolicy(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(utils.GetResourceIDFromURL(r))
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid cancellation policy ID")
+		return
+	}
+
+	if err := h.policyService.DeleteCancellationPolicy(r.Context(), id); err != nil {
+		respondCancellationPolicyError(w, err, "Failed to delete cancellation policy")
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "Cancellation policy deleted successfully"})
+}
+
+func respondCancellationPolicyError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, entity.ErrCancellationPolicyNotFound):
+		utils.RespondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, entity.ErrInvalidCancellationRules):
+		utils.RespondError(w, http.StatusBadRequest, err.Error())
+	default:
+		if status, msg, ok := utils.HandleUniqueConstraintError(err); ok {
+			utils.RespondError(w, status, msg)
+			return
+		}
+		utils.RespondError(w, http.StatusInternalServerError, message+": "+err.Error())
+	}
+}
\ No newline at end of file
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils"
+	"golang.org/x/oauth2"
+	"net/http"
+	"time"
+)
+
+const (
+	// googleStateCookie carries the state & PKCE verifier of a sign in with Google, from the redirect to Google until its callback
+	googleStateCookie = "googleOAuthState"
+	googleStateTTL    = 10 * time.Minute
+)
+
+// GoogleLogin starts a sign in with Google: it redirects to Google's consent screen, remembering the state
+// and PKCE verifier of this sign in in a signed cookie to check the callback against
+func (h *UserHandler) GoogleLogin(w http.ResponseWriter, r *http.Request) {
+	state := oauth2.GenerateVerifier()
+	verifier := oauth2.GenerateVerifier()
+
+	stateToken, err := utils.GenerateOAuthStateToken(state, verifier, googleStateTTL)
+	if err != nil {
+		utils.RespondError(w, http.StatusInternalServerError, "Failed to start Google sign in")
+		return
+	}
+
+	// Lax, not Strict: the cookie must come back with Google's redirect to the callback
+	http.SetCookie(w, &http.Cookie{
+		Name:     googleStateCookie,
+		Value:    stateToken,
+		Expires:  time.Now().Add(googleStateTTL),
+		Path:     "/api/v1/auth/google",
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, h.googleOAuth.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier)), http.StatusFound)
+}
+
+// GoogleCallback finishes a sign in with Google, signing the user in to the account linked to their Google account
+func (h *UserHandler) GoogleCallback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("error") != "" {
+		utils.RespondError(w, http.StatusUnauthorized, "Google sign in was cancelled")
+		return
+	}
+
+	cookie, err := r.Cookie(googleStateCookie)
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Google sign in expired. Please try again")
+		return
+	}
+	// the state is single use
+	http.SetCookie(w, &http.Cookie{
+		Name:     googleStateCookie,
+		Value:    "",
+		MaxAge:   -1,
+		Path:     "/api/v1/auth/google",
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	payload, err := utils.ValidateToken(cookie.Value, "OAUTH_STATE")
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Google sign in expired. Please try again")
+		return
+	}
+	state, _ := payload["state"].(string)
+	verifier, _ := payload["verifier"].(string)
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(query.Get("state"))) != 1 {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid state")
+		return
+	}
+
+	accessToken, refreshToken, err := h.userService.SignInWithGoogle(r.Context(), query.Get("code"), verifier, deviceFromRequest(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrGoogleSignInFailed):
+			utils.RespondError(w, http.StatusUnauthorized, entity.ErrGoogleSignInFailed.Error())
+		case errors.Is(err, entity.ErrEmailNotVerified), errors.Is(err, entity.ErrAccountInactive):
+			utils.RespondError(w, http.StatusForbidden, err.Error())
+		default:
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to sign in with Google. Please try again later")
+		}
+		return
+	}
+
+	setAuthCookies(w, accessToken, refreshToken)
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"accessToken": accessToken, "refreshToken": refreshToken})
+}
\ No newline at end of file
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils/input"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// maxWebhookBodyBytes caps the size of a webhook delivery we are willing to read
+const maxWebhookBodyBytes = 64 << 10
+
+type PaymentHandler struct {
+	paymentService   payment.Service
+	webhookSecret    string
+	webhookTolerance time.Duration
+}
+
+func NewPaymentHandler(paymentService payment.Service, webhookSecret string, webhookTolerance time.Duration) *PaymentHandler {
+	if webhookTolerance <= 0 {
+		webhookTolerance = payment.DefaultWebhookTolerance
+	}
+	return &PaymentHandler{
+		paymentService:   paymentService,
+		webhookSecret:    webhookSecret,
+		webhookTolerance: webhookTolerance,
+	}
+}
+
+// Webhook receives asynchronous payment status events from the processor.
+// Deliveries are verified against the signature header before anything is read from them,
+// and redelivered events are acknowledged without being applied again
+func (h *PaymentHandler) Webhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodyBytes))
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := payment.VerifyWebhookSignature(h.webhookSecret, r.Header.Get(payment.SignatureHeader), body, h.webhookTolerance, time.Now()); err != nil {
+		utils.RespondError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	var payload entity.WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if validationErrors := input.ValidateStruct(payload); validationErrors != nil {
+		utils.RespondJSON(w, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	applied, err := h.paymentService.HandleWebhookEvent(r.Context(), &payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, payment.ErrUnsupportedEvent):
+			// acknowledge so the processor stops retrying an event we do not act on
+			utils.RespondJSON(w, http.StatusOK, map[string]any{"received": true, "ignored": true})
+		case errors.Is(err, entity.ErrPaymentNotFound):
+			utils.RespondError(w, http.StatusNotFound, err.Error())
+		default:
+			log.Printf("failed to process payment webhook %s: %v", payload.ID, err)
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to process webhook")
+		}
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]any{"received": true, "duplicate": !applied})
+}
\ No newline at end of file
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/services"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils/input"
+	"github.com/google/uuid"
+	"net/http"
+	"time"
+)
+
+type RatePlanHandler struct {
+	ratePlanService services.RatePlanService
+}
+
+func NewRatePlanHandler(ratePlanService services.RatePlanService) *RatePlanHandler {
+	return &RatePlanHandler{
+		ratePlanService: ratePlanService,
+	}
+}
+
+// CreateRatePlan creates a new rate plan
+func (h *RatePlanHandler) CreateRatePlan(w http.ResponseWriter, r *http.Request) {
+	plan, ok := decodeRatePlan(w, r)
+	if !ok {
+		return
+	}
+
+	created, err := h.ratePlanService.CreateRatePlan(r.Context(), plan)
+	if err != nil {
+		respondRatePlanError(w, err, "Failed to create rate plan")
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusCreated, created)
+}
+
+// ListRatePlans retrieves all rate plans, highest priority first
+func (h *RatePlanHandler) ListRatePlans(w http.ResponseWriter, r *http.Request) {
+	plans, err := h.ratePlanService.ListRatePlans(r.Context())
+	if err != nil {
+		utils.RespondError(w, http.StatusInternalServerError, "Failed to retrieve rate plans")
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, plans)
+}
+
+// GetRatePlan retrieves a single rate plan
+func (h *RatePlanHandler) GetRatePlan(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(utils.GetResourceIDFromURL(r))
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid rate plan ID")
+		return
+	}
+
+	plan, err := h.ratePlanService.GetRatePlan(r.Context(), id)
+	if err != nil {
+		respondRatePlanError(w, err, "Failed to retrieve rate plan")
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, plan)
+}
+
+// UpdateRatePlan replaces an existing rate plan
+func (h *RatePlanHandler) UpdateRatePlan(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(utils.GetResourceIDFromURL(r))
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid rate plan ID")
+		return
+	}
+
+	plan, ok := decodeRatePlan(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := h.ratePlanService.UpdateRatePlan(r.Context(), id, plan)
+	if err != nil {
+		respondRatePlanError(w, err, "Failed to update rate plan")
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, updated)
+}
+
+// DeleteRatePlan removes a rate plan
+func (h *RatePlanHandler) DeleteRatePlan(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(utils.GetResourceIDFromURL(r))
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid rate plan ID")
+		return
+	}
+
+	if err := h.ratePlanService.DeleteRatePlan(r.Context(), id); err != nil {
+		respondRatePlanError(w, err, "Failed to delete rate plan")
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "Rate plan deleted successfully"})
+}
+
+// decodeRatePlan reads & validates a RatePlanRequest body, writing the error response itself when it fails
+func decodeRatePlan(w http.ResponseWriter, r *http.Request) (*entity.RatePlan, bool) {
+	var req entity.RatePlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid request payload")
+		return nil, false
+	}
+
+	req.Name = input.SanitizeString(req.Name)
+	req.Description = input.SanitizeString(req.Description)
+	if validationErrors := input.ValidateStruct(req); validationErrors != nil {
+		utils.RespondJSON(w, http.StatusBadRequest, validationErrors)
+		return nil, false
+	}
+
+	plan := &entity.RatePlan{
+		Name:              req.Name,
+		Description:       req.Description,
+		WeekdayMask:       req.WeekdayMask,
+		Priority:          req.Priority,
+		Active:            req.Active == nil || *req.Active,
+		PercentAdjustment: req.PercentAdjustment,
+		Rates:             req.Rates,
+
+		CancellationPolicyID: req.CancellationPolicyID,
+	}
+
+	var err error
+	if plan.StartDate, err = parseOptionalDate(req.StartDate); err != nil {
+		utils.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid start date: %v", err))
+		return nil, false
+	}
+	if plan.EndDate, err = parseOptionalDate(req.EndDate); err != nil {
+		utils.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid end date: %v", err))
+		return nil, false
+	}
+
+	return plan, true
+}
+
+func parseOptionalDate(dateStr *string) (*time.Time, error) {
+	if dateStr == nil || *dateStr == "" {
+		return nil, nil
+	}
+	date, err := utils.ParseDate(*dateStr)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
+
+func respondRatePlanError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, entity.ErrRatePlanNotFound):
+		utils.RespondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, entity.ErrInvalidRatePlanDates):
+		utils.RespondError(w, http.StatusBadRequest, err.Error())
+	default:
+		if status, msg, ok := utils.HandleUniqueConstraintError(err); ok {
+			utils.RespondError(w, status, msg)
+			return
+		}
+		utils.RespondError(w, http.StatusInternalServerError, message+": "+err.Error())
+	}
+}
\ No newline at end of file
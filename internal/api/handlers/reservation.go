@@ -1,9 +1,13 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	paymentDomain "github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment"
 	paymentEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment/entity"
+	pricingEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/entity"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/entity"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/services"
 	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils"
@@ -23,8 +27,6 @@ func NewReservationHandler(reservationService services.ReservationService) *Rese
 }
 
 func (h *ReservationHandler) CreateReservation(w http.ResponseWriter, r *http.Request) {
-	var roomID uuid.UUID
-	var totalPrice float64
 	var req entity.CreateReservationRequest
 	userIDStr := r.Context().Value("userID").(string)
 
@@ -38,8 +40,38 @@ func (h *ReservationHandler) CreateReservation(w http.ResponseWriter, r *http.Re
 		utils.RespondError(w, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
-	if req.RoomID == nil && req.RoomNumber == nil {
-		utils.RespondError(w, http.StatusBadRequest, "Either room_id or room_number must be provided")
+	if validationErrors := input.ValidateStruct(req); validationErrors != nil {
+		utils.RespondJSON(w, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	newReservation, ok := h.reservationFromStay(w, r, userID, req.StayRequest)
+	if !ok {
+		return
+	}
+	newReservation.Payment = *newPayment(userID, req.PaymentMethod, req.PaymentDetails)
+
+	createdReservation, err := h.reservationService.CreateReservation(r.Context(), newReservation)
+	if err != nil {
+		respondBookingError(w, err)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusCreated, map[string]any{"message": "Reservation created successfully,. You will receive an email confirmation", "reservation": createdReservation})
+}
+
+// CreateBooking books several rooms at once, paid together & sharing one confirmation code.
+// If any of the rooms cannot be booked, none is
+func (h *ReservationHandler) CreateBooking(w http.ResponseWriter, r *http.Request) {
+	var req entity.CreateBookingRequest
+	userID, err := uuid.Parse(r.Context().Value("userID").(string))
+	if err != nil {
+		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 	if validationErrors := input.ValidateStruct(req); validationErrors != nil {
@@ -47,74 +79,191 @@ func (h *ReservationHandler) CreateReservation(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	checkInDate, err := utils.ParseAndValidateCheckInDate(req.CheckInDate)
+	booking := &entity.Booking{
+		UserID:  userID,
+		Payment: *newPayment(userID, req.PaymentMethod, req.PaymentDetails),
+	}
+	for _, stay := range req.Rooms {
+		reservation, ok := h.reservationFromStay(w, r, userID, stay)
+		if !ok {
+			return
+		}
+		booking.Reservations = append(booking.Reservations, reservation)
+	}
+
+	createdBooking, err := h.reservationService.CreateBooking(r.Context(), booking)
+	if err != nil {
+		respondBookingError(w, err)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusCreated, map[string]any{"message": "Booking created successfully. You will receive an email confirmation for each room", "booking": createdBooking})
+}
+
+// reservationFromStay builds the reservation of one requested stay.
+// When the stay is invalid it responds with the error and returns false
+func (h *ReservationHandler) reservationFromStay(w http.ResponseWriter, r *http.Request, userID uuid.UUID, stay entity.StayRequest) (*entity.Reservation, bool) {
+	var roomID *uuid.UUID
+	var roomTypeID uuid.UUID
+
+	if stay.RoomID == nil && stay.RoomNumber == nil && stay.RoomTypeID == nil {
+		utils.RespondError(w, http.StatusBadRequest, "One of room_id, room_number or room_type_id must be provided")
+		return nil, false
+	}
+
+	checkInDate, err := utils.ParseAndValidateCheckInDate(stay.CheckInDate)
 	if err != nil {
 		utils.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid check-in date: %v", err))
-		return
+		return nil, false
 	}
-	checkOutDate, err := utils.ParseDate(req.CheckoutDate)
+	checkOutDate, err := utils.ParseDate(stay.CheckoutDate)
 	if err != nil {
 		utils.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid check-out date: %v", err))
-		return
+		return nil, false
 	}
 
 	// Validate check-in/check-out relationship
 	if err := utils.ValidateCheckInCheckOutDates(checkInDate, checkOutDate); err != nil {
 		utils.RespondError(w, http.StatusBadRequest, err.Error())
-		return
+		return nil, false
 	}
 
-	//if room id is not provided, get room id from the room_number given in body
-	if req.RoomID == nil {
-		room, err := h.reservationService.GetRoomByNumber(r.Context(), *req.RoomNumber)
+	// a room type alone books any room of that type; a room is allocated later
+	if stay.RoomTypeID != nil {
+		id, err := uuid.Parse(*stay.RoomTypeID)
+		if err != nil {
+			utils.RespondError(w, http.StatusBadRequest, "Invalid room type ID")
+			return nil, false
+		}
+		roomTypeID = id
+	}
+
+	// a specific room is given by its id, or by its number
+	switch {
+	case stay.RoomID != nil:
+		id, err := uuid.Parse(*stay.RoomID)
+		if err != nil {
+			utils.RespondError(w, http.StatusBadRequest, "Invalid room ID")
+			return nil, false
+		}
+		roomID = &id
+	case stay.RoomNumber != nil:
+		room, err := h.reservationService.GetRoomByNumber(r.Context(), *stay.RoomNumber)
 		if err != nil || room == nil {
 			if room == nil {
 				utils.RespondError(w, http.StatusNotFound, "Room not found")
-				return
+				return nil, false
 			}
 			utils.RespondError(w, http.StatusInternalServerError, err.Error())
-			return
+			return nil, false
 		}
-		roomID = room.ID
-		totalPrice = float64(req.NumGuests) * room.RoomType.BasePrice
-	} else {
-		id, err := uuid.Parse(*req.RoomID)
-		if err != nil {
-			utils.RespondError(w, http.StatusBadRequest, "Invalid room ID")
-			return
-		}
-		roomID = id
+		roomID = &room.ID
 	}
 
-	// Create a payment record
-	payment := &paymentEntity.Payment{
+	reservation := &entity.Reservation{
+		RoomID:       roomID,
+		RoomTypeID:   roomTypeID,
+		UserID:       userID,
+		CheckInDate:  checkInDate,
+		CheckOutDate: checkOutDate,
+		NumGuests:    stay.NumGuests,
+	}
+	if stay.SpecialRequest != nil {
+		reservation.SpecialRequest = *stay.SpecialRequest
+	}
+	if stay.Preferences != nil {
+		reservation.Preferences = *stay.Preferences
+	}
+	return reservation, true
+}
+
+// newPayment creates a payment record. The amount is priced by the reservation service
+func newPayment(userID uuid.UUID, method paymentEntity.Method, details json.RawMessage) *paymentEntity.Payment {
+	return &paymentEntity.Payment{
 		UserID:         userID,
-		Amount:         totalPrice,
-		Currency:       "USD",
-		PaymentMethod:  req.PaymentMethod,
+		PaymentMethod:  method,
 		PaymentStatus:  paymentEntity.StatusPending,
 		TransactionID:  uuid.New().String(),
-		PaymentDetails: req.PaymentDetails,
+		PaymentDetails: details,
 	}
+}
 
-	newReservation := &entity.Reservation{
-		RoomID:         roomID,
-		UserID:         userID,
-		CheckInDate:    checkInDate,
-		CheckOutDate:   checkOutDate,
-		NumGuests:      req.NumGuests,
-		SpecialRequest: *req.SpecialRequest,
-		TotalPrice:     totalPrice,
-		Payment:        *payment,
+// respondBookingError maps the errors of booking rooms to their status codes
+func respondBookingError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, entity.ErrRoomUnavailable):
+		utils.RespondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, paymentDomain.ErrPaymentDeclined):
+		utils.RespondError(w, http.StatusPaymentRequired, err.Error())
+	case errors.Is(err, pricingEntity.ErrInvalidStay), errors.Is(err, pricingEntity.ErrOccupancyExceeded):
+		utils.RespondError(w, http.StatusBadRequest, err.Error())
+	default:
+		utils.RespondError(w, http.StatusInternalServerError, err.Error())
 	}
+}
 
-	createdReservation, err := h.reservationService.CreateReservation(r.Context(), newReservation)
+// GetBooking returns a booking with all of its rooms
+func (h *ReservationHandler) GetBooking(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("bookingID"))
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid booking ID")
+		return
+	}
+
+	actor, err := actorFromContext(r)
+	if err != nil {
+		utils.RespondError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	booking, err := h.reservationService.GetBooking(r.Context(), actor, id)
 	if err != nil {
+		if errors.Is(err, entity.ErrBookingNotFound) {
+			utils.RespondError(w, http.StatusNotFound, err.Error())
+			return
+		}
 		utils.RespondError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	utils.RespondJSON(w, http.StatusOK, booking)
+}
 
-	utils.RespondJSON(w, http.StatusCreated, map[string]any{"message": "Reservation created successfully,. You will receive an email confirmation", "reservation": createdReservation})
+// CancelBooking cancels every room of a booking that can still be cancelled.
+// A single room of a booking is cancelled like any reservation, with PATCH /cancel/{reservationID}
+func (h *ReservationHandler) CancelBooking(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("bookingID"))
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid booking ID")
+		return
+	}
+
+	actor, err := actorFromContext(r)
+	if err != nil {
+		utils.RespondError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	cancellation, err := h.reservationService.CancelBooking(r.Context(), actor, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrBookingNotFound):
+			utils.RespondError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, entity.ErrAlreadyCancelled), errors.Is(err, entity.ErrNotCancellable):
+			utils.RespondError(w, http.StatusBadRequest, err.Error())
+		case errors.As(err, new(*entity.InvalidTransitionError)):
+			utils.RespondError(w, http.StatusConflict, err.Error())
+		default:
+			utils.RespondError(w, http.StatusInternalServerError, "failed to cancel booking: "+err.Error())
+		}
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]any{
+		"message":       "Booking cancelled successfully",
+		"cancellations": cancellation.Cancellations,
+		"refunded":      cancellation.Refunded,
+		"refund_due":    cancellation.RefundDue,
+	})
 }
 
 func (h *ReservationHandler) CancelReservation(w http.ResponseWriter, r *http.Request) {
@@ -125,25 +274,202 @@ func (h *ReservationHandler) CancelReservation(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	reservation, err := h.reservationService.GetReservation(r.Context(), id)
-	if err != nil || reservation == nil {
-		utils.RespondError(w, http.StatusInternalServerError, "failed to get reservation: ")
+	actor, err := actorFromContext(r)
+	if err != nil {
+		utils.RespondError(w, http.StatusUnauthorized, err.Error())
 		return
 	}
-	if reservation.Status == entity.StatusCancelled {
-		utils.RespondJSON(w, http.StatusBadRequest, "Reservation already cancelled")
+
+	cancellation, err := h.reservationService.CancelReservation(r.Context(), actor, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrReservationNotFound):
+			utils.RespondError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, entity.ErrAlreadyCancelled), errors.Is(err, entity.ErrNotCancellable):
+			utils.RespondError(w, http.StatusBadRequest, err.Error())
+		case errors.As(err, new(*entity.InvalidTransitionError)):
+			utils.RespondError(w, http.StatusConflict, err.Error())
+		default:
+			utils.RespondError(w, http.StatusInternalServerError, "failed to cancel reservation: "+err.Error())
+		}
 		return
 	}
 
-	reservation.Status = entity.StatusCancelled
+	utils.RespondJSON(w, http.StatusOK, map[string]any{
+		"message":    "Reservation cancelled successfully",
+		"updated":    cancellation.Reservation,
+		"fee":        cancellation.Fee,
+		"refunded":   cancellation.Refunded,
+		"refund_due": cancellation.RefundDue,
+	})
+}
+
+// ModifyReservation changes the dates, room or number of guests of a reservation
+func (h *ReservationHandler) ModifyReservation(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(utils.GetResourceIDFromURL(r))
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid reservation ID")
+		return
+	}
 
-	updated, err := h.reservationService.UpdateReservation(r.Context(), reservation)
+	actor, err := actorFromContext(r)
 	if err != nil {
-		utils.RespondError(w, http.StatusInternalServerError, "failed to cancel reservation: "+err.Error())
+		utils.RespondError(w, http.StatusUnauthorized, err.Error())
 		return
 	}
 
-	utils.RespondJSON(w, http.StatusOK, map[string]any{"message": "Reservation cancelled successfully", "updated": updated})
+	var req entity.ModifyReservationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if validationErrors := input.ValidateStruct(req); validationErrors != nil {
+		utils.RespondJSON(w, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	modification := entity.Modification{NumGuests: req.NumGuests}
+	if req.CheckInDate != nil {
+		checkInDate, err := utils.ParseAndValidateCheckInDate(*req.CheckInDate)
+		if err != nil {
+			utils.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid check-in date: %v", err))
+			return
+		}
+		modification.CheckIn = &checkInDate
+	}
+	if req.CheckoutDate != nil {
+		checkOutDate, err := utils.ParseDate(*req.CheckoutDate)
+		if err != nil {
+			utils.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid check-out date: %v", err))
+			return
+		}
+		modification.CheckOut = &checkOutDate
+	}
+	if req.RoomTypeID != nil {
+		roomTypeID, err := uuid.Parse(*req.RoomTypeID)
+		if err != nil {
+			utils.RespondError(w, http.StatusBadRequest, "Invalid room type ID")
+			return
+		}
+		modification.RoomTypeID = &roomTypeID
+	}
+	switch {
+	case req.RoomID != nil:
+		roomID, err := uuid.Parse(*req.RoomID)
+		if err != nil {
+			utils.RespondError(w, http.StatusBadRequest, "Invalid room ID")
+			return
+		}
+		modification.RoomID = &roomID
+	case req.RoomNumber != nil:
+		room, err := h.reservationService.GetRoomByNumber(r.Context(), *req.RoomNumber)
+		if err != nil {
+			utils.RespondError(w, http.StatusNotFound, "Room not found")
+			return
+		}
+		modification.RoomID = &room.ID
+	}
+
+	result, err := h.reservationService.ModifyReservation(r.Context(), actor, id, modification)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrReservationNotFound):
+			utils.RespondError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, entity.ErrRoomUnavailable):
+			utils.RespondError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, paymentDomain.ErrPaymentDeclined):
+			utils.RespondError(w, http.StatusPaymentRequired, err.Error())
+		case errors.Is(err, entity.ErrNotModifiable), errors.Is(err, entity.ErrNoChanges),
+			errors.Is(err, pricingEntity.ErrInvalidStay), errors.Is(err, pricingEntity.ErrOccupancyExceeded):
+			utils.RespondError(w, http.StatusBadRequest, err.Error())
+		default:
+			utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]any{
+		"message":          "Reservation modified successfully",
+		"reservation":      result.Reservation,
+		"price_difference": result.PriceDifference,
+		"adjustment":       result.Adjustment,
+	})
+}
+
+// GetReservationHistory lists the changes made to a reservation and who made them
+func (h *ReservationHandler) GetReservationHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(utils.GetResourceIDFromURL(r))
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid reservation ID")
+		return
+	}
+
+	actor, err := actorFromContext(r)
+	if err != nil {
+		utils.RespondError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	history, err := h.reservationService.GetReservationHistory(r.Context(), actor, id)
+	if err != nil {
+		if errors.Is(err, entity.ErrReservationNotFound) {
+			utils.RespondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, history)
+}
+
+// CheckIn marks the guest of a reservation as arrived
+func (h *ReservationHandler) CheckIn(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.reservationService.CheckIn, "Guest checked in successfully")
+}
+
+// CheckOut marks the stay of a checked-in reservation as completed
+func (h *ReservationHandler) CheckOut(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.reservationService.CheckOut, "Guest checked out successfully")
+}
+
+// MarkNoShow closes a reservation whose guest never arrived
+func (h *ReservationHandler) MarkNoShow(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.reservationService.MarkNoShow, "Reservation marked as no-show")
+}
+
+// changeStatus runs one of the service's state transitions on the reservation in the URL.
+// Transitions the state machine does not allow are reported as 409 Conflict
+func (h *ReservationHandler) changeStatus(w http.ResponseWriter, r *http.Request,
+	transition func(ctx context.Context, actor entity.Actor, id uuid.UUID) (*entity.Reservation, error), message string) {
+	id, err := uuid.Parse(r.PathValue("reservationID"))
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid reservation ID")
+		return
+	}
+
+	actor, err := actorFromContext(r)
+	if err != nil {
+		utils.RespondError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	reservation, err := transition(r.Context(), actor, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrReservationNotFound):
+			utils.RespondError(w, http.StatusNotFound, err.Error())
+		case errors.As(err, new(*entity.InvalidTransitionError)):
+			utils.RespondError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, entity.ErrOutsideStay), errors.Is(err, entity.ErrBeforeCheckIn):
+			utils.RespondError(w, http.StatusBadRequest, err.Error())
+		default:
+			utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]any{"message": message, "reservation": reservation})
 }
 
 func (h *ReservationHandler) GetUserReservations(w http.ResponseWriter, r *http.Request) {
@@ -171,11 +497,58 @@ func (h *ReservationHandler) GetReservation(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	reservation, err := h.reservationService.GetReservation(r.Context(), reservationIDParsed)
+	actor, err := actorFromContext(r)
 	if err != nil {
+		utils.RespondError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	reservation, err := h.reservationService.GetReservation(r.Context(), actor, reservationIDParsed)
+	if err != nil {
+		if errors.Is(err, entity.ErrReservationNotFound) {
+			utils.RespondError(w, http.StatusNotFound, err.Error())
+			return
+		}
 		utils.RespondError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	utils.RespondJSON(w, http.StatusOK, reservation)
+}
+
+// actorFromContext reads the authenticated user & role set by the Authenticate middleware
+func actorFromContext(r *http.Request) (entity.Actor, error) {
+	userIDStr, _ := r.Context().Value("userID").(string)
+	role, _ := r.Context().Value("role").(string)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil || role == "" {
+		return entity.Actor{}, errors.New("missing or invalid authenticated user")
+	}
+	return entity.NewActor(userID, role), nil
+}
+
+// LookupReservation lets a guest who is not logged in find their reservation by its code and their last name or email.
+// Only a summary of the reservation is returned
+func (h *ReservationHandler) LookupReservation(w http.ResponseWriter, r *http.Request) {
+	var req entity.LookupReservationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if validationErrors := input.ValidateStruct(req); validationErrors != nil {
+		utils.RespondJSON(w, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	summary, err := h.reservationService.LookupReservation(r.Context(), req.Code, req.LastName, req.Email)
+	if err != nil {
+		if errors.Is(err, entity.ErrReservationNotFound) {
+			utils.RespondError(w, http.StatusNotFound, entity.ErrReservationNotFound.Error())
+			return
+		}
+		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, summary)
 }
\ No newline at end of file
@@ -8,6 +8,7 @@ import (
 	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils/input"
 	"github.com/google/uuid"
 	"net/http"
+	"strconv"
 )
 
 type RoomHandler struct {
@@ -54,7 +55,17 @@ func (h *RoomHandler) GetAvailableRooms(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	categorizedRooms, err := h.roomService.CheckAvailability(r.Context(), checkinDate, checkoutDate)
+	// number of guests to price the stay for (defaults to 1)
+	guests := 1
+	if guestsStr := utils.GetParamFromURL(r, "guests"); guestsStr != "" {
+		guests, err = strconv.Atoi(guestsStr)
+		if err != nil || guests < 1 {
+			utils.RespondError(w, http.StatusBadRequest, "Invalid number of guests")
+			return
+		}
+	}
+
+	categorizedRooms, err := h.roomService.CheckAvailability(r.Context(), checkinDate, checkoutDate, guests)
 	if err != nil {
 		utils.RespondError(w, http.StatusInternalServerError, "Failed to get available rooms:"+err.Error())
 		return
@@ -185,4 +196,71 @@ func (h *RoomHandler) CreateBedType(w http.ResponseWriter, r *http.Request) {
 	}
 
 	utils.RespondJSON(w, http.StatusCreated, createdBedType)
+}
+
+// ___ Maintenance windows ____//
+
+// ScheduleMaintenance plans a period during which a room is not allocated to guests
+func (h *RoomHandler) ScheduleMaintenance(w http.ResponseWriter, r *http.Request) {
+	var req entity.MaintenanceWindowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	req.Reason = input.SanitizeString(req.Reason)
+	if validationErrors := input.ValidateStruct(req); validationErrors != nil {
+		utils.RespondJSON(w, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	startDate, err := utils.ParseDate(req.StartDate)
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid start date: "+err.Error())
+		return
+	}
+	endDate, err := utils.ParseDate(req.EndDate)
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid end date: "+err.Error())
+		return
+	}
+	if !endDate.After(startDate) {
+		utils.RespondError(w, http.StatusBadRequest, "end_date must be after start_date")
+		return
+	}
+
+	window, err := h.roomService.ScheduleMaintenance(r.Context(), &entity.MaintenanceWindow{
+		RoomID:    uuid.MustParse(req.RoomID),
+		StartDate: startDate,
+		EndDate:   endDate,
+		Reason:    req.Reason,
+	})
+	if err != nil {
+		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusCreated, window)
+}
+
+// GetMaintenanceWindows lists the maintenance windows between the from and to dates
+func (h *RoomHandler) GetMaintenanceWindows(w http.ResponseWriter, r *http.Request) {
+	from, err := utils.GetDateFromURL(r, "from")
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid from date: "+err.Error())
+		return
+	}
+	to, err := utils.GetDateFromURL(r, "to")
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid to date: "+err.Error())
+		return
+	}
+
+	windows, err := h.roomService.GetMaintenanceWindows(r.Context(), from, to)
+	if err != nil {
+		utils.RespondError(w, http.StatusInternalServerError, "Failed to get maintenance windows")
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, windows)
 }
\ No newline at end of file
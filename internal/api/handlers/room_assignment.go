@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/services"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils"
+	"net/http"
+	"time"
+)
+
+type RoomAssignmentHandler struct {
+	allocator services.RoomAllocator
+}
+
+func NewRoomAssignmentHandler(allocator services.RoomAllocator) *RoomAssignmentHandler {
+	return &RoomAssignmentHandler{
+		allocator: allocator,
+	}
+}
+
+// AssignRooms allocates rooms to the room type bookings arriving on the date in the query (today by default)
+func (h *RoomAssignmentHandler) AssignRooms(w http.ResponseWriter, r *http.Request) {
+	date := time.Now().UTC()
+	if utils.GetParamFromURL(r, "date") != "" {
+		parsed, err := utils.GetDateFromURL(r, "date")
+		if err != nil {
+			utils.RespondError(w, http.StatusBadRequest, "Invalid date: "+err.Error())
+			return
+		}
+		date = parsed
+	}
+
+	actor, err := actorFromContext(r)
+	if err != nil {
+		utils.RespondError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	result, err := h.allocator.AssignRooms(r.Context(), actor, date)
+	if err != nil {
+		utils.RespondError(w, http.StatusInternalServerError, "Failed to assign rooms: "+err.Error())
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, result)
+}
\ No newline at end of file
@@ -2,14 +2,16 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/config"
-	"github.com/gatimugabriel/hotel-reservation-system/internal/constants"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/entity"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/services"
 	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils"
 	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils/input"
+	"github.com/google/uuid"
 	"golang.org/x/oauth2"
+	"net"
 	"net/http"
 	"time"
 )
@@ -27,7 +29,7 @@ func NewUserHandler(userService services.UserService) *UserHandler {
 }
 
 func (h *UserHandler) SignUp(w http.ResponseWriter, r *http.Request) {
-	var req entity.User
+	var req entity.SignUpRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		utils.RespondError(w, http.StatusBadRequest, "Invalid request payload")
 		return
@@ -38,16 +40,13 @@ func (h *UserHandler) SignUp(w http.ResponseWriter, r *http.Request) {
 	req.LastName = input.SanitizeString(req.LastName)
 	req.Email = input.SanitizeString(req.Email)
 	req.Phone = input.SanitizeString(req.Phone)
-	if req.Role == "" {
-		req.Role = constants.GUEST
-	}
 
 	if validationErrors := input.ValidateStruct(&req); validationErrors != nil {
 		utils.RespondJSON(w, http.StatusBadRequest, validationErrors)
 		return
 	}
 
-	user, err := h.userService.Create(r.Context(), &req)
+	user, err := h.userService.Create(r.Context(), req.User())
 	if err != nil {
 		if status, message, ok := utils.HandleUniqueConstraintError(err); ok {
 			utils.RespondError(w, status, message)
@@ -59,31 +58,20 @@ func (h *UserHandler) SignUp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// generate tokens for new user
-	accessToken, refreshToken, _ := utils.GenerateTokens(user.ID.String(), user.Role)
-
-	// Set HTTP_only cookies
-	http.SetCookie(w, &http.Cookie{
-		Name:     "accessToken",
-		Value:    accessToken,
-		Expires:  time.Now().Add(15 * time.Minute),
-		Path:     "/",
-		HttpOnly: true,
-		Secure:   true,
-		SameSite: http.SameSiteStrictMode,
-	})
+	// the account works without it: the user can ask for another verification email
+	if err := h.userService.SendVerificationEmail(r.Context(), user); err != nil {
+		fmt.Println("Failed to send verification email:", err)
+	}
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     "refreshToken",
-		Value:    refreshToken,
-		Expires:  time.Now().Add(30 * 24 * time.Hour),
-		Path:     "/",
-		HttpOnly: true,
-		Secure:   true,
-		SameSite: http.SameSiteStrictMode,
-	})
+	// generate tokens for new user
+	accessToken, refreshToken, err := h.userService.IssueTokens(r.Context(), user, deviceFromRequest(r))
+	if err != nil {
+		utils.RespondError(w, http.StatusInternalServerError, "Signed up, but failed to sign in. Please sign in")
+		return
+	}
+	setAuthCookies(w, accessToken, refreshToken)
 
-	utils.RespondJSON(w, http.StatusCreated, map[string]interface{}{"user": user, "accessToken": accessToken, "refreshToken": refreshToken})
+	utils.RespondJSON(w, http.StatusCreated, map[string]interface{}{"user": user.Profile(), "accessToken": accessToken, "refreshToken": refreshToken})
 }
 
 func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
@@ -95,19 +83,225 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	req.Email = input.SanitizeString(req.Email)
 
-	accessToken, refreshToken, err := h.userService.Authenticate(r.Context(), &req)
+	accessToken, refreshToken, err := h.userService.Authenticate(r.Context(), &req, deviceFromRequest(r))
 	if err != nil {
+		if errors.Is(err, entity.ErrAccountInactive) {
+			utils.RespondError(w, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.RespondError(w, http.StatusUnauthorized, "Invalid credentials")
 		return
 	}
 
-	fmt.Println(time.Now().Add(15 * time.Minute))
+	setAuthCookies(w, accessToken, refreshToken)
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"accessToken": accessToken, "refreshToken": refreshToken})
+}
+
+// RefreshToken exchanges the refresh token, from the refreshToken cookie or the request body, for a new pair of tokens.
+// Each refresh token works once; presenting one again signs out every session started from the same sign in
+func (h *UserHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	refreshToken, ok := refreshTokenFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	accessToken, refreshToken, err := h.userService.RefreshTokens(r.Context(), refreshToken, deviceFromRequest(r))
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidRefreshToken) || errors.Is(err, entity.ErrRefreshTokenReused) {
+			clearAuthCookies(w)
+			utils.RespondError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	setAuthCookies(w, accessToken, refreshToken)
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"accessToken": accessToken, "refreshToken": refreshToken})
+}
+
+// SignOut ends the session of the refresh token and clears the auth cookies
+func (h *UserHandler) SignOut(w http.ResponseWriter, r *http.Request) {
+	refreshToken, ok := refreshTokenFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.SignOut(r.Context(), refreshToken); err != nil {
+		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	clearAuthCookies(w)
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "Signed out successfully"})
+}
+
+// GetSessions lists the sessions of the authenticated user, marking the one making the request as current
+func (h *UserHandler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.Context().Value("userID").(string))
+	if err != nil {
+		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	currentSessionID, _ := uuid.Parse(r.Context().Value("sessionID").(string))
+
+	sessions, err := h.userService.GetSessions(r.Context(), userID, currentSessionID)
+	if err != nil {
+		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, sessions)
+}
+
+// RevokeSession signs the authenticated user out of one of their sessions
+func (h *UserHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.Context().Value("userID").(string))
+	if err != nil {
+		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	sessionID, err := uuid.Parse(r.PathValue("sessionID"))
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	if err := h.userService.RevokeSession(r.Context(), userID, sessionID); err != nil {
+		if errors.Is(err, entity.ErrSessionNotFound) {
+			utils.RespondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "Session revoked successfully"})
+}
+
+// VerifyEmail marks the email of the user a verification link was sent to as verified
+func (h *UserHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		utils.RespondError(w, http.StatusBadRequest, "Missing verification token")
+		return
+	}
+
+	if err := h.userService.VerifyEmail(r.Context(), token); err != nil {
+		if errors.Is(err, entity.ErrInvalidVerificationToken) {
+			utils.RespondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.RespondError(w, http.StatusInternalServerError, "Failed to verify email. Please try again later")
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "Email verified successfully"})
+}
+
+// ResendVerificationEmail sends the authenticated user another verification link
+func (h *UserHandler) ResendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.Context().Value("userID").(string))
+	if err != nil {
+		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
 
-	// Set HTTP_only cookies
+	if err := h.userService.ResendVerificationEmail(r.Context(), userID); err != nil {
+		switch {
+		case errors.Is(err, entity.ErrAlreadyVerified):
+			utils.RespondError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, entity.ErrVerificationThrottled):
+			utils.RespondError(w, http.StatusTooManyRequests, err.Error())
+		case errors.Is(err, entity.ErrUserNotFound):
+			utils.RespondError(w, http.StatusNotFound, err.Error())
+		default:
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to send verification email. Please try again later")
+		}
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "Verification email sent"})
+}
+
+// ForgotPassword emails a password reset link to the user with the given email.
+// It always responds the same, whether or not there is such a user
+func (h *UserHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req entity.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	req.Email = input.SanitizeString(req.Email)
+	if validationErrors := input.ValidateStruct(&req); validationErrors != nil {
+		utils.RespondJSON(w, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	// failures are only logged: an error for some emails but not others would tell which have accounts
+	if err := h.userService.ForgotPassword(r.Context(), req.Email); err != nil {
+		fmt.Println("Failed to start password reset:", err)
+	}
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "If an account with that email exists, a password reset link has been sent to it"})
+}
+
+// ResetPassword sets a new password with the token of a password reset link, signing the user out everywhere
+func (h *UserHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req entity.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if validationErrors := input.ValidateStruct(&req); validationErrors != nil {
+		utils.RespondJSON(w, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	if err := h.userService.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+		if errors.Is(err, entity.ErrInvalidResetToken) {
+			utils.RespondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.RespondError(w, http.StatusInternalServerError, "Failed to reset password. Please try again later")
+		return
+	}
+
+	clearAuthCookies(w)
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "Password reset successfully. Please sign in with your new password"})
+}
+
+// deviceFromRequest describes the client making the request, for its session
+func deviceFromRequest(r *http.Request) entity.Device {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	userAgent := r.UserAgent()
+	if len(userAgent) > 512 {
+		userAgent = userAgent[:512]
+	}
+	return entity.Device{UserAgent: userAgent, IPAddress: ip}
+}
+
+// refreshTokenFromRequest reads the refresh token from the refreshToken cookie, or else from the request body.
+// It responds with an error itself when there is none
+func refreshTokenFromRequest(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if cookie, err := r.Cookie("refreshToken"); err == nil && cookie.Value != "" {
+		return cookie.Value, true
+	}
+
+	var req entity.RefreshTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		utils.RespondError(w, http.StatusBadRequest, "Missing Refresh Token")
+		return "", false
+	}
+	return req.RefreshToken, true
+}
+
+// setAuthCookies sets the HTTP-only cookies carrying the tokens
+func setAuthCookies(w http.ResponseWriter, accessToken, refreshToken string) {
 	http.SetCookie(w, &http.Cookie{
 		Name:     "accessToken",
 		Value:    accessToken,
-		Expires:  time.Now().Add(15 * time.Minute),
+		Expires:  time.Now().Add(utils.AccessTokenTTL),
 		Path:     "/",
 		HttpOnly: true,
 		Secure:   true,
@@ -117,16 +311,194 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 	http.SetCookie(w, &http.Cookie{
 		Name:     "refreshToken",
 		Value:    refreshToken,
-		Expires:  time.Now().Add(30 * 24 * time.Hour),
+		Expires:  time.Now().Add(utils.RefreshTokenTTL),
 		Path:     "/",
 		HttpOnly: true,
 		Secure:   true,
 		SameSite: http.SameSiteStrictMode,
 	})
+}
 
-	utils.RespondJSON(w, http.StatusOK, map[string]string{"accessToken": accessToken, "refreshToken": refreshToken})
+// clearAuthCookies removes the cookies set by setAuthCookies
+func clearAuthCookies(w http.ResponseWriter) {
+	for _, name := range []string{"accessToken", "refreshToken"} {
+		http.SetCookie(w, &http.Cookie{
+			Name:     name,
+			Value:    "",
+			MaxAge:   -1,
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteStrictMode,
+		})
+	}
 }
 
-func (h *UserHandler) GetUserProfile(w http.ResponseWriter, r *http.Request) {
+// GetMyProfile returns the authenticated user's profile
+func (h *UserHandler) GetMyProfile(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.Context().Value("userID").(string))
+	if err != nil {
+		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.getProfile(w, r, userID)
+}
 
+// UpdateMyProfile changes the authenticated user's name or phone number
+func (h *UserHandler) UpdateMyProfile(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.Context().Value("userID").(string))
+	if err != nil {
+		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.updateProfile(w, r, userID)
+}
+
+// ChangePassword sets the authenticated user's password, signing them out of their other sessions
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.Context().Value("userID").(string))
+	if err != nil {
+		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	currentSessionID, _ := uuid.Parse(r.Context().Value("sessionID").(string))
+
+	var req entity.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if validationErrors := input.ValidateStruct(&req); validationErrors != nil {
+		utils.RespondJSON(w, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	if err := h.userService.ChangePassword(r.Context(), userID, currentSessionID, &req); err != nil {
+		switch {
+		case errors.Is(err, entity.ErrWrongPassword):
+			utils.RespondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, entity.ErrUserNotFound):
+			utils.RespondError(w, http.StatusNotFound, err.Error())
+		default:
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to change password. Please try again later")
+		}
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "Password changed successfully"})
+}
+
+// DeactivateMyAccount suspends the authenticated user's account and signs them out everywhere
+func (h *UserHandler) DeactivateMyAccount(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.Context().Value("userID").(string))
+	if err != nil {
+		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := h.userService.DeactivateAccount(r.Context(), userID); err != nil {
+		respondUserError(w, err, "Failed to deactivate account. Please try again later")
+		return
+	}
+	clearAuthCookies(w)
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "Account deactivated successfully"})
+}
+
+// DeleteMyAccount closes the authenticated user's account and signs them out everywhere
+func (h *UserHandler) DeleteMyAccount(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.Context().Value("userID").(string))
+	if err != nil {
+		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := h.userService.DeleteUser(r.Context(), userID); err != nil {
+		respondUserError(w, err, "Failed to delete account. Please try again later")
+		return
+	}
+	clearAuthCookies(w)
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "Account deleted successfully"})
+}
+
+// GetUser returns the profile of any user (admin)
+func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+	h.getProfile(w, r, userID)
+}
+
+// UpdateUser changes the name or phone number of a user below the admin's role
+func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.managedUser(w, r)
+	if !ok {
+		return
+	}
+	h.updateProfile(w, r, user.ID)
+}
+
+// DeleteUser closes the account of a user below the admin's role
+func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.managedUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.DeleteUser(r.Context(), user.ID); err != nil {
+		respondUserError(w, err, "Failed to delete user. Please try again later")
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "User deleted successfully"})
+}
+
+func (h *UserHandler) getProfile(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	user, err := h.userService.GetUser(r.Context(), userID)
+	if err != nil {
+		respondUserError(w, err, "Failed to get user. Please try again later")
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, user.Profile())
+}
+
+func (h *UserHandler) updateProfile(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	var req entity.UpdateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	//validate & sanitize inputs
+	for _, field := range []*string{req.FirstName, req.LastName, req.Phone} {
+		if field != nil {
+			*field = input.SanitizeString(*field)
+		}
+	}
+	if validationErrors := input.ValidateStruct(&req); validationErrors != nil {
+		utils.RespondJSON(w, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	user, err := h.userService.UpdateProfile(r.Context(), userID, &req)
+	if err != nil {
+		if status, message, ok := utils.HandleUniqueConstraintError(err); ok {
+			utils.RespondError(w, status, message)
+			return
+		}
+		respondUserError(w, err, "Failed to update profile. Please try again later")
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, user.Profile())
+}
+
+// respondUserError responds 404 for users that do not exist, 403 for users the actor may not manage, else 500 with message
+func respondUserError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, entity.ErrUserNotFound):
+		utils.RespondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, entity.ErrRoleNotAllowed):
+		utils.RespondError(w, http.StatusForbidden, err.Error())
+	default:
+		utils.RespondError(w, http.StatusInternalServerError, message)
+	}
 }
\ No newline at end of file
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/constants"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils/input"
+	"github.com/google/uuid"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultUsersPerPage = 20
+	maxUsersPerPage     = 100
+)
+
+// ListUsers lists the users matching the query params q (name or email), role & status, a page at a time (page, limit)
+func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	filter := entity.UserFilter{
+		Query:  strings.TrimSpace(utils.GetParamFromURL(r, "q")),
+		Role:   constants.Role(strings.ToUpper(utils.GetParamFromURL(r, "role"))),
+		Status: constants.UserStatus(strings.ToUpper(utils.GetParamFromURL(r, "status"))),
+		Page:   1,
+		Limit:  defaultUsersPerPage,
+	}
+	if page := utils.GetParamFromURL(r, "page"); page != "" {
+		n, err := strconv.Atoi(page)
+		if err != nil || n < 1 {
+			utils.RespondError(w, http.StatusBadRequest, "Invalid page")
+			return
+		}
+		filter.Page = n
+	}
+	if limit := utils.GetParamFromURL(r, "limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxUsersPerPage {
+			utils.RespondError(w, http.StatusBadRequest, "Invalid limit: must be between 1 and "+strconv.Itoa(maxUsersPerPage))
+			return
+		}
+		filter.Limit = n
+	}
+
+	users, total, err := h.userService.ListUsers(r.Context(), filter)
+	if err != nil {
+		utils.RespondError(w, http.StatusInternalServerError, "Failed to get users")
+		return
+	}
+
+	profiles := make([]*entity.UserProfile, 0, len(users))
+	for _, user := range users {
+		profiles = append(profiles, user.Profile())
+	}
+	utils.RespondPaginatedJSON(w, http.StatusOK, profiles, len(profiles), filter.Page, int(total))
+}
+
+// InviteUser creates an account, with a role below the admin's, whose owner chooses its password from an emailed link
+func (h *UserHandler) InviteUser(w http.ResponseWriter, r *http.Request) {
+	actor, err := userActorFromContext(r)
+	if err != nil {
+		utils.RespondError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	var req entity.InviteUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	//validate & sanitize inputs
+	req.FirstName = input.SanitizeString(req.FirstName)
+	req.LastName = input.SanitizeString(req.LastName)
+	req.Email = input.SanitizeString(req.Email)
+	req.Phone = input.SanitizeString(req.Phone)
+	req.Role = constants.Role(strings.ToUpper(string(req.Role)))
+	if validationErrors := input.ValidateStruct(&req); validationErrors != nil {
+		utils.RespondJSON(w, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	user, err := h.userService.InviteUser(r.Context(), actor, &req)
+	if err != nil {
+		if status, message, ok := utils.HandleUniqueConstraintError(err); ok {
+			utils.RespondError(w, status, message)
+			return
+		}
+		respondUserError(w, err, "Failed to invite user. Please try again later")
+		return
+	}
+	utils.RespondJSON(w, http.StatusCreated, user.Profile())
+}
+
+// ChangeUserRole gives a user below the admin's role another role below it
+func (h *UserHandler) ChangeUserRole(w http.ResponseWriter, r *http.Request) {
+	actor, userID, ok := adminTarget(w, r)
+	if !ok {
+		return
+	}
+
+	var req entity.ChangeRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	req.Role = constants.Role(strings.ToUpper(string(req.Role)))
+	if validationErrors := input.ValidateStruct(&req); validationErrors != nil {
+		utils.RespondJSON(w, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	user, err := h.userService.ChangeRole(r.Context(), actor, userID, req.Role)
+	if err != nil {
+		respondUserError(w, err, "Failed to change role. Please try again later")
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, user.Profile())
+}
+
+// ChangeUserStatus activates or deactivates the account of a user below the admin's role
+func (h *UserHandler) ChangeUserStatus(w http.ResponseWriter, r *http.Request) {
+	actor, userID, ok := adminTarget(w, r)
+	if !ok {
+		return
+	}
+
+	var req entity.ChangeStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	req.Status = constants.UserStatus(strings.ToUpper(string(req.Status)))
+	if validationErrors := input.ValidateStruct(&req); validationErrors != nil {
+		utils.RespondJSON(w, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	user, err := h.userService.ChangeStatus(r.Context(), actor, userID, req.Status)
+	if err != nil {
+		respondUserError(w, err, "Failed to change status. Please try again later")
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, user.Profile())
+}
+
+// SignOutUser signs a user below the admin's role out of every session
+func (h *UserHandler) SignOutUser(w http.ResponseWriter, r *http.Request) {
+	actor, userID, ok := adminTarget(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.SignOutUser(r.Context(), actor, userID); err != nil {
+		respondUserError(w, err, "Failed to sign user out. Please try again later")
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "User signed out of all sessions"})
+}
+
+// managedUser returns the user of the userID path value if the authenticated user may manage them.
+// It responds with an error itself otherwise
+func (h *UserHandler) managedUser(w http.ResponseWriter, r *http.Request) (*entity.User, bool) {
+	actor, userID, ok := adminTarget(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	user, err := h.userService.ManagedUser(r.Context(), actor, userID)
+	if err != nil {
+		respondUserError(w, err, "Failed to get user. Please try again later")
+		return nil, false
+	}
+	return user, true
+}
+
+// adminTarget reads the authenticated user and the userID path value. It responds with an error itself when either is invalid
+func adminTarget(w http.ResponseWriter, r *http.Request) (entity.Actor, uuid.UUID, bool) {
+	actor, err := userActorFromContext(r)
+	if err != nil {
+		utils.RespondError(w, http.StatusUnauthorized, err.Error())
+		return entity.Actor{}, uuid.Nil, false
+	}
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid user ID")
+		return entity.Actor{}, uuid.Nil, false
+	}
+	return actor, userID, true
+}
+
+// userActorFromContext reads the authenticated user & role set by the Authenticate middleware
+func userActorFromContext(r *http.Request) (entity.Actor, error) {
+	userIDStr, _ := r.Context().Value("userID").(string)
+	role, _ := r.Context().Value("role").(string)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil || role == "" {
+		return entity.Actor{}, errors.New("missing or invalid authenticated user")
+	}
+	return entity.NewActor(userID, role), nil
+}
\ No newline at end of file
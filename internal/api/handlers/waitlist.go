@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/services"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils/input"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+type WaitlistHandler struct {
+	waitlistService services.WaitlistService
+}
+
+func NewWaitlistHandler(waitlistService services.WaitlistService) *WaitlistHandler {
+	return &WaitlistHandler{
+		waitlistService: waitlistService,
+	}
+}
+
+// JoinWaitlist puts the guest on the waitlist of a room type that is sold out for their dates
+func (h *WaitlistHandler) JoinWaitlist(w http.ResponseWriter, r *http.Request) {
+	var req entity.JoinWaitlistRequest
+	actor, err := actorFromContext(r)
+	if err != nil {
+		utils.RespondError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if validationErrors := input.ValidateStruct(req); validationErrors != nil {
+		utils.RespondJSON(w, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	checkInDate, err := utils.ParseAndValidateCheckInDate(req.CheckInDate)
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid check-in date: %v", err))
+		return
+	}
+	checkOutDate, err := utils.ParseDate(req.CheckoutDate)
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid check-out date: %v", err))
+		return
+	}
+	if err := utils.ValidateCheckInCheckOutDates(checkInDate, checkOutDate); err != nil {
+		utils.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entry, err := h.waitlistService.Join(r.Context(), &entity.WaitlistEntry{
+		UserID:       actor.UserID,
+		RoomTypeID:   uuid.MustParse(req.RoomTypeID),
+		CheckInDate:  checkInDate,
+		CheckOutDate: checkOutDate,
+		NumGuests:    req.NumGuests,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrRoomsAvailable), errors.Is(err, entity.ErrAlreadyWaitlisted):
+			utils.RespondError(w, http.StatusConflict, err.Error())
+		default:
+			utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusCreated, map[string]any{"message": "You are on the waitlist. We will email you if a room frees up", "entry": entry})
+}
+
+// GetUserWaitlist lists the guest's waitlist entries
+func (h *WaitlistHandler) GetUserWaitlist(w http.ResponseWriter, r *http.Request) {
+	actor, err := actorFromContext(r)
+	if err != nil {
+		utils.RespondError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	entries, err := h.waitlistService.GetUserEntries(r.Context(), actor.UserID)
+	if err != nil {
+		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, entries)
+}
+
+// LeaveWaitlist takes one of the guest's entries off the waitlist
+func (h *WaitlistHandler) LeaveWaitlist(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("entryID"))
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid waitlist entry ID")
+		return
+	}
+
+	actor, err := actorFromContext(r)
+	if err != nil {
+		utils.RespondError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	if err := h.waitlistService.Leave(r.Context(), actor, id); err != nil {
+		switch {
+		case errors.Is(err, entity.ErrWaitlistEntryNotFound):
+			utils.RespondError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, entity.ErrWaitlistEntryNotActive):
+			utils.RespondError(w, http.StatusBadRequest, err.Error())
+		default:
+			utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, map[string]any{"message": "You have left the waitlist"})
+}
\ No newline at end of file
@@ -0,0 +1,41 @@
+package router
+
+import (
+	"github.com/gatimugabriel/hotel-reservation-system/internal/api/handlers"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/config"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/constants"
+	reservationRepository "github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/repository"
+	reservationServices "github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/services"
+	roomRepository "github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/repository"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/infrastructure/database"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/middleware"
+	"net/http"
+)
+
+// RegisterAdminRoutes registers hotel operations API endpoints
+// @param configurations -> application config (user invitations)
+// @param db -> database service
+// @param r -> http ServeMux (router)
+// @return http.Handler
+func RegisterAdminRoutes(configurations *config.Config, db *database.Service, r *http.ServeMux) http.Handler {
+	reservationRepo := reservationRepository.NewReservationRepository(db)
+	roomRepo := roomRepository.NewRoomRepository(db)
+
+	allocator := reservationServices.NewRoomAllocator(reservationRepo, roomRepo)
+	assignmentHandler := handlers.NewRoomAssignmentHandler(allocator)
+	userHandler := handlers.NewUserHandler(newUserService(configurations, db))
+
+	adminRoles := []constants.Role{constants.MANAGER, constants.PROPERTYOWNER, constants.ADMIN}
+	roleCheckMiddleware := middleware.AuthWithRoleCheck(adminRoles)
+
+	r.Handle("POST /assign-rooms", roleCheckMiddleware(http.HandlerFunc(assignmentHandler.AssignRooms)))
+
+	//___ User management: everyone may only manage users, and grant roles, below their own role ___//
+	r.Handle("GET /users", roleCheckMiddleware(http.HandlerFunc(userHandler.ListUsers)))
+	r.Handle("POST /users/invite", roleCheckMiddleware(http.HandlerFunc(userHandler.InviteUser)))
+	r.Handle("PATCH /users/{userID}/role", roleCheckMiddleware(http.HandlerFunc(userHandler.ChangeUserRole)))
+	r.Handle("PATCH /users/{userID}/status", roleCheckMiddleware(http.HandlerFunc(userHandler.ChangeUserStatus)))
+	r.Handle("POST /users/{userID}/signout", roleCheckMiddleware(http.HandlerFunc(userHandler.SignOutUser)))
+
+	return http.StripPrefix("/api/v1/admin", r)
+}
\ No newline at end of file
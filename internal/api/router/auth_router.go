@@ -2,25 +2,53 @@ package router
 
 import (
 	"github.com/gatimugabriel/hotel-reservation-system/internal/api/handlers"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/config"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/repository"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/services"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/infrastructure/database"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/middleware"
+	"golang.org/x/time/rate"
 	"net/http"
+	"time"
 )
 
 // RegisterAuthRoutes registers auth related API endpoints
+// @param configurations -> application config (email verification & password reset)
 // @param db -> database service
 // @param r -> http ServeMux (router)
 // @return http.Handler
-func RegisterAuthRoutes(db *database.Service, r *http.ServeMux) http.Handler {
-	userRepo := repository.NewUserRepository(db)
-	userService := services.NewUserService(userRepo)
+func RegisterAuthRoutes(configurations *config.Config, db *database.Service, r *http.ServeMux) http.Handler {
+	userService := newUserService(configurations, db)
 	handler := handlers.NewUserHandler(userService)
 
 	r.HandleFunc("POST /signup", handler.SignUp)
 	r.HandleFunc("POST /signin", handler.Login)
-	//r.HandleFunc("POST /signout", handler.SignOut)
-	//r.HandleFunc("GET /refresh", handler.RefreshToken)
+	r.HandleFunc("POST /refresh", handler.RefreshToken)
+	r.HandleFunc("POST /signout", handler.SignOut)
+
+	//___ Email verification ___//
+	r.HandleFunc("GET /verify-email", handler.VerifyEmail)
+	r.Handle("POST /verify-email/resend", middleware.Authenticate(http.HandlerFunc(handler.ResendVerificationEmail)))
+
+	//___ Password reset: rate limited per IP, as each request may send an email ___//
+	passwordResetLimiter := middleware.NewIPRateLimiter(rate.Every(12*time.Second), 5)
+	r.Handle("POST /forgot-password", middleware.Limit(passwordResetLimiter)(http.HandlerFunc(handler.ForgotPassword)))
+	r.Handle("POST /reset-password", middleware.Limit(passwordResetLimiter)(http.HandlerFunc(handler.ResetPassword)))
+
+	//___ Sign in with Google ___//
+	r.HandleFunc("GET /google/login", handler.GoogleLogin)
+	r.HandleFunc("GET /google/callback", handler.GoogleCallback)
 
 	return http.StripPrefix("/api/v1/auth", r)
+}
+
+// newUserService builds the user service shared by the auth & user route groups
+func newUserService(configurations *config.Config, db *database.Service) *services.UserServiceImpl {
+	return services.NewUserService(
+		repository.NewUserRepository(db),
+		repository.NewSessionRepository(db),
+		repository.NewIdentityRepository(db),
+		repository.NewOneTimeTokenRepository(db),
+		configurations.Auth,
+	)
 }
\ No newline at end of file
@@ -1,25 +1,36 @@
 package router
 
 import (
+	"github.com/gatimugabriel/hotel-reservation-system/internal/config"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment"
+	userRepository "github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/repository"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/infrastructure/database"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/middleware"
 	"net/http"
 )
 
-// RegisterRouter : main entrance of all routes (route groups)
-func RegisterRouter(dbService *database.Service, r *http.ServeMux) {
+// RegisterRouter : main entrance of all routes (route groups).
+// gateway is the payment processor shared by every route group that charges guests
+func RegisterRouter(configurations *config.Config, dbService *database.Service, r *http.ServeMux, gateway payment.Gateway) {
+	// refuse access tokens of revoked sessions everywhere Authenticate is used
+	middleware.CheckSessions(middleware.NewSessionCache(userRepository.NewSessionRepository(dbService), middleware.RevocationCacheTTL))
+
 	//__ 1.  USER ROUTES (auth + profile) __//
-	r.Handle("/api/v1/auth/", RegisterAuthRoutes(dbService, r))
-	r.Handle("/api/v1/user/", RegisterUserProfileRoutes(dbService, r))
+	r.Handle("/api/v1/auth/", RegisterAuthRoutes(configurations, dbService, r))
+	r.Handle("/api/v1/user/", RegisterUserProfileRoutes(configurations, dbService))
 
 	//__ 2. ROOMS __//
 	r.Handle("/api/v1/room/", RegisterRoomRoutes(dbService, r))
 
 	//__ 3. RESERVATIONS __//
-	r.Handle("/api/v1/reservation/", RegisterReservationRoutes(dbService, r))
+	r.Handle("/api/v1/reservation/", RegisterReservationRoutes(configurations, dbService, r, gateway))
+
+	//__ 4. PAYMENTS __//
+	r.Handle("/api/v1/payment/", RegisterPaymentRoutes(configurations, dbService, r, gateway))
+
+	//__ 5. HOTEL OPERATIONS __//
+	r.Handle("/api/v1/admin/", RegisterAdminRoutes(configurations, dbService, r))
 
-	////__ 4. PAYMENTS __//
-	//r.Handle("/payment/", RegisterPaymentRoutes(dbService, r))
-	//
-	////__ 5. NOTIFICATIONS __//
+	////__ 6. NOTIFICATIONS __//
 	//r.Handle("/notification/", RegisterNotificationRoutes(dbService, r))
 }
\ No newline at end of file
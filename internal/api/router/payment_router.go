@@ -0,0 +1,27 @@
+package router
+
+import (
+	"github.com/gatimugabriel/hotel-reservation-system/internal/api/handlers"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/config"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment/repository"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/infrastructure/database"
+	"net/http"
+)
+
+// RegisterPaymentRoutes registers payment API endpoints
+// @param configurations -> application config (webhook secret)
+// @param db -> database service
+// @param r -> http ServeMux (router)
+// @param gateway -> payment processor
+// @return http.Handler
+func RegisterPaymentRoutes(configurations *config.Config, db *database.Service, r *http.ServeMux, gateway payment.Gateway) http.Handler {
+	paymentRepo := repository.NewPaymentRepository(db)
+	paymentService := payment.NewPaymentService(paymentRepo, gateway)
+	handler := handlers.NewPaymentHandler(paymentService, configurations.Payment.WebhookSecret, configurations.Payment.WebhookTolerance)
+
+	// called by the payment processor, authenticated by its signature rather than a user token
+	r.HandleFunc("POST /webhook", handler.Webhook)
+
+	return http.StripPrefix("/api/v1/payment", r)
+}
\ No newline at end of file
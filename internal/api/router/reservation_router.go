@@ -2,31 +2,79 @@ package router
 
 import (
 	"github.com/gatimugabriel/hotel-reservation-system/internal/api/handlers"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/config"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/constants"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment"
 	paymentRepository "github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment/repository"
+	pricingRepository "github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/repository"
+	pricingServices "github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/services"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/repository"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/services"
 	roomRepository "github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/repository"
+	userRepository "github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/repository"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/infrastructure/database"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/middleware"
+	"golang.org/x/time/rate"
 	"net/http"
+	"time"
 )
 
-// RegisterReservationRoutes registers bookings API endpoints
+// RegisterReservationRoutes registers bookings API endpoints.
+// They get a mux of their own, behind Authenticate: on the shared one their patterns would also match other groups' paths,
+// such as "POST /api/v1/room/{id}/check-in", without authentication
+// @param configurations -> application config (reservation hold TTL, email verification policy)
 // @param db -> database service
-// @param r -> http ServeMux (router)
+// @param r -> http ServeMux (router), only for the public routes
+// @param gateway -> payment processor used to charge bookings
 // @return http.Handler
-func RegisterReservationRoutes(db *database.Service, r *http.ServeMux) http.Handler {
+func RegisterReservationRoutes(configurations *config.Config, db *database.Service, r *http.ServeMux, gateway payment.Gateway) http.Handler {
 	reservationRepo := repository.NewReservationRepository(db)
 	roomRepo := roomRepository.NewRoomRepository(db)
+	roomTypeRepo := roomRepository.NewRoomTypeRepository(db)
 	paymentRepo := paymentRepository.NewPaymentRepository(db)
+	ratePlanRepo := pricingRepository.NewRatePlanRepository(db)
+	policyRepo := pricingRepository.NewCancellationPolicyRepository(db)
 
-	reservationService := services.NewReservationService(reservationRepo, roomRepo, paymentRepo)
+	pricingService := pricingServices.NewPricingService(roomTypeRepo, ratePlanRepo, policyRepo)
+	paymentService := payment.NewPaymentService(paymentRepo, gateway)
+	reservationService := services.NewReservationService(reservationRepo, roomRepo, paymentService, pricingService, configurations.Reservation.HoldTTL)
+	waitlistService := services.NewWaitlistService(repository.NewWaitlistRepository(db), reservationRepo, roomTypeRepo, configurations.Reservation.WaitlistOfferTTL)
 	handler := handlers.NewReservationHandler(reservationService)
+	waitlistHandler := handlers.NewWaitlistHandler(waitlistService)
 
-	r.HandleFunc("POST /create-reservation", handler.CreateReservation)
-	r.HandleFunc("GET /me", handler.GetUserReservations)
-	r.HandleFunc("GET /reservation-details/{reservationID}", handler.GetReservation)
-	r.HandleFunc("PATCH /cancel/{reservationID}", handler.CancelReservation)
+	// guests may have to verify their email before they can reserve rooms
+	requireVerified := func(next http.Handler) http.Handler { return next }
+	if configurations.Reservation.RequireVerifiedEmail {
+		requireVerified = middleware.RequireVerifiedEmail(userRepository.NewUserRepository(db))
+	}
 
-	return middleware.Authenticate(http.StripPrefix("/api/v1/reservation", r))
+	reservations := http.NewServeMux()
+	reservations.Handle("POST /create-reservation", requireVerified(http.HandlerFunc(handler.CreateReservation)))
+	reservations.HandleFunc("GET /me", handler.GetUserReservations)
+	reservations.HandleFunc("GET /reservation-details/{reservationID}", handler.GetReservation)
+	reservations.HandleFunc("PATCH /cancel/{reservationID}", handler.CancelReservation)
+	reservations.HandleFunc("PATCH /{reservationID}", handler.ModifyReservation)
+	reservations.HandleFunc("GET /history/{reservationID}", handler.GetReservationHistory)
+
+	//___ Group bookings ___//
+	reservations.Handle("POST /bookings", requireVerified(http.HandlerFunc(handler.CreateBooking)))
+	reservations.HandleFunc("GET /bookings/{bookingID}", handler.GetBooking)
+	reservations.HandleFunc("PATCH /bookings/{bookingID}/cancel", handler.CancelBooking)
+
+	//___ Waitlist of sold-out room types ___//
+	reservations.HandleFunc("POST /waitlist", waitlistHandler.JoinWaitlist)
+	reservations.HandleFunc("GET /waitlist", waitlistHandler.GetUserWaitlist)
+	reservations.HandleFunc("DELETE /waitlist/{entryID}", waitlistHandler.LeaveWaitlist)
+
+	//___ Guest lookup by confirmation code: public, so it is registered on the shared mux with its full path ___//
+	lookupLimiter := middleware.NewIPRateLimiter(rate.Every(12*time.Second), 5)
+	r.Handle("POST /api/v1/reservation/lookup", middleware.Limit(lookupLimiter)(http.HandlerFunc(handler.LookupReservation)))
+
+	//___Front desk routes ___//
+	staffRoles := []constants.Role{constants.STAFF, constants.MANAGER, constants.ADMIN, constants.PROPERTYOWNER}
+	reservations.Handle("POST /{reservationID}/check-in", middleware.RoleCheck(staffRoles, http.HandlerFunc(handler.CheckIn)))
+	reservations.Handle("POST /{reservationID}/check-out", middleware.RoleCheck(staffRoles, http.HandlerFunc(handler.CheckOut)))
+	reservations.Handle("POST /{reservationID}/no-show", middleware.RoleCheck(staffRoles, http.HandlerFunc(handler.MarkNoShow)))
+
+	return middleware.Authenticate(http.StripPrefix("/api/v1/reservation", reservations))
 }
\ No newline at end of file
@@ -0,0 +1,46 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gatimugabriel/hotel-reservation-system/internal/config"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/infrastructure/database"
+)
+
+func TestReservationRoutesNeedAuthentication(t *testing.T) {
+	configurations := &config.Config{}
+	db := &database.Service{}
+	r := http.NewServeMux()
+	r.Handle("/api/v1/auth/", RegisterAuthRoutes(configurations, db, r))
+	r.Handle("/api/v1/room/", RegisterRoomRoutes(db, r))
+	r.Handle("/api/v1/reservation/", RegisterReservationRoutes(configurations, db, r, payment.NewFakeGateway()))
+
+	reservationID := "5f0c6d3e-8f3a-4b7e-9a52-1d2c3b4a5e6f"
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodPost, "/api/v1/reservation/" + reservationID + "/check-in", http.StatusUnauthorized},
+		{http.MethodPost, "/api/v1/reservation/waitlist", http.StatusUnauthorized},
+		// reservation patterns must not be reachable through other groups' prefixes
+		{http.MethodPost, "/api/v1/room/" + reservationID + "/check-in", http.StatusNotFound},
+		{http.MethodPost, "/api/v1/auth/" + reservationID + "/no-show", http.StatusNotFound},
+		{http.MethodPost, "/api/v1/auth/waitlist", http.StatusNotFound},
+		{http.MethodPatch, "/api/v1/room/" + reservationID, http.StatusNotFound},
+		// the guest lookup stays public
+		{http.MethodPost, "/api/v1/reservation/lookup", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, strings.NewReader("not json")))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
\ No newline at end of file
@@ -3,6 +3,8 @@ package router
 import (
 	"github.com/gatimugabriel/hotel-reservation-system/internal/api/handlers"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/constants"
+	pricingRepository "github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/repository"
+	pricingServices "github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/services"
 	reservationRepository "github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/repository"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/repository"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/services"
@@ -19,10 +21,17 @@ func RegisterRoomRoutes(db *database.Service, r *http.ServeMux) http.Handler {
 	roomRepo := repository.NewRoomRepository(db)
 	roomTypeRepo := repository.NewRoomTypeRepository(db)
 	reservationRepo := reservationRepository.NewReservationRepository(db)
+	ratePlanRepo := pricingRepository.NewRatePlanRepository(db)
+	policyRepo := pricingRepository.NewCancellationPolicyRepository(db)
 
-	roomService := services.NewRoomService(roomRepo, roomTypeRepo, reservationRepo)
+	pricingService := pricingServices.NewPricingService(roomTypeRepo, ratePlanRepo, policyRepo)
+	ratePlanService := pricingServices.NewRatePlanService(ratePlanRepo)
+	policyService := pricingServices.NewCancellationPolicyService(policyRepo)
+	roomService := services.NewRoomService(roomRepo, roomTypeRepo, reservationRepo, pricingService)
 	roomTypeService := services.NewRoomTypeService(roomTypeRepo)
 	handler := handlers.NewRoomHandler(roomService, roomTypeService)
+	ratePlanHandler := handlers.NewRatePlanHandler(ratePlanService)
+	policyHandler := handlers.NewCancellationPolicyHandler(policyService)
 
 	allowedCreationRoles := []constants.Role{constants.MANAGER, constants.PROPERTYOWNER, constants.ADMIN}
 	roleCheckMiddleware := middleware2.AuthWithRoleCheck(allowedCreationRoles)
@@ -39,6 +48,43 @@ func RegisterRoomRoutes(db *database.Service, r *http.ServeMux) http.Handler {
 	r.Handle("POST /create-type", adminHandlers[1])
 	r.Handle("POST /create-bedtype", adminHandlers[2]) //bed types
 
+	// rate plans
+	ratePlanHandlers := middleware2.ApplyMiddlewareToMany(
+		roleCheckMiddleware,
+		ratePlanHandler.CreateRatePlan,
+		ratePlanHandler.ListRatePlans,
+		ratePlanHandler.GetRatePlan,
+		ratePlanHandler.UpdateRatePlan,
+		ratePlanHandler.DeleteRatePlan,
+	)
+	r.Handle("POST /rate-plans", ratePlanHandlers[0])
+	r.Handle("GET /rate-plans", ratePlanHandlers[1])
+	r.Handle("GET /rate-plans/{ratePlanID}", ratePlanHandlers[2])
+	r.Handle("PUT /rate-plans/{ratePlanID}", ratePlanHandlers[3])
+	r.Handle("DELETE /rate-plans/{ratePlanID}", ratePlanHandlers[4])
+
+	// cancellation policies
+	policyHandlers := middleware2.ApplyMiddlewareToMany(
+		roleCheckMiddleware,
+		policyHandler.CreateCancellationPolicy,
+		policyHandler.ListCancellationPolicies,
+		policyHandler.GetCancellationPolicy,
+		policyHandler.DeleteCancellationPolicy,
+	)
+	r.Handle("POST /cancellation-policies", policyHandlers[0])
+	r.Handle("GET /cancellation-policies", policyHandlers[1])
+	r.Handle("GET /cancellation-policies/{policyID}", policyHandlers[2])
+	r.Handle("DELETE /cancellation-policies/{policyID}", policyHandlers[3])
+
+	// maintenance windows
+	maintenanceHandlers := middleware2.ApplyMiddlewareToMany(
+		roleCheckMiddleware,
+		handler.ScheduleMaintenance,
+		handler.GetMaintenanceWindows,
+	)
+	r.Handle("POST /maintenance-windows", maintenanceHandlers[0])
+	r.Handle("GET /maintenance-windows", maintenanceHandlers[1])
+
 	//r.Handle("POST /create-room",
 	//	middleware.Authenticate(
 	//		middleware.RoleCheck([]constants.Role{constants.MANAGER, constants.PROPERTYOWNER, constants.ADMIN},
@@ -2,25 +2,37 @@ package router
 
 import (
 	"github.com/gatimugabriel/hotel-reservation-system/internal/api/handlers"
-	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/repository"
-	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/services"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/config"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/constants"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/infrastructure/database"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/middleware"
 	"net/http"
 )
 
-// RegisterUserProfileRoutes registers User API endpoints
+// RegisterUserProfileRoutes registers User API endpoints.
+// They get a mux of their own: patterns such as "GET /me" would clash with other groups' on the shared one
+// @param configurations -> application config (email verification & password reset)
 // @param db -> database service
-// @param r -> http ServeMux (router)
 // @return http.Handler
-func RegisterUserProfileRoutes(db *database.Service, r *http.ServeMux) http.Handler {
-	userRepo := repository.NewUserRepository(db)
-	userService := services.NewUserService(userRepo)
+func RegisterUserProfileRoutes(configurations *config.Config, db *database.Service) http.Handler {
+	userService := newUserService(configurations, db)
 	handler := handlers.NewUserHandler(userService)
+	r := http.NewServeMux()
 
-	r.HandleFunc("GET /:id", handler.GetUserProfile)
-	//r.HandleFunc("PUT /:id", handler.UpdateUserProfile)
-	//r.HandleFunc("DELETE /:id", handler.DeleteUser)
-	//r.HandleFunc("GET /all", handler.GetUsers)
+	//___ The authenticated user's own account ___//
+	r.HandleFunc("GET /me", handler.GetMyProfile)
+	r.HandleFunc("PATCH /me", handler.UpdateMyProfile)
+	r.HandleFunc("DELETE /me", handler.DeleteMyAccount)
+	r.HandleFunc("POST /me/password", handler.ChangePassword)
+	r.HandleFunc("POST /me/deactivate", handler.DeactivateMyAccount)
+	r.HandleFunc("GET /sessions", handler.GetSessions)
+	r.HandleFunc("DELETE /sessions/{sessionID}", handler.RevokeSession)
 
-	return http.StripPrefix("/api/v1/user", r)
+	//___ Other users' accounts (admin): only those below the admin's role can be changed ___//
+	adminRoles := []constants.Role{constants.MANAGER, constants.ADMIN, constants.PROPERTYOWNER}
+	r.Handle("GET /{userID}", middleware.RoleCheck(adminRoles, http.HandlerFunc(handler.GetUser)))
+	r.Handle("PATCH /{userID}", middleware.RoleCheck(adminRoles, http.HandlerFunc(handler.UpdateUser)))
+	r.Handle("DELETE /{userID}", middleware.RoleCheck(adminRoles, http.HandlerFunc(handler.DeleteUser)))
+
+	return middleware.Authenticate(http.StripPrefix("/api/v1/user", r))
 }
\ No newline at end of file
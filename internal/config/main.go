@@ -4,7 +4,9 @@ import (
 	"golang.org/x/oauth2/google"
 	"log"
 	"os"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/joho/godotenv"
 	"golang.org/x/oauth2"
@@ -14,7 +16,9 @@ type Config struct {
 	Database DatabaseConfig
 	Auth     AuthConfig
 	//AuthGoogle GoogleOAuthConfig
-	Server ServerConfig
+	Server      ServerConfig
+	Payment     PaymentConfig
+	Reservation ReservationConfig
 }
 
 type DatabaseConfig struct {
@@ -27,8 +31,12 @@ type DatabaseConfig struct {
 }
 
 type AuthConfig struct {
-	AccessTokenSecret  string
-	RefreshTokenSecret string
+	AccessTokenSecret      string
+	RefreshTokenSecret     string
+	EmailVerificationTTL   time.Duration // how long an emailed verification link works
+	VerificationResendWait time.Duration // how long after a verification or password reset email a user may ask for another
+	PasswordResetTTL       time.Duration // how long an emailed password reset link works
+	InviteTTL              time.Duration // how long the link inviting someone to choose their password works
 }
 
 type ServerConfig struct {
@@ -38,6 +46,20 @@ type ServerConfig struct {
 	BaseURL        string
 }
 
+type PaymentConfig struct {
+	Gateway          string // the payment processor charging guests, see payment.NewGateway
+	WebhookSecret    string
+	WebhookTolerance time.Duration
+}
+
+type ReservationConfig struct {
+	HoldTTL           time.Duration // how long an unpaid reservation keeps its room
+	HoldSweepInterval time.Duration // how often expired holds are released and freed rooms offered to the waitlist
+	WaitlistOfferTTL  time.Duration // how long a room offered to a waitlisted guest is held for them
+
+	RequireVerifiedEmail bool // whether users must verify their email before they can reserve rooms
+}
+
 var GoogleOAuthConfig = &oauth2.Config{
 	ClientID:     os.Getenv("GOOGLE_OAUTH_CLIENT_ID"),
 	ClientSecret: os.Getenv("GOOGLE_OAUTH_CLIENT_ID_SECRET"),
@@ -46,9 +68,17 @@ var GoogleOAuthConfig = &oauth2.Config{
 		"https://www.googleapis.com/auth/userinfo.email",
 		"https://www.googleapis.com/auth/userinfo.profile",
 	},
-	Endpoint: google.Endpoint,
+	Endpoint: oauth2.Endpoint{
+		AuthURL:   google.Endpoint.AuthURL,
+		TokenURL:  stringFromEnv("GOOGLE_OAUTH_TOKEN_URL", google.Endpoint.TokenURL),
+		AuthStyle: google.Endpoint.AuthStyle,
+	},
 }
 
+// GoogleUserInfoURL : where the profile of a user signing in with Google is fetched from.
+// Like the token URL, it can be pointed elsewhere (e.g. at a stand-in) from the environment
+var GoogleUserInfoURL = stringFromEnv("GOOGLE_OAUTH_USERINFO_URL", "https://openidconnect.googleapis.com/v1/userinfo")
+
 var (
 	cfg  *Config
 	once sync.Once
@@ -74,6 +104,11 @@ func LoadConfig() (*Config, error) {
 			Auth: AuthConfig{
 				AccessTokenSecret:  os.Getenv("JWT_ACCESS_SECRET"),
 				RefreshTokenSecret: os.Getenv("JWT_REFRESH_SECRET"),
+
+				EmailVerificationTTL:   durationFromEnv("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+				VerificationResendWait: durationFromEnv("EMAIL_VERIFICATION_RESEND_WAIT", time.Minute),
+				PasswordResetTTL:       durationFromEnv("PASSWORD_RESET_TTL", time.Hour),
+				InviteTTL:              durationFromEnv("USER_INVITE_TTL", 72*time.Hour),
 			},
 			Server: ServerConfig{
 				Environment: os.Getenv("SERVER_ENVIRONMENT"),
@@ -85,6 +120,18 @@ func LoadConfig() (*Config, error) {
 				},
 				BaseURL: os.Getenv("SERVER_BASE_URL"),
 			},
+			Payment: PaymentConfig{
+				Gateway:          os.Getenv("PAYMENT_GATEWAY"),
+				WebhookSecret:    os.Getenv("PAYMENT_WEBHOOK_SECRET"),
+				WebhookTolerance: durationFromEnv("PAYMENT_WEBHOOK_TOLERANCE", 5*time.Minute),
+			},
+			Reservation: ReservationConfig{
+				HoldTTL:           durationFromEnv("RESERVATION_HOLD_TTL", 15*time.Minute),
+				HoldSweepInterval: durationFromEnv("RESERVATION_HOLD_SWEEP_INTERVAL", time.Minute),
+				WaitlistOfferTTL:  durationFromEnv("RESERVATION_WAITLIST_OFFER_TTL", 2*time.Hour),
+
+				RequireVerifiedEmail: boolFromEnv("RESERVATION_REQUIRE_VERIFIED_EMAIL", false),
+			},
 		}
 	})
 
@@ -93,4 +140,40 @@ func LoadConfig() (*Config, error) {
 	}
 
 	return cfg, nil
+}
+
+// stringFromEnv reads a setting, falling back to def when unset
+func stringFromEnv(key string, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return def
+}
+
+// boolFromEnv parses a setting such as "true", falling back to def when unset or invalid
+func boolFromEnv(key string, def bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("invalid %s %q, using %t", key, value, def)
+		return def
+	}
+	return b
+}
+
+// durationFromEnv parses a duration such as "5m", falling back to def when unset or invalid
+func durationFromEnv(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("invalid %s %q, using %s", key, value, def)
+		return def
+	}
+	return d
 }
\ No newline at end of file
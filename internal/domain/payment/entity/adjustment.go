@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Adjustment : a change to the amount due on a payment after it was taken, e.g. when a reservation is modified.
+// A positive amount is charged to the guest, a negative one refunded
+type Adjustment struct {
+	ID        uuid.UUID   `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	PaymentID uuid.UUID   `gorm:"type:uuid;not null;index" json:"payment_id"`
+	Amount    money.Money `gorm:"type:decimal(10,2);not null" json:"amount"`
+	Currency  string      `gorm:"type:varchar(3);not null" json:"currency"`
+	Reason    string      `gorm:"type:text" json:"reason"`
+
+	// PENDING: due, collected with the payment or at the front desk. SUCCESS: charged. REFUNDED: returned. FAILED: declined
+	Status           Status `gorm:"type:varchar(20);not null;default:PENDING" json:"status"`
+	GatewayReference string `gorm:"type:varchar(100);index" json:"gateway_reference,omitempty"`
+	FailureReason    string `gorm:"type:text" json:"failure_reason,omitempty"`
+
+	// RefundedAmount is the part of a charged adjustment returned to the guest under its reference
+	RefundedAmount money.Money `gorm:"type:decimal(10,2);not null;default:0" json:"refunded_amount"`
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+func (Adjustment) TableName() string {
+	return "payment_adjustments"
+}
+
+// BeforeSave keeps the currency column in line with the amount
+func (a *Adjustment) BeforeSave(tx *gorm.DB) error {
+	if a.Amount.Currency() != "" {
+		a.Currency = a.Amount.Currency()
+	}
+	return nil
+}
+
+// AfterFind labels the amounts read from the numeric columns with the adjustment's currency
+func (a *Adjustment) AfterFind(tx *gorm.DB) error {
+	if a.Currency != "" {
+		a.Amount = a.Amount.WithCurrency(a.Currency)
+		a.RefundedAmount = a.RefundedAmount.WithCurrency(a.Currency)
+	}
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,6 @@
+package entity
+
+import "errors"
+
+// ErrPaymentNotFound is returned when no payment matches a gateway reference
+var ErrPaymentNotFound = errors.New("payment not found")
\ No newline at end of file
@@ -3,6 +3,8 @@ package entity
 import (
 	"encoding/json"
 	userEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/money"
+	"gorm.io/gorm"
 	"time"
 
 	"github.com/google/uuid"
@@ -24,25 +26,94 @@ const (
 type Status string
 
 const (
-	StatusPending  Status = "PENDING"
-	StatusSuccess  Status = "SUCCESS"
-	StatusFailed   Status = "FAILED"
-	StatusRefunded Status = "REFUNDED"
+	StatusPending    Status = "PENDING"
+	StatusAuthorized Status = "AUTHORIZED"
+	StatusSuccess    Status = "SUCCESS"
+	StatusFailed     Status = "FAILED"
+	StatusVoided     Status = "VOIDED"
+	StatusRefunded   Status = "REFUNDED"
+
+	StatusPartiallyRefunded Status = "PARTIALLY_REFUNDED"
 )
 
 // Payment : represents a payment transaction
 type Payment struct {
 	ID             uuid.UUID       `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
-	Amount         float64         `gorm:"type:decimal(10,2);not null" json:"amount" validate:"required,min=0"`
+	Amount         money.Money     `gorm:"type:decimal(10,2);not null" json:"amount" validate:"min=0"`
 	Currency       string          `gorm:"type:varchar(3);not null" json:"currency" validate:"required,len=3"`
-	PaymentMethod  Method          `gorm:"type:varchar(20);not null" json:"payment_method" validate:"required,oneof=CREDIT_CARD DEBIT_CARD PAYPAL BANK_TRANSFER CRYPTO"`
-	PaymentStatus  Status          `gorm:"type:varchar(20);not null;default:PENDING" json:"payment_status" validate:"required,oneof=PENDING SUCCESS FAILED REFUNDED"`
+	PaymentMethod  Method          `gorm:"type:varchar(20);not null" json:"payment_method" validate:"required,oneof=CREDIT_CARD DEBIT_CARD PAYPAL BANK_TRANSFER CRYPTO CASH"`
+	PaymentStatus  Status          `gorm:"type:varchar(20);not null;default:PENDING" json:"payment_status" validate:"required,oneof=PENDING AUTHORIZED SUCCESS FAILED VOIDED REFUNDED PARTIALLY_REFUNDED"`
 	TransactionID  string          `gorm:"type:varchar(100);not null;uniqueIndex" json:"transaction_id" validate:"required"`
 	PaymentDetails json.RawMessage `gorm:"type:jsonb" json:"payment_details" validate:"required"`
 
+	// GatewayReference is the processor's id for the authorization/charge
+	GatewayReference string `gorm:"type:varchar(100);index" json:"gateway_reference,omitempty"`
+	FailureReason    string `gorm:"type:text" json:"failure_reason,omitempty"`
+
+	// RefundedAmount is the part of what was captured, adjustments included, returned to the guest so far
+	RefundedAmount money.Money `gorm:"type:decimal(10,2);not null;default:0" json:"refunded_amount"`
+
+	// Adjustments are the changes to the amount due since the payment was taken, oldest first
+	Adjustments []Adjustment `gorm:"foreignKey:PaymentID" json:"adjustments,omitempty"`
+
 	UserID uuid.UUID       `gorm:"type:uuid;not null;index" json:"user_id" validate:"required"`
 	User   userEntity.User `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"user,omitempty"`
 
 	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// BeforeSave keeps the currency column in line with the amount
+func (p *Payment) BeforeSave(tx *gorm.DB) error {
+	if p.Amount.Currency() != "" {
+		p.Currency = p.Amount.Currency()
+	}
+	return nil
+}
+
+// IsCaptured reports whether the payment's own charge has been taken, whether or not it was refunded since
+func (s Status) IsCaptured() bool {
+	return s == StatusSuccess || s == StatusPartiallyRefunded || s == StatusRefunded
+}
+
+// CanSettleAs reports whether a processor event may move a payment in this status to next.
+// Events are not delivered in order, so a late one must not undo a later outcome:
+// a capture, decline or void only settles a payment still awaiting its outcome, and a refund only follows a capture
+func (s Status) CanSettleAs(next Status) bool {
+	switch next {
+	case StatusSuccess, StatusFailed, StatusVoided:
+		return s == StatusPending || s == StatusAuthorized
+	case StatusRefunded:
+		return s.IsCaptured()
+	default:
+		return false
+	}
+}
+
+// Captured is what the guest has been charged: the payment once captured, and the adjustments charged on top of it
+func (p *Payment) Captured() money.Money {
+	if !p.PaymentStatus.IsCaptured() {
+		return money.Zero(p.Amount.Currency())
+	}
+	captured := p.Amount
+	for _, adjustment := range p.Adjustments {
+		if adjustment.Status == StatusSuccess {
+			captured = captured.Add(adjustment.Amount)
+		}
+	}
+	return captured
+}
+
+// Refundable is the captured amount not yet refunded
+func (p *Payment) Refundable() money.Money {
+	return p.Captured().Sub(p.RefundedAmount)
+}
+
+// AfterFind labels the amount read from the numeric column with the payment's currency
+func (p *Payment) AfterFind(tx *gorm.DB) error {
+	if p.Currency != "" {
+		p.Amount = p.Amount.WithCurrency(p.Currency)
+		p.RefundedAmount = p.RefundedAmount.WithCurrency(p.Currency)
+	}
+	return nil
 }
\ No newline at end of file
@@ -0,0 +1,82 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// WebhookEventType : kind of asynchronous status event sent by the payment processor
+type WebhookEventType string
+
+const (
+	EventPaymentSucceeded WebhookEventType = "payment.succeeded"
+	EventPaymentFailed    WebhookEventType = "payment.failed"
+	EventPaymentVoided    WebhookEventType = "payment.voided"
+	EventPaymentRefunded  WebhookEventType = "payment.refunded"
+)
+
+// PaymentStatus returns the payment status an event settles a payment in
+func (t WebhookEventType) PaymentStatus() (Status, bool) {
+	switch t {
+	case EventPaymentSucceeded:
+		return StatusSuccess, true
+	case EventPaymentFailed:
+		return StatusFailed, true
+	case EventPaymentVoided:
+		return StatusVoided, true
+	case EventPaymentRefunded:
+		return StatusRefunded, true
+	default:
+		return "", false
+	}
+}
+
+// WebhookPayload : body of a webhook delivery
+type WebhookPayload struct {
+	ID      string           `json:"id" validate:"required,max=100"`
+	Type    WebhookEventType `json:"type" validate:"required"`
+	Created int64            `json:"created"`
+	Data    struct {
+		// Reference is the gateway reference of the payment the event is about
+		Reference     string `json:"reference" validate:"required,max=100"`
+		FailureReason string `json:"failure_reason"`
+	} `json:"data"`
+}
+
+// Settlement : the outcome a webhook event applies to a payment & its reservation
+type Settlement struct {
+	Reference     string
+	PaymentStatus Status
+	FailureReason string
+	// ReservationStatus is applied to the linked reservation if it is still PENDING. Empty leaves it untouched
+	ReservationStatus string
+}
+
+// WebhookOutcome : what applying a webhook event did to its payment
+type WebhookOutcome int
+
+const (
+	// WebhookDuplicate : the event was delivered before, nothing changed
+	WebhookDuplicate WebhookOutcome = iota
+	// WebhookApplied : the payment and its pending reservations were settled
+	WebhookApplied
+	// WebhookIgnored : the event came after a later outcome of the payment and was only recorded
+	WebhookIgnored
+	// WebhookOrphaned : the payment was captured after its reservations were released, and is owed back to the guest
+	WebhookOrphaned
+)
+
+// OrphanedCaptureReason is recorded on a payment captured after its reservations were released
+const OrphanedCaptureReason = "captured after its reservation was released"
+
+// WebhookEvent : a processed webhook delivery, kept so redelivered events are recognised & ignored
+type WebhookEvent struct {
+	ID         string           `gorm:"type:varchar(100);primary_key" json:"id"`
+	Type       WebhookEventType `gorm:"type:varchar(50);not null" json:"type"`
+	PaymentID  uuid.UUID        `gorm:"type:uuid;not null;index" json:"payment_id"`
+	ReceivedAt time.Time        `gorm:"not null;default:CURRENT_TIMESTAMP" json:"received_at"`
+}
+
+func (WebhookEvent) TableName() string {
+	return "payment_webhook_events"
+}
\ No newline at end of file
@@ -0,0 +1,163 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/money"
+	"sync"
+)
+
+// FakeOutcome : scripted result of the next FakeGateway call
+type FakeOutcome int
+
+const (
+	FakeApprove FakeOutcome = iota
+	FakeDecline
+	FakeTimeout
+)
+
+// FakeGateway : deterministic in-process Gateway for local development & tests; NewGateway refuses it in production.
+// Every call consumes the next scripted outcome, and approves once the script runs out.
+// References are sequential ("fake_0001", "fake_0002", ...) so runs are reproducible
+type FakeGateway struct {
+	mu             sync.Mutex
+	script         []FakeOutcome
+	sequence       int
+	authorizations map[string]*fakeAuthorization
+}
+
+type fakeAuthorization struct {
+	authorized money.Money
+	captured   money.Money
+	refunded   money.Money
+	voided     bool
+}
+
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{
+		authorizations: make(map[string]*fakeAuthorization),
+	}
+}
+
+// Script queues outcomes for the following gateway calls, in order
+func (g *FakeGateway) Script(outcomes ...FakeOutcome) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.script = append(g.script, outcomes...)
+}
+
+func (g *FakeGateway) Authorize(ctx context.Context, req AuthorizationRequest) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.next(ctx); err != nil {
+		return "", err
+	}
+	if !req.Amount.IsPositive() {
+		return "", fmt.Errorf("%w: amount must be positive", ErrPaymentDeclined)
+	}
+
+	g.sequence++
+	reference := fmt.Sprintf("fake_%04d", g.sequence)
+	g.authorizations[reference] = &fakeAuthorization{
+		authorized: req.Amount,
+		captured:   money.Zero(req.Amount.Currency()),
+		refunded:   money.Zero(req.Amount.Currency()),
+	}
+	return reference, nil
+}
+
+func (g *FakeGateway) Capture(ctx context.Context, reference string, amount money.Money) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.next(ctx); err != nil {
+		return err
+	}
+	auth, err := g.lookup(reference)
+	if err != nil {
+		return err
+	}
+	if auth.voided || auth.captured.Add(amount).Cmp(auth.authorized) > 0 {
+		return fmt.Errorf("%w: capture exceeds authorized amount", ErrPaymentDeclined)
+	}
+	auth.captured = auth.captured.Add(amount)
+	return nil
+}
+
+func (g *FakeGateway) Void(ctx context.Context, reference string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.next(ctx); err != nil {
+		return err
+	}
+	auth, err := g.lookup(reference)
+	if err != nil {
+		return err
+	}
+	if !auth.captured.IsZero() {
+		return fmt.Errorf("%w: authorization already captured", ErrPaymentDeclined)
+	}
+	auth.voided = true
+	return nil
+}
+
+func (g *FakeGateway) Refund(ctx context.Context, reference string, amount money.Money) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.next(ctx); err != nil {
+		return err
+	}
+	auth, err := g.lookup(reference)
+	if err != nil {
+		return err
+	}
+	if auth.refunded.Add(amount).Cmp(auth.captured) > 0 {
+		return fmt.Errorf("%w: refund exceeds captured amount", ErrPaymentDeclined)
+	}
+	auth.refunded = auth.refunded.Add(amount)
+	return nil
+}
+
+// Captured reports how much has been captured (net of refunds) under a reference
+func (g *FakeGateway) Captured(reference string) money.Money {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	auth, ok := g.authorizations[reference]
+	if !ok {
+		return money.Money{}
+	}
+	return auth.captured.Sub(auth.refunded)
+}
+
+// next consumes the next scripted outcome
+func (g *FakeGateway) next(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrGatewayTimeout, err)
+	}
+
+	outcome := FakeApprove
+	if len(g.script) > 0 {
+		outcome, g.script = g.script[0], g.script[1:]
+	}
+
+	switch outcome {
+	case FakeDecline:
+		return fmt.Errorf("%w: declined by fake gateway", ErrPaymentDeclined)
+	case FakeTimeout:
+		return ErrGatewayTimeout
+	default:
+		return nil
+	}
+}
+
+func (g *FakeGateway) lookup(reference string) (*fakeAuthorization, error) {
+	auth, ok := g.authorizations[reference]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown reference %s", ErrPaymentDeclined, reference)
+	}
+	return auth, nil
+}
\ No newline at end of file
@@ -0,0 +1,56 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/money"
+)
+
+var (
+	// ErrPaymentDeclined is returned when the processor refuses an operation
+	ErrPaymentDeclined = errors.New("payment declined")
+	// ErrGatewayTimeout is returned when the processor does not answer in time. The outcome is unknown
+	ErrGatewayTimeout = errors.New("payment gateway timed out")
+)
+
+// AuthorizationRequest : what the gateway needs to place a hold on the guest's funds
+type AuthorizationRequest struct {
+	// Reference identifies the payment on our side; gateways use it to de-duplicate retries
+	Reference string
+	Amount    money.Money
+	Method    entity.Method
+	Details   json.RawMessage
+}
+
+// Gateway : an external payment processor.
+// Implementations return ErrPaymentDeclined or ErrGatewayTimeout (optionally wrapped) for those outcomes
+type Gateway interface {
+	// Authorize holds the amount and returns the processor's reference for the authorization
+	Authorize(ctx context.Context, req AuthorizationRequest) (string, error)
+	// Capture collects a previously authorized amount
+	Capture(ctx context.Context, reference string, amount money.Money) error
+	// Void releases an authorization that was not captured
+	Void(ctx context.Context, reference string) error
+	// Refund returns part or all of a captured amount
+	Refund(ctx context.Context, reference string, amount money.Money) error
+}
+
+// NewGateway returns the payment processor named by provider (PAYMENT_GATEWAY) for the server environment.
+// No real provider's adapter exists yet. The "fake" one moves no money and forgets its authorizations on restart,
+// so it is only for development & tests and is refused in production
+func NewGateway(provider, environment string) (Gateway, error) {
+	switch provider {
+	case "fake":
+		if environment == "production" {
+			return nil, errors.New("the fake payment gateway cannot be used in production: set PAYMENT_GATEWAY to a real provider")
+		}
+		return NewFakeGateway(), nil
+	case "":
+		return nil, errors.New("no payment gateway configured: set PAYMENT_GATEWAY")
+	default:
+		return nil, fmt.Errorf("unknown payment gateway %q", provider)
+	}
+}
\ No newline at end of file
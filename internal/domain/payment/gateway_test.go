@@ -0,0 +1,22 @@
+package payment
+
+import "testing"
+
+func TestNewGateway(t *testing.T) {
+	tests := []struct {
+		provider, environment string
+		wantErr               bool
+	}{
+		{provider: "fake", environment: "development"},
+		{provider: "fake", environment: ""},
+		{provider: "fake", environment: "production", wantErr: true},
+		{provider: "", environment: "development", wantErr: true},
+		{provider: "acme", environment: "development", wantErr: true},
+	}
+	for _, tt := range tests {
+		gateway, err := NewGateway(tt.provider, tt.environment)
+		if (err != nil) != tt.wantErr || (err == nil) == (gateway == nil) {
+			t.Errorf("NewGateway(%q, %q) = %v, %v, want error %v", tt.provider, tt.environment, gateway, err, tt.wantErr)
+		}
+	}
+}
\ No newline at end of file
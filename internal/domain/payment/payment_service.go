@@ -1,13 +1,347 @@
 package payment
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment/repository"
+	reservationEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/money"
 	"github.com/google/uuid"
+	"time"
+)
+
+var (
+	ErrUnsupportedMethod = errors.New("unsupported payment method")
+	ErrNotRefundable     = errors.New("refund exceeds the captured amount of the payment")
+	ErrNotVoidable       = errors.New("only an authorized payment awaiting capture can be voided")
 )
 
 type Service interface {
-	ProcessPayment(payment *entity.Payment) error
-	RefundPayment(paymentID uuid.UUID) error
-	GetPaymentHistory(userID uuid.UUID) ([]*entity.Payment, error)
+	ProcessPayment(ctx context.Context, payment *entity.Payment) error
+	RefundPayment(ctx context.Context, paymentID uuid.UUID, amount money.Money) (*entity.Payment, error)
+	VoidPayment(ctx context.Context, paymentID uuid.UUID, reason string) (*entity.Payment, error)
+	GetPaymentHistory(ctx context.Context, userID uuid.UUID) ([]*entity.Payment, error)
 	ValidatePaymentMethod(method entity.Method) error
+	HandleWebhookEvent(ctx context.Context, payload *entity.WebhookPayload) (bool, error)
+	AdjustPayment(ctx context.Context, paymentID uuid.UUID, amount money.Money, reason string) (*entity.Adjustment, error)
+}
+
+type ServiceImpl struct {
+	repo    repository.PaymentRepository
+	gateway Gateway
+}
+
+func NewPaymentService(repo repository.PaymentRepository, gateway Gateway) *ServiceImpl {
+	return &ServiceImpl{
+		repo:    repo,
+		gateway: gateway,
+	}
+}
+
+// ProcessPayment authorizes & captures a stored payment through the gateway and saves the outcome:
+//   - approved: SUCCESS
+//   - declined: FAILED, returns ErrPaymentDeclined
+//   - timed out: left PENDING for the processor's webhook to settle, returns ErrGatewayTimeout
+//
+// Cash payments are settled at the front desk and stay PENDING
+func (s *ServiceImpl) ProcessPayment(ctx context.Context, payment *entity.Payment) error {
+	if err := s.ValidatePaymentMethod(payment.PaymentMethod); err != nil {
+		return err
+	}
+	if payment.PaymentMethod == entity.MethodCash {
+		return nil
+	}
+
+	reference, err := s.gateway.Authorize(ctx, AuthorizationRequest{
+		Reference: payment.TransactionID,
+		Amount:    payment.Amount,
+		Method:    payment.PaymentMethod,
+		Details:   payment.PaymentDetails,
+	})
+	if err != nil {
+		return s.recordFailure(ctx, payment, err)
+	}
+	payment.GatewayReference = reference
+	payment.PaymentStatus = entity.StatusAuthorized
+
+	if err := s.gateway.Capture(ctx, reference, payment.Amount); err != nil {
+		if errors.Is(err, ErrPaymentDeclined) {
+			// release the hold rather than leave the guest's funds tied up
+			if voidErr := s.gateway.Void(ctx, reference); voidErr == nil {
+				payment.PaymentStatus = entity.StatusVoided
+			}
+		}
+		return s.recordFailure(ctx, payment, err)
+	}
+
+	payment.PaymentStatus = entity.StatusSuccess
+	payment.FailureReason = ""
+	if err := s.save(ctx, payment); err != nil {
+		return err
+	}
+	return nil
+}
+
+// RefundPayment returns part or all of a captured payment, adjustments included, to the guest.
+// The payment's own charge is refunded first, then the adjustments charged on top of it, each under its own reference.
+// The payment ends REFUNDED once nothing captured is left, PARTIALLY_REFUNDED otherwise
+func (s *ServiceImpl) RefundPayment(ctx context.Context, paymentID uuid.UUID, amount money.Money) (*entity.Payment, error) {
+	payment, err := s.repo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if amount.IsZero() {
+		return payment, nil
+	}
+
+	refundable := payment.Refundable()
+	if amount.IsNegative() || amount.Cmp(refundable) > 0 || payment.GatewayReference == "" {
+		return nil, ErrNotRefundable
+	}
+
+	// what is left of the payment's own charge: the refunds not made under an adjustment's reference were made under its own
+	ownLeft := payment.Amount.Sub(payment.RefundedAmount)
+	for _, adjustment := range payment.Adjustments {
+		ownLeft = ownLeft.Add(adjustment.RefundedAmount)
+	}
+
+	remaining := amount
+	refund := func(reference string, left money.Money) (money.Money, error) {
+		part := money.Min(remaining, left)
+		if !part.IsPositive() {
+			return money.Zero(amount.Currency()), nil
+		}
+		if err := s.gateway.Refund(ctx, reference, part); err != nil {
+			return money.Zero(amount.Currency()), fmt.Errorf("failed to refund payment: %w", err)
+		}
+		remaining = remaining.Sub(part)
+		return part, nil
+	}
+
+	_, refundErr := refund(payment.GatewayReference, ownLeft)
+	for i := range payment.Adjustments {
+		adjustment := &payment.Adjustments[i]
+		if refundErr != nil || !remaining.IsPositive() {
+			break
+		}
+		if adjustment.Status != entity.StatusSuccess {
+			continue
+		}
+		var part money.Money
+		part, refundErr = refund(adjustment.GatewayReference, adjustment.Amount.Sub(adjustment.RefundedAmount))
+		adjustment.RefundedAmount = adjustment.RefundedAmount.Add(part)
+	}
+
+	if refundErr == nil && remaining.IsPositive() {
+		refundErr = ErrNotRefundable
+	}
+	refunded := amount.Sub(remaining)
+	if refunded.IsZero() {
+		return nil, refundErr
+	}
+	// record what did go back even when a later part failed
+	payment.RefundedAmount = payment.RefundedAmount.Add(refunded)
+	payment.PaymentStatus = entity.StatusPartiallyRefunded
+	if !payment.Refundable().IsPositive() {
+		payment.PaymentStatus = entity.StatusRefunded
+	}
+	if err := s.save(ctx, payment); err != nil {
+		return nil, err
+	}
+	if refundErr != nil {
+		return nil, refundErr
+	}
+	return payment, nil
+}
+
+// VoidPayment releases the authorization of a payment whose capture is outstanding and saves it VOIDED with the reason.
+// Once voided the capture can no longer go through; a void the gateway refuses, because the capture did, leaves the payment as it was
+func (s *ServiceImpl) VoidPayment(ctx context.Context, paymentID uuid.UUID, reason string) (*entity.Payment, error) {
+	payment, err := s.repo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.PaymentStatus != entity.StatusAuthorized || payment.GatewayReference == "" {
+		return nil, ErrNotVoidable
+	}
+
+	if err := s.gateway.Void(ctx, payment.GatewayReference); err != nil {
+		return nil, fmt.Errorf("failed to void payment: %w", err)
+	}
+	payment.PaymentStatus = entity.StatusVoided
+	payment.FailureReason = reason
+	if err := s.save(ctx, payment); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// AdjustPayment records a change of the amount due on a payment and settles it where possible:
+//   - a payment not captured yet is simply due for the new amount
+//   - on a captured payment an increase is charged as a separate gateway transaction
+//     (left PENDING for cash, to be collected at the front desk), and a decrease is refunded
+//
+// A declined charge is recorded as a FAILED adjustment rather than returned as an error, so the balance stays visible
+func (s *ServiceImpl) AdjustPayment(ctx context.Context, paymentID uuid.UUID, amount money.Money, reason string) (*entity.Adjustment, error) {
+	if amount.IsZero() {
+		return nil, nil
+	}
+	payment, err := s.repo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	adjustment := &entity.Adjustment{
+		PaymentID: payment.ID,
+		Amount:    amount,
+		Reason:    reason,
+		Status:    entity.StatusPending,
+	}
+	captured := payment.PaymentStatus == entity.StatusSuccess || payment.PaymentStatus == entity.StatusPartiallyRefunded
+
+	switch {
+	case !captured:
+		payment.Amount = payment.Amount.Add(amount)
+		if err := s.save(ctx, payment); err != nil {
+			return nil, err
+		}
+
+	case amount.IsNegative():
+		refund := money.Min(amount.Neg(), payment.Refundable())
+		if _, err := s.RefundPayment(ctx, payment.ID, refund); err != nil {
+			return nil, err
+		}
+		adjustment.Status = entity.StatusRefunded
+
+	case payment.PaymentMethod != entity.MethodCash:
+		reference, err := s.gateway.Authorize(ctx, AuthorizationRequest{
+			Reference: payment.TransactionID + "-adj-" + uuid.NewString()[:8],
+			Amount:    amount,
+			Method:    payment.PaymentMethod,
+			Details:   payment.PaymentDetails,
+		})
+		if err == nil {
+			adjustment.GatewayReference = reference
+			err = s.gateway.Capture(ctx, reference, amount)
+		}
+
+		switch {
+		case err == nil:
+			adjustment.Status = entity.StatusSuccess
+		case errors.Is(err, ErrGatewayTimeout):
+			// left PENDING for the processor's webhook or staff to settle
+		default:
+			adjustment.Status = entity.StatusFailed
+			adjustment.FailureReason = err.Error()
+		}
+	}
+
+	if err := s.repo.CreateAdjustment(ctx, adjustment); err != nil {
+		return nil, err
+	}
+	return adjustment, nil
+}
+
+func (s *ServiceImpl) GetPaymentHistory(ctx context.Context, userID uuid.UUID) ([]*entity.Payment, error) {
+	return s.repo.GetByUserID(ctx, userID)
+}
+
+// ValidatePaymentMethod checks the method is one the hotel accepts
+func (s *ServiceImpl) ValidatePaymentMethod(method entity.Method) error {
+	switch method {
+	case entity.MethodCreditCard, entity.MethodDebitCard, entity.MethodPayPal,
+		entity.MethodBankTransfer, entity.MethodCrypto, entity.MethodCash:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedMethod, method)
+	}
+}
+
+// HandleWebhookEvent settles a payment from a verified processor event and moves a still-pending reservation along:
+// a successful payment confirms it, a failed or voided one cancels it.
+// An event arriving after a later outcome of the payment is ignored, and a capture arriving after the reservation
+// was released is refunded in full rather than kept.
+// It reports false when the event was delivered before, in which case nothing changes
+func (s *ServiceImpl) HandleWebhookEvent(ctx context.Context, payload *entity.WebhookPayload) (bool, error) {
+	status, ok := payload.Type.PaymentStatus()
+	if !ok {
+		return false, fmt.Errorf("%w: %s", ErrUnsupportedEvent, payload.Type)
+	}
+
+	settlement := entity.Settlement{
+		Reference:     payload.Data.Reference,
+		PaymentStatus: status,
+		FailureReason: payload.Data.FailureReason,
+	}
+	switch status {
+	case entity.StatusSuccess:
+		settlement.ReservationStatus = string(reservationEntity.StatusConfirmed)
+	case entity.StatusFailed, entity.StatusVoided:
+		settlement.ReservationStatus = string(reservationEntity.StatusCancelled)
+	}
+
+	event := &entity.WebhookEvent{
+		ID:         payload.ID,
+		Type:       payload.Type,
+		ReceivedAt: time.Now(),
+	}
+	outcome, err := s.repo.ApplyWebhookEvent(ctx, event, settlement)
+	if err != nil {
+		return false, err
+	}
+	applied := outcome != entity.WebhookDuplicate
+	if outcome == entity.WebhookOrphaned || (!applied && status == entity.StatusSuccess) {
+		if err := s.refundOrphanedCapture(ctx, event.PaymentID); err != nil {
+			return applied, err
+		}
+	}
+	return applied, nil
+}
+
+// refundOrphanedCapture gives back what was captured of a payment after its reservations were released.
+// A refund that failed is retried when the processor redelivers the event
+func (s *ServiceImpl) refundOrphanedCapture(ctx context.Context, paymentID uuid.UUID) error {
+	payment, err := s.repo.GetByID(ctx, paymentID)
+	if err != nil {
+		return err
+	}
+	if payment.FailureReason != entity.OrphanedCaptureReason || !payment.Refundable().IsPositive() {
+		return nil
+	}
+	if _, err := s.RefundPayment(ctx, payment.ID, payment.Refundable()); err != nil {
+		return fmt.Errorf("failed to refund payment %s %s: %w", payment.ID, entity.OrphanedCaptureReason, err)
+	}
+	return nil
+}
+
+// recordFailure saves a declined payment as FAILED. A timeout leaves the status untouched since the outcome is unknown
+func (s *ServiceImpl) recordFailure(ctx context.Context, payment *entity.Payment, cause error) error {
+	if errors.Is(cause, ErrGatewayTimeout) {
+		if err := s.save(ctx, payment); err != nil {
+			return err
+		}
+		return cause
+	}
+
+	if payment.PaymentStatus != entity.StatusVoided {
+		payment.PaymentStatus = entity.StatusFailed
+	}
+	payment.FailureReason = cause.Error()
+	if err := s.save(ctx, payment); err != nil {
+		return err
+	}
+	if !errors.Is(cause, ErrPaymentDeclined) {
+		return fmt.Errorf("%w: %v", ErrPaymentDeclined, cause)
+	}
+	return cause
+}
+
+func (s *ServiceImpl) save(ctx context.Context, payment *entity.Payment) error {
+	payment.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, payment); err != nil {
+		return fmt.Errorf("failed to save payment: %w", err)
+	}
+	return nil
 }
\ No newline at end of file
@@ -0,0 +1,371 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/money"
+	"github.com/google/uuid"
+)
+
+// memoryRepository : in-memory PaymentRepository
+type memoryRepository struct {
+	payments    map[uuid.UUID]entity.Payment
+	events      map[string]entity.WebhookEvent
+	adjustments []entity.Adjustment
+	released    map[uuid.UUID]bool
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{
+		payments: make(map[uuid.UUID]entity.Payment),
+		events:   make(map[string]entity.WebhookEvent),
+		released: make(map[uuid.UUID]bool),
+	}
+}
+
+func (m *memoryRepository) Create(ctx context.Context, payment *entity.Payment) error {
+	if payment.ID == uuid.Nil {
+		payment.ID = uuid.New()
+	}
+	m.payments[payment.ID] = *payment
+	return nil
+}
+
+// GetByID returns the payment with its adjustments, like the preloading repository does
+func (m *memoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Payment, error) {
+	payment, ok := m.payments[id]
+	if !ok {
+		return nil, errors.New("payment not found")
+	}
+	payment.Adjustments = nil
+	for _, adjustment := range m.adjustments {
+		if adjustment.PaymentID == id {
+			payment.Adjustments = append(payment.Adjustments, adjustment)
+		}
+	}
+	return &payment, nil
+}
+
+func (m *memoryRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.Payment, error) {
+	var payments []*entity.Payment
+	for _, payment := range m.payments {
+		if payment.UserID == userID {
+			payment := payment
+			payments = append(payments, &payment)
+		}
+	}
+	return payments, nil
+}
+
+func (m *memoryRepository) Update(ctx context.Context, payment *entity.Payment) error {
+	m.payments[payment.ID] = *payment
+	for _, adjustment := range payment.Adjustments {
+		for i := range m.adjustments {
+			if m.adjustments[i].ID == adjustment.ID {
+				m.adjustments[i].RefundedAmount = adjustment.RefundedAmount
+			}
+		}
+	}
+	return nil
+}
+
+// ApplyWebhookEvent settles payments like the database repository does, the payments in released standing for
+// those whose reservations are no longer pending
+func (m *memoryRepository) ApplyWebhookEvent(ctx context.Context, event *entity.WebhookEvent, settlement entity.Settlement) (entity.WebhookOutcome, error) {
+	for id, payment := range m.payments {
+		if payment.GatewayReference != settlement.Reference {
+			continue
+		}
+		event.PaymentID = id
+		if _, seen := m.events[event.ID]; seen {
+			return entity.WebhookDuplicate, nil
+		}
+		m.events[event.ID] = *event
+
+		outcome := entity.WebhookApplied
+		switch {
+		case settlement.PaymentStatus == entity.StatusSuccess && !payment.PaymentStatus.IsCaptured() &&
+			(m.released[id] || !payment.PaymentStatus.CanSettleAs(entity.StatusSuccess)):
+			outcome = entity.WebhookOrphaned
+			settlement.FailureReason = entity.OrphanedCaptureReason
+		case !payment.PaymentStatus.CanSettleAs(settlement.PaymentStatus):
+			return entity.WebhookIgnored, nil
+		}
+		payment.PaymentStatus = settlement.PaymentStatus
+		payment.FailureReason = settlement.FailureReason
+		m.payments[id] = payment
+		return outcome, nil
+	}
+	return entity.WebhookDuplicate, entity.ErrPaymentNotFound
+}
+
+func (m *memoryRepository) CreateAdjustment(ctx context.Context, adjustment *entity.Adjustment) error {
+	adjustment.ID = uuid.New()
+	m.adjustments = append(m.adjustments, *adjustment)
+	return nil
+}
+
+func newPayment(t *testing.T, repo *memoryRepository, method entity.Method) *entity.Payment {
+	t.Helper()
+	payment := &entity.Payment{
+		Amount:        money.MustParse("240.00", "USD"),
+		Currency:      "USD",
+		PaymentMethod: method,
+		PaymentStatus: entity.StatusPending,
+		TransactionID: uuid.NewString(),
+		UserID:        uuid.New(),
+	}
+	if err := repo.Create(context.Background(), payment); err != nil {
+		t.Fatal(err)
+	}
+	return payment
+}
+
+func TestProcessPayment(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     entity.Method
+		script     []FakeOutcome
+		wantErr    error
+		wantStatus entity.Status
+		wantCharge int64
+	}{
+		{"approved", entity.MethodCreditCard, nil, nil, entity.StatusSuccess, 24000},
+		{"declined on authorize", entity.MethodCreditCard, []FakeOutcome{FakeDecline}, ErrPaymentDeclined, entity.StatusFailed, 0},
+		{"timeout on authorize", entity.MethodCreditCard, []FakeOutcome{FakeTimeout}, ErrGatewayTimeout, entity.StatusPending, 0},
+		{"declined on capture is voided", entity.MethodDebitCard, []FakeOutcome{FakeApprove, FakeDecline}, ErrPaymentDeclined, entity.StatusVoided, 0},
+		{"timeout on capture stays authorized", entity.MethodPayPal, []FakeOutcome{FakeApprove, FakeTimeout}, ErrGatewayTimeout, entity.StatusAuthorized, 0},
+		{"cash is settled at the desk", entity.MethodCash, []FakeOutcome{FakeDecline}, nil, entity.StatusPending, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemoryRepository()
+			gateway := NewFakeGateway()
+			gateway.Script(tt.script...)
+			service := NewPaymentService(repo, gateway)
+
+			payment := newPayment(t, repo, tt.method)
+			err := service.ProcessPayment(context.Background(), payment)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("ProcessPayment unexpected error: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ProcessPayment error = %v, want %v", err, tt.wantErr)
+			}
+
+			stored, _ := repo.GetByID(context.Background(), payment.ID)
+			if stored.PaymentStatus != tt.wantStatus {
+				t.Errorf("stored status = %s, want %s", stored.PaymentStatus, tt.wantStatus)
+			}
+			if tt.wantStatus == entity.StatusFailed && stored.FailureReason == "" {
+				t.Error("expected a failure reason on a failed payment")
+			}
+			if got := gateway.Captured(stored.GatewayReference); got.Minor() != tt.wantCharge {
+				t.Errorf("captured = %s, want %d minor units", got, tt.wantCharge)
+			}
+		})
+	}
+}
+
+func TestRefundPayment(t *testing.T) {
+	repo := newMemoryRepository()
+	gateway := NewFakeGateway()
+	service := NewPaymentService(repo, gateway)
+	ctx := context.Background()
+
+	payment := newPayment(t, repo, entity.MethodCreditCard)
+	if _, err := service.RefundPayment(ctx, payment.ID, money.MustParse("10", "USD")); !errors.Is(err, ErrNotRefundable) {
+		t.Fatalf("refund of an uncaptured payment: error = %v, want %v", err, ErrNotRefundable)
+	}
+
+	if err := service.ProcessPayment(ctx, payment); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		amount     string
+		wantErr    error
+		wantStatus entity.Status
+		wantLeft   string
+	}{
+		{"40.00", nil, entity.StatusPartiallyRefunded, "200.00"},
+		{"0", nil, entity.StatusPartiallyRefunded, "200.00"},
+		{"200.01", ErrNotRefundable, entity.StatusPartiallyRefunded, "200.00"},
+		{"200.00", nil, entity.StatusRefunded, "0.00"},
+		{"0.01", ErrNotRefundable, entity.StatusRefunded, "0.00"},
+	}
+	for _, step := range steps {
+		_, err := service.RefundPayment(ctx, payment.ID, money.MustParse(step.amount, "USD"))
+		if !errors.Is(err, step.wantErr) {
+			t.Fatalf("refund of %s: error = %v, want %v", step.amount, err, step.wantErr)
+		}
+
+		stored, _ := repo.GetByID(ctx, payment.ID)
+		if stored.PaymentStatus != step.wantStatus {
+			t.Errorf("after refund of %s: status = %s, want %s", step.amount, stored.PaymentStatus, step.wantStatus)
+		}
+		if got := gateway.Captured(stored.GatewayReference); got.Decimal() != step.wantLeft {
+			t.Errorf("after refund of %s: captured = %s, want %s", step.amount, got.Decimal(), step.wantLeft)
+		}
+	}
+}
+
+func TestRefundPaymentWithAdjustments(t *testing.T) {
+	repo := newMemoryRepository()
+	gateway := NewFakeGateway()
+	service := NewPaymentService(repo, gateway)
+	ctx := context.Background()
+
+	payment := newPayment(t, repo, entity.MethodCreditCard)
+	if err := service.ProcessPayment(ctx, payment); err != nil {
+		t.Fatal(err)
+	}
+	increase, err := service.AdjustPayment(ctx, payment.ID, money.MustParse("60.00", "USD"), "stay extended")
+	if err != nil || increase.Status != entity.StatusSuccess {
+		t.Fatalf("AdjustPayment = %+v, %v, want the increase charged", increase, err)
+	}
+
+	if _, err := service.RefundPayment(ctx, payment.ID, money.MustParse("300.01", "USD")); !errors.Is(err, ErrNotRefundable) {
+		t.Fatalf("refund beyond payment & adjustment: error = %v, want %v", err, ErrNotRefundable)
+	}
+	refunded, err := service.RefundPayment(ctx, payment.ID, money.MustParse("270.00", "USD"))
+	if err != nil {
+		t.Fatalf("RefundPayment unexpected error: %v", err)
+	}
+	if refunded.PaymentStatus != entity.StatusPartiallyRefunded || refunded.Refundable().Decimal() != "30.00" {
+		t.Errorf("after refunding 270: %s with %s refundable, want PARTIALLY_REFUNDED with 30.00", refunded.PaymentStatus, refunded.Refundable().Decimal())
+	}
+	if own, adjusted := gateway.Captured(payment.GatewayReference), gateway.Captured(increase.GatewayReference); own.Decimal() != "0.00" || adjusted.Decimal() != "30.00" {
+		t.Errorf("captured = %s on the payment & %s on the adjustment, want its own charge refunded first", own.Decimal(), adjusted.Decimal())
+	}
+
+	if refunded, err = service.RefundPayment(ctx, payment.ID, money.MustParse("30.00", "USD")); err != nil || refunded.PaymentStatus != entity.StatusRefunded {
+		t.Fatalf("refund of the rest = %v, %v, want REFUNDED", refunded, err)
+	}
+	if adjusted := gateway.Captured(increase.GatewayReference); adjusted.Decimal() != "0.00" {
+		t.Errorf("captured on the adjustment = %s, want all of it refunded", adjusted.Decimal())
+	}
+}
+
+func TestVoidPayment(t *testing.T) {
+	tests := []struct {
+		name string
+		// capture makes the capture that timed out go through at the gateway
+		capture    bool
+		status     entity.Status
+		wantErr    error
+		wantStatus entity.Status
+	}{
+		{"outstanding capture is voided", false, entity.StatusAuthorized, nil, entity.StatusVoided},
+		{"capture that went through cannot be voided", true, entity.StatusAuthorized, ErrPaymentDeclined, entity.StatusAuthorized},
+		{"captured payment is not voidable", true, entity.StatusSuccess, ErrNotVoidable, entity.StatusSuccess},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := newMemoryRepository()
+			gateway := NewFakeGateway()
+			service := NewPaymentService(repo, gateway)
+			payment := authorizedPayment(t, repo, service, gateway)
+			if tt.capture {
+				if err := gateway.Capture(ctx, payment.GatewayReference, payment.Amount); err != nil {
+					t.Fatal(err)
+				}
+			}
+			stored := repo.payments[payment.ID]
+			stored.PaymentStatus = tt.status
+			repo.payments[payment.ID] = stored
+
+			_, err := service.VoidPayment(ctx, payment.ID, "hold expired")
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if got := repo.payments[payment.ID].PaymentStatus; got != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestValidatePaymentMethod(t *testing.T) {
+	service := NewPaymentService(newMemoryRepository(), NewFakeGateway())
+
+	if err := service.ValidatePaymentMethod(entity.MethodCrypto); err != nil {
+		t.Errorf("ValidatePaymentMethod(%s) unexpected error: %v", entity.MethodCrypto, err)
+	}
+	if err := service.ValidatePaymentMethod("CHEQUE"); !errors.Is(err, ErrUnsupportedMethod) {
+		t.Errorf("ValidatePaymentMethod(CHEQUE) error = %v, want %v", err, ErrUnsupportedMethod)
+	}
+}
+
+func TestAdjustPayment(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		method      entity.Method
+		capture     bool
+		script      []FakeOutcome
+		amount      string
+		wantStatus  entity.Status
+		wantAmount  string // amount due on the payment afterwards
+		wantCapture string // net captured under the original reference afterwards
+	}{
+		{"uncaptured payment is due for the new total", entity.MethodCreditCard, false, nil, "60.00", entity.StatusPending, "300.00", "0.00"},
+		{"cash balance is collected at the desk", entity.MethodCash, false, nil, "60.00", entity.StatusPending, "300.00", "0.00"},
+		{"increase on a captured payment is charged", entity.MethodCreditCard, true, nil, "60.00", entity.StatusSuccess, "240.00", "240.00"},
+		{"declined increase is recorded as failed", entity.MethodCreditCard, true, []FakeOutcome{FakeDecline}, "60.00", entity.StatusFailed, "240.00", "240.00"},
+		{"decrease on a captured payment is refunded", entity.MethodDebitCard, true, nil, "-40.00", entity.StatusRefunded, "240.00", "200.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemoryRepository()
+			gateway := NewFakeGateway()
+			service := NewPaymentService(repo, gateway)
+
+			payment := newPayment(t, repo, tt.method)
+			if tt.capture {
+				if err := service.ProcessPayment(ctx, payment); err != nil {
+					t.Fatal(err)
+				}
+			}
+			gateway.Script(tt.script...)
+
+			adjustment, err := service.AdjustPayment(ctx, payment.ID, money.MustParse(tt.amount, "USD"), "stay extended")
+			if err != nil {
+				t.Fatalf("AdjustPayment unexpected error: %v", err)
+			}
+			if adjustment.Status != tt.wantStatus {
+				t.Errorf("adjustment status = %s, want %s", adjustment.Status, tt.wantStatus)
+			}
+			if len(repo.adjustments) != 1 {
+				t.Errorf("recorded %d adjustments, want 1", len(repo.adjustments))
+			}
+
+			stored, _ := repo.GetByID(ctx, payment.ID)
+			if stored.Amount.Decimal() != tt.wantAmount {
+				t.Errorf("payment amount = %s, want %s", stored.Amount.Decimal(), tt.wantAmount)
+			}
+			if got := gateway.Captured(stored.GatewayReference); got.Decimal() != tt.wantCapture {
+				t.Errorf("captured = %s, want %s", got.Decimal(), tt.wantCapture)
+			}
+		})
+	}
+
+	// nothing to adjust
+	repo := newMemoryRepository()
+	payment := newPayment(t, repo, entity.MethodCreditCard)
+	adjustment, err := NewPaymentService(repo, NewFakeGateway()).AdjustPayment(ctx, payment.ID, money.Zero("USD"), "no-op")
+	if adjustment != nil || err != nil || len(repo.adjustments) != 0 {
+		t.Errorf("zero adjustment = %v, %v, want nothing recorded", adjustment, err)
+	}
+}
\ No newline at end of file
@@ -2,16 +2,24 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment/entity"
+	reservationEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/entity"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/infrastructure/database"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
 )
 
 type PaymentRepository interface {
 	Create(ctx context.Context, payment *entity.Payment) error
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.Payment, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.Payment, error)
 	Update(ctx context.Context, payment *entity.Payment) error
+	ApplyWebhookEvent(ctx context.Context, event *entity.WebhookEvent, settlement entity.Settlement) (entity.WebhookOutcome, error)
+	CreateAdjustment(ctx context.Context, adjustment *entity.Adjustment) error
 }
 
 type PaymentRepositoryImpl struct {
@@ -29,17 +37,148 @@ func (repo *PaymentRepositoryImpl) Create(ctx context.Context, payment *entity.P
 	return nil
 }
 
+// orderedAdjustments preloads a payment's adjustments oldest first
+func orderedAdjustments(db *gorm.DB) *gorm.DB {
+	return db.Order("created_at, id")
+}
+
 func (repo *PaymentRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*entity.Payment, error) {
 	var payment entity.Payment
-	if err := repo.db.WithContext(ctx).First(&payment, "id = ?", id).Error; err != nil {
+	if err := repo.db.WithContext(ctx).Preload("Adjustments", orderedAdjustments).First(&payment, "id = ?", id).Error; err != nil {
 		return nil, fmt.Errorf("failed to get payment: %w", err)
 	}
 	return &payment, nil
 }
 
+// GetByUserID returns a user's payments, most recent first
+func (repo *PaymentRepositoryImpl) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.Payment, error) {
+	var payments []*entity.Payment
+	if err := repo.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&payments).Error; err != nil {
+		return nil, fmt.Errorf("failed to get payments by user ID: %w", err)
+	}
+	return payments, nil
+}
+
+// Update saves the payment, along with what was refunded of its adjustments
 func (repo *PaymentRepositoryImpl) Update(ctx context.Context, payment *entity.Payment) error {
-	if err := repo.db.WithContext(ctx).Save(payment).Error; err != nil {
-		return fmt.Errorf("failed to update payment: %w", err)
+	return repo.db.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(payment).Error; err != nil {
+			return fmt.Errorf("failed to update payment: %w", err)
+		}
+		for _, adjustment := range payment.Adjustments {
+			if err := tx.Model(&entity.Adjustment{}).Where("id = ?", adjustment.ID).
+				Update("refunded_amount", adjustment.RefundedAmount).Error; err != nil {
+				return fmt.Errorf("failed to update payment adjustment: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (repo *PaymentRepositoryImpl) CreateAdjustment(ctx context.Context, adjustment *entity.Adjustment) error {
+	if err := repo.db.WithContext(ctx).Create(adjustment).Error; err != nil {
+		return fmt.Errorf("failed to create payment adjustment: %w", err)
+	}
+	return nil
+}
+
+// errDuplicateEvent rolls back the transaction of an event that was already processed
+var errDuplicateEvent = errors.New("webhook event already processed")
+
+// ApplyWebhookEvent records a webhook event and settles its payment & linked reservation in one transaction.
+// Events are applied only where the payment's current status allows it (see entity.Status.CanSettleAs), the others are recorded & ignored.
+// A capture reported once the reservations it paid for were released is recorded with entity.OrphanedCaptureReason,
+// leaving them untouched, and reported as entity.WebhookOrphaned for the caller to refund.
+// It reports entity.WebhookDuplicate, changing nothing, when the event ID has been recorded before
+func (repo *PaymentRepositoryImpl) ApplyWebhookEvent(ctx context.Context, event *entity.WebhookEvent, settlement entity.Settlement) (entity.WebhookOutcome, error) {
+	outcome := entity.WebhookApplied
+	err := repo.db.Transaction(ctx, func(tx *gorm.DB) error {
+		var payment entity.Payment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("gateway_reference = ?", settlement.Reference).First(&payment).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return entity.ErrPaymentNotFound
+			}
+			return fmt.Errorf("failed to lock payment: %w", err)
+		}
+
+		event.PaymentID = payment.ID
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+		if result.Error != nil {
+			return fmt.Errorf("failed to record webhook event: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errDuplicateEvent
+		}
+
+		orphaned := false
+		if settlement.PaymentStatus == entity.StatusSuccess && !payment.PaymentStatus.IsCaptured() {
+			// the money was taken whatever our records say; without a pending reservation to confirm it is owed back
+			var pending int64
+			if err := tx.Model(&reservationEntity.Reservation{}).
+				Where("payment_id = ? AND status = ?", payment.ID, reservationEntity.StatusPending).
+				Count(&pending).Error; err != nil {
+				return fmt.Errorf("failed to count pending reservations: %w", err)
+			}
+			orphaned = pending == 0 || !payment.PaymentStatus.CanSettleAs(entity.StatusSuccess)
+		}
+		switch {
+		case orphaned:
+			outcome = entity.WebhookOrphaned
+			settlement.FailureReason = entity.OrphanedCaptureReason
+			settlement.ReservationStatus = ""
+		case !payment.PaymentStatus.CanSettleAs(settlement.PaymentStatus):
+			outcome = entity.WebhookIgnored
+			return nil
+		}
+
+		if err := tx.Model(&payment).Updates(map[string]interface{}{
+			"payment_status": settlement.PaymentStatus,
+			"failure_reason": settlement.FailureReason,
+			"updated_at":     time.Now(),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update payment: %w", err)
+		}
+
+		if settlement.ReservationStatus != "" {
+			return settleReservations(tx, payment.ID, reservationEntity.Status(settlement.ReservationStatus))
+		}
+		return nil
+	})
+
+	if errors.Is(err, errDuplicateEvent) {
+		return entity.WebhookDuplicate, nil
+	}
+	if err != nil {
+		return entity.WebhookDuplicate, err
+	}
+	return outcome, nil
+}
+
+// settleReservations moves the pending reservations paid by a payment to the settled status
+// and records each transition in the reservation's history
+func settleReservations(tx *gorm.DB, paymentID uuid.UUID, next reservationEntity.Status) error {
+	var reservations []reservationEntity.Reservation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("payment_id = ? AND status = ?", paymentID, reservationEntity.StatusPending).
+		Find(&reservations).Error; err != nil {
+		return fmt.Errorf("failed to lock reservation: %w", err)
+	}
+
+	for _, reservation := range reservations {
+		change, err := reservationEntity.NewTransition(reservationEntity.SystemActor, reservation.ID, reservation.Status, next)
+		if err != nil {
+			return fmt.Errorf("failed to record reservation change: %w", err)
+		}
+		if err := tx.Model(&reservationEntity.Reservation{}).Where("id = ?", reservation.ID).Updates(map[string]interface{}{
+			"status":     next,
+			"updated_at": time.Now(),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update reservation: %w", err)
+		}
+		if err := tx.Create(change).Error; err != nil {
+			return fmt.Errorf("failed to record reservation change: %w", err)
+		}
 	}
 	return nil
 }
\ No newline at end of file
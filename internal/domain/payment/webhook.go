@@ -0,0 +1,83 @@
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader carries the processor's signature of a webhook delivery, formatted "t=<unix seconds>,v1=<hex hmac>"
+const SignatureHeader = "X-Payment-Signature"
+
+// DefaultWebhookTolerance is how far a delivery's timestamp may drift from our clock before it is treated as a replay
+const DefaultWebhookTolerance = 5 * time.Minute
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrStaleWebhook     = errors.New("webhook timestamp outside the tolerance window")
+	ErrUnsupportedEvent = errors.New("unsupported webhook event type")
+)
+
+// SignWebhook computes the signature header value for a body sent at the given time
+func SignWebhook(secret string, timestamp time.Time, body []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(webhookMAC(secret, ts, body))
+}
+
+// VerifyWebhookSignature checks the HMAC-SHA256 of "<timestamp>.<body>" against the signature header,
+// and rejects deliveries whose timestamp is more than tolerance away from now
+func VerifyWebhookSignature(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	if secret == "" {
+		return errors.New("webhook secret is not configured")
+	}
+
+	var ts string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			if sig, err := hex.DecodeString(value); err == nil {
+				signatures = append(signatures, sig)
+			}
+		}
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+
+	expected := webhookMAC(secret, ts, body)
+	valid := false
+	for _, sig := range signatures { // several v1 entries are sent while the secret is being rotated
+		if hmac.Equal(sig, expected) {
+			valid = true
+		}
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+
+	if drift := now.Sub(time.Unix(unix, 0)); drift > tolerance || drift < -tolerance {
+		return ErrStaleWebhook
+	}
+	return nil
+}
+
+func webhookMAC(secret, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
\ No newline at end of file
@@ -0,0 +1,230 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/money"
+	"github.com/google/uuid"
+)
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	const secret = "whsec_test"
+	body := []byte(`{"id":"evt_1","type":"payment.succeeded"}`)
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name    string
+		header  string
+		body    []byte
+		wantErr error
+	}{
+		{"valid", SignWebhook(secret, now, body), body, nil},
+		{"valid within tolerance", SignWebhook(secret, now.Add(-4*time.Minute), body), body, nil},
+		{"tampered body", SignWebhook(secret, now, body), []byte(`{"id":"evt_1","type":"payment.failed"}`), ErrInvalidSignature},
+		{"wrong secret", SignWebhook("other", now, body), body, ErrInvalidSignature},
+		{"replayed outside window", SignWebhook(secret, now.Add(-10*time.Minute), body), body, ErrStaleWebhook},
+		{"timestamp in the future", SignWebhook(secret, now.Add(10*time.Minute), body), body, ErrStaleWebhook},
+		{"missing header", "", body, ErrInvalidSignature},
+		{"malformed header", "v1=zz,t=abc", body, ErrInvalidSignature},
+		{"rotated secret", "t=1700000000,v1=00," + SignWebhook(secret, now, body)[len("t=1700000000,"):], body, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyWebhookSignature(secret, tt.header, tt.body, DefaultWebhookTolerance, now)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandleWebhookEventIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryRepository()
+	gateway := NewFakeGateway()
+	gateway.Script(FakeApprove, FakeTimeout) // capture times out, leaving the payment to the webhook
+	service := NewPaymentService(repo, gateway)
+
+	payment := newPayment(t, repo, entity.MethodCreditCard)
+	if err := service.ProcessPayment(ctx, payment); !errors.Is(err, ErrGatewayTimeout) {
+		t.Fatalf("ProcessPayment error = %v, want %v", err, ErrGatewayTimeout)
+	}
+
+	payload := &entity.WebhookPayload{ID: "evt_1", Type: entity.EventPaymentSucceeded}
+	payload.Data.Reference = payment.GatewayReference
+
+	applied, err := service.HandleWebhookEvent(ctx, payload)
+	if err != nil || !applied {
+		t.Fatalf("first delivery = %v, %v, want applied", applied, err)
+	}
+	stored, _ := repo.GetByID(ctx, payment.ID)
+	if stored.PaymentStatus != entity.StatusSuccess {
+		t.Errorf("status after event = %s, want %s", stored.PaymentStatus, entity.StatusSuccess)
+	}
+
+	// a redelivery of the same event must not change anything, even if its content differs
+	payload.Type = entity.EventPaymentFailed
+	applied, err = service.HandleWebhookEvent(ctx, payload)
+	if err != nil || applied {
+		t.Fatalf("duplicate delivery = %v, %v, want a no-op", applied, err)
+	}
+	stored, _ = repo.GetByID(ctx, payment.ID)
+	if stored.PaymentStatus != entity.StatusSuccess {
+		t.Errorf("status after duplicate = %s, want %s", stored.PaymentStatus, entity.StatusSuccess)
+	}
+
+	unknown := &entity.WebhookPayload{ID: "evt_2", Type: "payment.disputed"}
+	if _, err := service.HandleWebhookEvent(ctx, unknown); !errors.Is(err, ErrUnsupportedEvent) {
+		t.Errorf("unknown event type error = %v, want %v", err, ErrUnsupportedEvent)
+	}
+}
+
+// authorizedPayment processes a card payment whose capture times out, leaving it AUTHORIZED for the webhook to settle
+func authorizedPayment(t *testing.T, repo *memoryRepository, service *ServiceImpl, gateway *FakeGateway) *entity.Payment {
+	t.Helper()
+	gateway.Script(FakeApprove, FakeTimeout)
+	payment := newPayment(t, repo, entity.MethodCreditCard)
+	if err := service.ProcessPayment(context.Background(), payment); !errors.Is(err, ErrGatewayTimeout) {
+		t.Fatalf("ProcessPayment error = %v, want %v", err, ErrGatewayTimeout)
+	}
+	return payment
+}
+
+func TestHandleWebhookEventOutOfOrder(t *testing.T) {
+	setStatus := func(repo *memoryRepository, id uuid.UUID, status entity.Status, reason string) {
+		payment := repo.payments[id]
+		payment.PaymentStatus = status
+		payment.FailureReason = reason
+		repo.payments[id] = payment
+	}
+
+	tests := []struct {
+		name string
+		// prepare brings the payment, whose capture went through at the gateway, to the state the event finds it in
+		prepare      func(t *testing.T, repo *memoryRepository, service *ServiceImpl, id uuid.UUID)
+		event        entity.WebhookEventType
+		wantStatus   entity.Status
+		wantCaptured string
+	}{
+		{
+			name:         "success settles an outstanding capture",
+			prepare:      func(t *testing.T, repo *memoryRepository, service *ServiceImpl, id uuid.UUID) {},
+			event:        entity.EventPaymentSucceeded,
+			wantStatus:   entity.StatusSuccess,
+			wantCaptured: "240.00",
+		},
+		{
+			name: "late success after a refund is ignored",
+			prepare: func(t *testing.T, repo *memoryRepository, service *ServiceImpl, id uuid.UUID) {
+				setStatus(repo, id, entity.StatusSuccess, "")
+				if _, err := service.RefundPayment(context.Background(), id, money.MustParse("240.00", "USD")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			event:        entity.EventPaymentSucceeded,
+			wantStatus:   entity.StatusRefunded,
+			wantCaptured: "0.00",
+		},
+		{
+			name: "late failure after a capture is ignored",
+			prepare: func(t *testing.T, repo *memoryRepository, service *ServiceImpl, id uuid.UUID) {
+				setStatus(repo, id, entity.StatusSuccess, "")
+			},
+			event:        entity.EventPaymentFailed,
+			wantStatus:   entity.StatusSuccess,
+			wantCaptured: "240.00",
+		},
+		{
+			name: "capture after the hold expired is refunded",
+			prepare: func(t *testing.T, repo *memoryRepository, service *ServiceImpl, id uuid.UUID) {
+				setStatus(repo, id, entity.StatusFailed, "reservation hold expired before payment completed")
+			},
+			event:        entity.EventPaymentSucceeded,
+			wantStatus:   entity.StatusRefunded,
+			wantCaptured: "0.00",
+		},
+		{
+			name: "capture after the reservation was released is refunded",
+			prepare: func(t *testing.T, repo *memoryRepository, service *ServiceImpl, id uuid.UUID) {
+				repo.released[id] = true
+			},
+			event:        entity.EventPaymentSucceeded,
+			wantStatus:   entity.StatusRefunded,
+			wantCaptured: "0.00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := newMemoryRepository()
+			gateway := NewFakeGateway()
+			service := NewPaymentService(repo, gateway)
+			payment := authorizedPayment(t, repo, service, gateway)
+
+			// the capture that timed out did go through
+			if err := gateway.Capture(ctx, payment.GatewayReference, payment.Amount); err != nil {
+				t.Fatal(err)
+			}
+			tt.prepare(t, repo, service, payment.ID)
+
+			payload := &entity.WebhookPayload{ID: "evt_1", Type: tt.event}
+			payload.Data.Reference = payment.GatewayReference
+			if applied, err := service.HandleWebhookEvent(ctx, payload); err != nil || !applied {
+				t.Fatalf("HandleWebhookEvent = %v, %v, want applied", applied, err)
+			}
+
+			stored, _ := repo.GetByID(ctx, payment.ID)
+			if stored.PaymentStatus != tt.wantStatus {
+				t.Errorf("status = %s, want %s", stored.PaymentStatus, tt.wantStatus)
+			}
+			if got := gateway.Captured(payment.GatewayReference); got.Decimal() != tt.wantCaptured {
+				t.Errorf("captured at gateway = %s, want %s", got, tt.wantCaptured)
+			}
+		})
+	}
+}
+
+func TestHandleWebhookEventRetriesOrphanedRefund(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryRepository()
+	gateway := NewFakeGateway()
+	service := NewPaymentService(repo, gateway)
+	payment := authorizedPayment(t, repo, service, gateway)
+	if err := gateway.Capture(ctx, payment.GatewayReference, payment.Amount); err != nil {
+		t.Fatal(err)
+	}
+	repo.released[payment.ID] = true
+
+	payload := &entity.WebhookPayload{ID: "evt_1", Type: entity.EventPaymentSucceeded}
+	payload.Data.Reference = payment.GatewayReference
+
+	gateway.Script(FakeTimeout) // the refund does not go through the first time
+	if _, err := service.HandleWebhookEvent(ctx, payload); !errors.Is(err, ErrGatewayTimeout) {
+		t.Fatalf("first delivery error = %v, want %v", err, ErrGatewayTimeout)
+	}
+	stored, _ := repo.GetByID(ctx, payment.ID)
+	if stored.PaymentStatus != entity.StatusSuccess || stored.FailureReason != entity.OrphanedCaptureReason {
+		t.Fatalf("payment = %s %q, want %s %q", stored.PaymentStatus, stored.FailureReason, entity.StatusSuccess, entity.OrphanedCaptureReason)
+	}
+
+	applied, err := service.HandleWebhookEvent(ctx, payload)
+	if err != nil || applied {
+		t.Fatalf("redelivery = %v, %v, want a duplicate", applied, err)
+	}
+	stored, _ = repo.GetByID(ctx, payment.ID)
+	if stored.PaymentStatus != entity.StatusRefunded {
+		t.Errorf("status after redelivery = %s, want %s", stored.PaymentStatus, entity.StatusRefunded)
+	}
+	if got := gateway.Captured(payment.GatewayReference); !got.IsZero() {
+		t.Errorf("captured at gateway = %s, want nothing", got)
+	}
+}
\ No newline at end of file
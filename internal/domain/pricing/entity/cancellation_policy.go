@@ -0,0 +1,125 @@
+package entity
+
+import (
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/money"
+	"github.com/google/uuid"
+	"sort"
+	"time"
+)
+
+// FeeType : how a cancellation fee is charged
+type FeeType string
+
+const (
+	FeeNone       FeeType = "NONE"        // free cancellation
+	FeeFirstNight FeeType = "FIRST_NIGHT" // the price of the first night
+	FeePercent    FeeType = "PERCENT"     // a share of the total
+	FeeFull       FeeType = "FULL"        // non-refundable
+)
+
+// CancellationPolicy : tiers of cancellation fees depending on how long before check-in a reservation is cancelled.
+// A policy is attached to a room type or a rate plan; the rate plan's takes precedence
+type CancellationPolicy struct {
+	ID          uuid.UUID          `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Name        string             `gorm:"type:citext;not null;unique" json:"name" validate:"required,min=2,max=100"`
+	Description string             `gorm:"type:text" json:"description" validate:"max=500"`
+	Rules       []CancellationRule `gorm:"foreignKey:PolicyID;constraint:OnDelete:CASCADE" json:"rules" validate:"required,min=1,dive"`
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// CancellationRule : fee charged when cancelling at least HoursBeforeCheckIn hours before check-in.
+// Cancelling after check-in, or earlier than any rule allows, forfeits the full amount
+type CancellationRule struct {
+	ID                 uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	PolicyID           uuid.UUID `gorm:"type:uuid;not null;index" json:"policy_id"`
+	HoursBeforeCheckIn int       `gorm:"not null" json:"hours_before_check_in" validate:"min=0"`
+	FeeType            FeeType   `gorm:"type:varchar(20);not null" json:"fee_type" validate:"required,oneof=NONE FIRST_NIGHT PERCENT FULL"`
+	FeePercent         *float64  `gorm:"type:decimal(5,2)" json:"fee_percent,omitempty" validate:"omitempty,min=0,max=100"`
+}
+
+// DefaultCancellationPolicy applies when neither the rate plan nor the room type has a policy:
+// free until 48h before check-in, then the first night, non-refundable once the stay has started
+var DefaultCancellationPolicy = CancellationPolicy{
+	Name: "Standard",
+	Rules: []CancellationRule{
+		{HoursBeforeCheckIn: 48, FeeType: FeeNone},
+		{HoursBeforeCheckIn: 0, FeeType: FeeFirstNight},
+	},
+}
+
+// CancellationFee : breakdown of what cancelling a reservation costs
+type CancellationFee struct {
+	PolicyID           *uuid.UUID  `json:"policy_id,omitempty"`
+	PolicyName         string      `json:"policy_name"`
+	HoursBeforeCheckIn int         `json:"hours_before_check_in"`
+	FeeType            FeeType     `json:"fee_type"`
+	Total              money.Money `json:"total"`
+	Fee                money.Money `json:"fee"`
+	Refundable         money.Money `json:"refundable"`
+}
+
+// CancellationRequest : what the fee of a cancellation depends on
+type CancellationRequest struct {
+	RoomTypeID  uuid.UUID
+	RatePlanID  *uuid.UUID // rate plan the first night was sold under, if any
+	CheckIn     time.Time
+	CancelledAt time.Time
+	Total       money.Money
+	FirstNight  money.Money
+}
+
+// Assess works out the fee for cancelling at the requested time. The fee never exceeds the total
+func (p *CancellationPolicy) Assess(req CancellationRequest) *CancellationFee {
+	hours := int(req.CheckIn.Sub(req.CancelledAt).Hours())
+	if req.CancelledAt.After(req.CheckIn) {
+		hours = -1
+	}
+
+	fee := &CancellationFee{
+		PolicyName:         p.Name,
+		HoursBeforeCheckIn: max(hours, 0),
+		FeeType:            FeeFull,
+		Total:              req.Total,
+	}
+	if p.ID != uuid.Nil {
+		fee.PolicyID = &p.ID
+	}
+
+	// the rule with the longest notice the guest has satisfied applies
+	rules := append([]CancellationRule(nil), p.Rules...)
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].HoursBeforeCheckIn > rules[j].HoursBeforeCheckIn
+	})
+	for _, rule := range rules {
+		if hours >= rule.HoursBeforeCheckIn {
+			fee.FeeType = rule.FeeType
+			fee.Fee = rule.fee(req)
+			break
+		}
+	}
+	if fee.FeeType == FeeFull {
+		fee.Fee = req.Total
+	}
+
+	fee.Fee = money.Min(fee.Fee.WithCurrency(req.Total.Currency()), req.Total)
+	fee.Refundable = req.Total.Sub(fee.Fee)
+	return fee
+}
+
+func (r CancellationRule) fee(req CancellationRequest) money.Money {
+	switch r.FeeType {
+	case FeeFirstNight:
+		return req.FirstNight
+	case FeePercent:
+		if r.FeePercent == nil {
+			return req.Total
+		}
+		return req.Total.MulRate(*r.FeePercent)
+	case FeeFull:
+		return req.Total
+	default:
+		return money.Zero(req.Total.Currency())
+	}
+}
\ No newline at end of file
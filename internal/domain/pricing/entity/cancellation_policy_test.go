@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/money"
+)
+
+func TestCancellationPolicyAssess(t *testing.T) {
+	checkIn := time.Date(2025, time.June, 10, 14, 0, 0, 0, time.UTC)
+	percent := 50.0
+	tiered := &CancellationPolicy{
+		Name: "Tiered",
+		Rules: []CancellationRule{
+			{HoursBeforeCheckIn: 24, FeeType: FeePercent, FeePercent: &percent},
+			{HoursBeforeCheckIn: 168, FeeType: FeeNone},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		policy      *CancellationPolicy
+		cancelledAt time.Time
+		wantType    FeeType
+		wantFee     string
+	}{
+		{"default, a week ahead", &DefaultCancellationPolicy, checkIn.AddDate(0, 0, -7), FeeNone, "0.00"},
+		{"default, exactly 48h ahead", &DefaultCancellationPolicy, checkIn.Add(-48 * time.Hour), FeeNone, "0.00"},
+		{"default, a day ahead", &DefaultCancellationPolicy, checkIn.Add(-24 * time.Hour), FeeFirstNight, "120.00"},
+		{"default, after check-in", &DefaultCancellationPolicy, checkIn.Add(time.Hour), FeeFull, "300.00"},
+		{"tiered, unsorted rules, 10 days ahead", tiered, checkIn.AddDate(0, 0, -10), FeeNone, "0.00"},
+		{"tiered, two days ahead", tiered, checkIn.Add(-48 * time.Hour), FeePercent, "150.00"},
+		{"tiered, inside the last tier", tiered, checkIn.Add(-time.Hour), FeeFull, "300.00"},
+		{"no rules is non-refundable", &CancellationPolicy{Name: "Non-refundable"}, checkIn.AddDate(0, 1, 0), FeeFull, "300.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fee := tt.policy.Assess(CancellationRequest{
+				CheckIn:     checkIn,
+				CancelledAt: tt.cancelledAt,
+				Total:       money.MustParse("300.00", "USD"),
+				FirstNight:  money.MustParse("120.00", "USD"),
+			})
+			if fee.FeeType != tt.wantType {
+				t.Errorf("fee type = %s, want %s", fee.FeeType, tt.wantType)
+			}
+			if fee.Fee.Decimal() != tt.wantFee {
+				t.Errorf("fee = %s, want %s", fee.Fee.Decimal(), tt.wantFee)
+			}
+			if got := fee.Fee.Add(fee.Refundable); got.Decimal() != "300.00" {
+				t.Errorf("fee + refundable = %s, want the total", got)
+			}
+		})
+	}
+}
\ No newline at end of file
@@ -0,0 +1,15 @@
+package entity
+
+import "errors"
+
+var (
+	ErrInvalidStay       = errors.New("check-out date must be at least one night after check-in date")
+	ErrOccupancyExceeded = errors.New("number of guests exceeds the maximum occupancy of the room type")
+	ErrRoomTypeNotFound  = errors.New("room type not found")
+
+	ErrRatePlanNotFound     = errors.New("rate plan not found")
+	ErrInvalidRatePlanDates = errors.New("rate plan end date must not be before its start date")
+
+	ErrCancellationPolicyNotFound = errors.New("cancellation policy not found")
+	ErrInvalidCancellationRules   = errors.New("cancellation rules must have distinct notice periods and percentage rules need a fee_percent")
+)
\ No newline at end of file
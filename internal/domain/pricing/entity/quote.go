@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/money"
+	"github.com/google/uuid"
+	"time"
+)
+
+// DefaultCurrency is the currency every rate is quoted in
+const DefaultCurrency = money.DefaultCurrency
+
+// NightlyCharge is the price of a single night of a stay
+type NightlyCharge struct {
+	Date             time.Time   `json:"date"`
+	BaseRate         money.Money `json:"base_rate"`
+	ExtraGuests      int         `json:"extra_guests"`
+	ExtraGuestRate   money.Money `json:"extra_guest_rate"`
+	ExtraGuestCharge money.Money `json:"extra_guest_charge"`
+	Total            money.Money `json:"total"`
+
+	// rate plan that set BaseRate, nil when the room type's base price applies
+	RatePlanID   *uuid.UUID `json:"rate_plan_id,omitempty"`
+	RatePlanName string     `json:"rate_plan_name,omitempty"`
+}
+
+// Quote is the server-side price of a stay, broken down per night
+type Quote struct {
+	RoomTypeID uuid.UUID `json:"room_type_id"`
+	CheckIn    time.Time `json:"check_in_date"`
+	CheckOut   time.Time `json:"check_out_date"`
+	Guests     int       `json:"num_guests"`
+	Nights     int       `json:"nights"`
+	Currency   string    `json:"currency"`
+
+	Nightly []NightlyCharge `json:"nightly"`
+
+	BaseTotal       money.Money `json:"base_total"`
+	ExtraGuestTotal money.Money `json:"extra_guest_total"`
+	Total           money.Money `json:"total"`
+}
\ No newline at end of file
@@ -0,0 +1,99 @@
+package entity
+
+import (
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/money"
+	"github.com/google/uuid"
+	"time"
+)
+
+// WeekdayMask : set of days of the week a rate plan applies to.
+// Bit 0 is Sunday through bit 6 for Saturday (time.Weekday order); an empty mask means every day
+type WeekdayMask int
+
+const (
+	AllWeekdays WeekdayMask = 1<<7 - 1
+	Weekends    WeekdayMask = 1<<time.Friday | 1<<time.Saturday
+)
+
+// Includes reports whether the mask covers the given day
+func (m WeekdayMask) Includes(day time.Weekday) bool {
+	return m == 0 || m&(1<<day) != 0
+}
+
+// RatePlan : a dated, prioritised price rule (high season, weekends, holidays...) that overrides RoomType.BasePrice.
+// On any night the active plan with the highest priority that applies to the room type sets the rate
+type RatePlan struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Name        string    `gorm:"type:citext;not null;unique" json:"name" validate:"required,min=2,max=100"`
+	Description string    `gorm:"type:text" json:"description" validate:"max=500"`
+
+	// nights from StartDate through EndDate (both inclusive); nil leaves that side open
+	StartDate   *time.Time  `gorm:"type:date" json:"start_date"`
+	EndDate     *time.Time  `gorm:"type:date" json:"end_date"`
+	WeekdayMask WeekdayMask `gorm:"not null;default:0" json:"weekday_mask" validate:"min=0,max=127"`
+	Priority    int         `gorm:"not null;default:0;index" json:"priority"`
+	Active      bool        `gorm:"not null;default:true" json:"active"`
+
+	// PercentAdjustment applies the plan to every room type as a change to its base price (e.g. 20 = +20%).
+	// When nil the plan only applies to the room types listed in Rates
+	PercentAdjustment *float64       `gorm:"type:decimal(6,2)" json:"percent_adjustment,omitempty" validate:"omitempty,min=-100"`
+	Rates             []RatePlanRate `gorm:"foreignKey:RatePlanID;constraint:OnDelete:CASCADE" json:"rates" validate:"dive"`
+
+	// CancellationPolicyID overrides the room type's cancellation policy for stays sold under this plan
+	CancellationPolicyID *uuid.UUID `gorm:"type:uuid;index" json:"cancellation_policy_id,omitempty"`
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// RatePlanRate : fixed nightly rate a plan sets for one room type
+type RatePlanRate struct {
+	ID          uuid.UUID   `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	RatePlanID  uuid.UUID   `gorm:"type:uuid;not null;uniqueIndex:idx_rate_plan_room_type" json:"rate_plan_id"`
+	RoomTypeID  uuid.UUID   `gorm:"type:uuid;not null;uniqueIndex:idx_rate_plan_room_type" json:"room_type_id" validate:"required"`
+	NightlyRate money.Money `gorm:"type:decimal(10,2);not null" json:"nightly_rate" validate:"min=0"`
+}
+
+// CoversNight reports whether the plan is in force on the given night, regardless of room type
+func (p *RatePlan) CoversNight(night time.Time) bool {
+	if !p.Active || !p.WeekdayMask.Includes(night.Weekday()) {
+		return false
+	}
+	if p.StartDate != nil && night.Before(*p.StartDate) {
+		return false
+	}
+	if p.EndDate != nil && night.After(*p.EndDate) {
+		return false
+	}
+	return true
+}
+
+// NightlyRate returns the rate the plan sets for a room type with the given base price,
+// or false when the plan does not apply to that room type
+func (p *RatePlan) NightlyRate(roomTypeID uuid.UUID, basePrice money.Money) (money.Money, bool) {
+	for _, rate := range p.Rates {
+		if rate.RoomTypeID == roomTypeID {
+			return rate.NightlyRate.WithCurrency(basePrice.Currency()), true
+		}
+	}
+	if p.PercentAdjustment != nil {
+		return basePrice.MulRate(100 + *p.PercentAdjustment), true
+	}
+	return money.Money{}, false
+}
+
+// RatePlanRequest represents the data object used to create or replace a rate plan.
+// Dates are given as YYYY-MM-DD
+type RatePlanRequest struct {
+	Name              string         `json:"name" validate:"required,min=2,max=100"`
+	Description       string         `json:"description" validate:"max=500"`
+	StartDate         *string        `json:"start_date"`
+	EndDate           *string        `json:"end_date"`
+	WeekdayMask       WeekdayMask    `json:"weekday_mask" validate:"min=0,max=127"`
+	Priority          int            `json:"priority"`
+	Active            *bool          `json:"active"`
+	PercentAdjustment *float64       `json:"percent_adjustment" validate:"omitempty,min=-100"`
+	Rates             []RatePlanRate `json:"rates" validate:"dive"`
+
+	CancellationPolicyID *uuid.UUID `json:"cancellation_policy_id"`
+}
\ No newline at end of file
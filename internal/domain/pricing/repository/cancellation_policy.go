@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/infrastructure/database"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CancellationPolicyRepository : data persistence interface for cancellation policies
+type CancellationPolicyRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.CancellationPolicy, error)
+	GetAll(ctx context.Context) ([]*entity.CancellationPolicy, error)
+
+	Create(ctx context.Context, policy *entity.CancellationPolicy) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// CancellationPolicyRepositoryImpl implements the CancellationPolicyRepository interface
+type CancellationPolicyRepositoryImpl struct {
+	db *database.Service
+}
+
+func NewCancellationPolicyRepository(db *database.Service) *CancellationPolicyRepositoryImpl {
+	return &CancellationPolicyRepositoryImpl{
+		db: db,
+	}
+}
+
+func (repo *CancellationPolicyRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*entity.CancellationPolicy, error) {
+	var policy entity.CancellationPolicy
+	err := repo.db.WithContext(ctx).Preload("Rules").First(&policy, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrCancellationPolicyNotFound
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (repo *CancellationPolicyRepositoryImpl) GetAll(ctx context.Context) ([]*entity.CancellationPolicy, error) {
+	var policies []*entity.CancellationPolicy
+	err := repo.db.WithContext(ctx).Preload("Rules").Order("name").Find(&policies).Error
+	if err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func (repo *CancellationPolicyRepositoryImpl) Create(ctx context.Context, policy *entity.CancellationPolicy) error {
+	return repo.db.Transaction(ctx, func(tx *gorm.DB) error {
+		return tx.Create(policy).Error
+	})
+}
+
+// Delete removes a policy and detaches it from the room types & rate plans using it, which fall back to the default
+func (repo *CancellationPolicyRepositoryImpl) Delete(ctx context.Context, id uuid.UUID) error {
+	return repo.db.Transaction(ctx, func(tx *gorm.DB) error {
+		for _, table := range []string{"room_types", "rate_plans"} {
+			if err := tx.Table(table).Where("cancellation_policy_id = ?", id).
+				Update("cancellation_policy_id", nil).Error; err != nil {
+				return err
+			}
+		}
+
+		result := tx.Delete(&entity.CancellationPolicy{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return entity.ErrCancellationPolicyNotFound
+		}
+		return nil
+	})
+}
\ No newline at end of file
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/infrastructure/database"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// RatePlanRepository : data persistence interface for rate plans
+type RatePlanRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.RatePlan, error)
+	GetAll(ctx context.Context) ([]*entity.RatePlan, error)
+	GetActiveBetween(ctx context.Context, from, to time.Time) ([]*entity.RatePlan, error)
+
+	Create(ctx context.Context, plan *entity.RatePlan) error
+	Update(ctx context.Context, plan *entity.RatePlan) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// RatePlanRepositoryImpl implements the RatePlanRepository interface
+type RatePlanRepositoryImpl struct {
+	db *database.Service
+}
+
+func NewRatePlanRepository(db *database.Service) *RatePlanRepositoryImpl {
+	return &RatePlanRepositoryImpl{
+		db: db,
+	}
+}
+
+func (repo *RatePlanRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*entity.RatePlan, error) {
+	var plan entity.RatePlan
+	err := repo.db.WithContext(ctx).Preload("Rates").First(&plan, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrRatePlanNotFound
+		}
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// GetAll returns every rate plan, highest priority first
+func (repo *RatePlanRepositoryImpl) GetAll(ctx context.Context) ([]*entity.RatePlan, error) {
+	var plans []*entity.RatePlan
+	err := repo.db.WithContext(ctx).Preload("Rates").Order("priority DESC, created_at").Find(&plans).Error
+	if err != nil {
+		return nil, err
+	}
+	return plans, nil
+}
+
+// GetActiveBetween returns the active plans in force on at least one night of [from, to), highest priority first
+func (repo *RatePlanRepositoryImpl) GetActiveBetween(ctx context.Context, from, to time.Time) ([]*entity.RatePlan, error) {
+	var plans []*entity.RatePlan
+	err := repo.db.WithContext(ctx).
+		Preload("Rates").
+		Where("active = ?", true).
+		Where("start_date IS NULL OR start_date < ?", to).
+		Where("end_date IS NULL OR end_date >= ?", from).
+		Order("priority DESC, created_at").
+		Find(&plans).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active rate plans: %w", err)
+	}
+	return plans, nil
+}
+
+func (repo *RatePlanRepositoryImpl) Create(ctx context.Context, plan *entity.RatePlan) error {
+	return repo.db.Transaction(ctx, func(tx *gorm.DB) error {
+		return tx.Create(plan).Error
+	})
+}
+
+// Update saves the plan and replaces its room type rates
+func (repo *RatePlanRepositoryImpl) Update(ctx context.Context, plan *entity.RatePlan) error {
+	return repo.db.Transaction(ctx, func(tx *gorm.DB) error {
+		result := tx.Omit("Rates").Save(plan)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return entity.ErrRatePlanNotFound
+		}
+
+		if err := tx.Where("rate_plan_id = ?", plan.ID).Delete(&entity.RatePlanRate{}).Error; err != nil {
+			return err
+		}
+		for i := range plan.Rates {
+			plan.Rates[i].ID = uuid.Nil
+			plan.Rates[i].RatePlanID = plan.ID
+		}
+		if len(plan.Rates) > 0 {
+			return tx.Create(&plan.Rates).Error
+		}
+		return nil
+	})
+}
+
+func (repo *RatePlanRepositoryImpl) Delete(ctx context.Context, id uuid.UUID) error {
+	return repo.db.Transaction(ctx, func(tx *gorm.DB) error {
+		result := tx.Delete(&entity.RatePlan{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return entity.ErrRatePlanNotFound
+		}
+		return nil
+	})
+}
\ No newline at end of file
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/repository"
+	"github.com/google/uuid"
+)
+
+// CancellationPolicyService : management of cancellation policies
+type CancellationPolicyService interface {
+	CreateCancellationPolicy(ctx context.Context, policy *entity.CancellationPolicy) (*entity.CancellationPolicy, error)
+	GetCancellationPolicy(ctx context.Context, id uuid.UUID) (*entity.CancellationPolicy, error)
+	ListCancellationPolicies(ctx context.Context) ([]*entity.CancellationPolicy, error)
+	DeleteCancellationPolicy(ctx context.Context, id uuid.UUID) error
+}
+
+type CancellationPolicyServiceImpl struct {
+	repo repository.CancellationPolicyRepository
+}
+
+func NewCancellationPolicyService(policyRepo repository.CancellationPolicyRepository) *CancellationPolicyServiceImpl {
+	return &CancellationPolicyServiceImpl{
+		repo: policyRepo,
+	}
+}
+
+func (s *CancellationPolicyServiceImpl) CreateCancellationPolicy(ctx context.Context, policy *entity.CancellationPolicy) (*entity.CancellationPolicy, error) {
+	if err := validateCancellationPolicy(policy); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (s *CancellationPolicyServiceImpl) GetCancellationPolicy(ctx context.Context, id uuid.UUID) (*entity.CancellationPolicy, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *CancellationPolicyServiceImpl) ListCancellationPolicies(ctx context.Context) ([]*entity.CancellationPolicy, error) {
+	return s.repo.GetAll(ctx)
+}
+
+func (s *CancellationPolicyServiceImpl) DeleteCancellationPolicy(ctx context.Context, id uuid.UUID) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func validateCancellationPolicy(policy *entity.CancellationPolicy) error {
+	seen := make(map[int]bool)
+	for _, rule := range policy.Rules {
+		if seen[rule.HoursBeforeCheckIn] {
+			return entity.ErrInvalidCancellationRules
+		}
+		seen[rule.HoursBeforeCheckIn] = true
+
+		if rule.FeeType == entity.FeePercent && rule.FeePercent == nil {
+			return entity.ErrInvalidCancellationRules
+		}
+	}
+	return nil
+}
\ No newline at end of file
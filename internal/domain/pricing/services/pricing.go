@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/repository"
+	roomRepository "github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/repository"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/money"
+	"github.com/google/uuid"
+	"time"
+)
+
+// PricingService : computes the price of a stay on the server
+type PricingService interface {
+	Quote(ctx context.Context, roomTypeID uuid.UUID, checkIn, checkOut time.Time, guests int) (*entity.Quote, error)
+	CancellationFee(ctx context.Context, req entity.CancellationRequest) (*entity.CancellationFee, error)
+}
+
+type PricingServiceImpl struct {
+	roomTypeRepo roomRepository.RoomTypeRepository
+	ratePlanRepo repository.RatePlanRepository
+	policyRepo   repository.CancellationPolicyRepository
+}
+
+func NewPricingService(roomTypeRepo roomRepository.RoomTypeRepository, ratePlanRepo repository.RatePlanRepository, policyRepo repository.CancellationPolicyRepository) *PricingServiceImpl {
+	return &PricingServiceImpl{
+		roomTypeRepo: roomTypeRepo,
+		ratePlanRepo: ratePlanRepo,
+		policyRepo:   policyRepo,
+	}
+}
+
+// Quote prices every night in [checkIn, checkOut) for the given room type and number of guests
+func (p *PricingServiceImpl) Quote(ctx context.Context, roomTypeID uuid.UUID, checkIn, checkOut time.Time, guests int) (*entity.Quote, error) {
+	if !checkOut.After(checkIn) {
+		return nil, entity.ErrInvalidStay
+	}
+
+	roomType, err := p.roomTypeRepo.GetByID(ctx, roomTypeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room type: %w", err)
+	}
+	if roomType == nil {
+		return nil, entity.ErrRoomTypeNotFound
+	}
+	if guests > roomType.MaxOccupancy {
+		return nil, entity.ErrOccupancyExceeded
+	}
+
+	ratePlans, err := p.ratePlanRepo.GetActiveBetween(ctx, checkIn, checkOut)
+	if err != nil {
+		return nil, err
+	}
+
+	extraGuests := max(guests-roomType.IncludedOccupancy, 0)
+
+	currency := entity.DefaultCurrency
+	quote := &entity.Quote{
+		RoomTypeID:      roomTypeID,
+		CheckIn:         checkIn,
+		CheckOut:        checkOut,
+		Guests:          guests,
+		Currency:        currency,
+		BaseTotal:       money.Zero(currency),
+		ExtraGuestTotal: money.Zero(currency),
+	}
+
+	for night := checkIn; night.Before(checkOut); night = night.AddDate(0, 0, 1) {
+		charge := entity.NightlyCharge{
+			Date:             night,
+			BaseRate:         roomType.BasePrice,
+			ExtraGuests:      extraGuests,
+			ExtraGuestRate:   roomType.ExtraGuestPrice,
+			ExtraGuestCharge: roomType.ExtraGuestPrice.Mul(int64(extraGuests)),
+		}
+		if plan, rate, ok := effectiveRate(ratePlans, roomTypeID, roomType.BasePrice, night); ok {
+			charge.BaseRate = rate
+			charge.RatePlanID = &plan.ID
+			charge.RatePlanName = plan.Name
+		}
+		charge.Total = charge.BaseRate.Add(charge.ExtraGuestCharge)
+
+		quote.Nightly = append(quote.Nightly, charge)
+		quote.BaseTotal = quote.BaseTotal.Add(charge.BaseRate)
+		quote.ExtraGuestTotal = quote.ExtraGuestTotal.Add(charge.ExtraGuestCharge)
+	}
+
+	quote.Nights = len(quote.Nightly)
+	quote.Total = quote.BaseTotal.Add(quote.ExtraGuestTotal)
+
+	return quote, nil
+}
+
+// CancellationFee assesses a cancellation under the policy of the rate plan the stay was sold under,
+// else the room type's, else entity.DefaultCancellationPolicy
+func (p *PricingServiceImpl) CancellationFee(ctx context.Context, req entity.CancellationRequest) (*entity.CancellationFee, error) {
+	policyID, err := p.cancellationPolicyID(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &entity.DefaultCancellationPolicy
+	if policyID != nil {
+		policy, err = p.policyRepo.GetByID(ctx, *policyID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cancellation policy: %w", err)
+		}
+	}
+	return policy.Assess(req), nil
+}
+
+func (p *PricingServiceImpl) cancellationPolicyID(ctx context.Context, req entity.CancellationRequest) (*uuid.UUID, error) {
+	if req.RatePlanID != nil {
+		plan, err := p.ratePlanRepo.GetByID(ctx, *req.RatePlanID)
+		switch {
+		case err == nil && plan.CancellationPolicyID != nil:
+			return plan.CancellationPolicyID, nil
+		case err != nil && !errors.Is(err, entity.ErrRatePlanNotFound): // a deleted plan falls back to the room type
+			return nil, fmt.Errorf("failed to get rate plan: %w", err)
+		}
+	}
+
+	roomType, err := p.roomTypeRepo.GetByID(ctx, req.RoomTypeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room type: %w", err)
+	}
+	if roomType == nil {
+		return nil, entity.ErrRoomTypeNotFound
+	}
+	return roomType.CancellationPolicyID, nil
+}
+
+// effectiveRate picks the rate for a night from plans ordered by descending priority:
+// the first plan covering the night that applies to the room type wins
+func effectiveRate(plans []*entity.RatePlan, roomTypeID uuid.UUID, basePrice money.Money, night time.Time) (*entity.RatePlan, money.Money, bool) {
+	for _, plan := range plans {
+		if !plan.CoversNight(night) {
+			continue
+		}
+		if rate, ok := plan.NightlyRate(roomTypeID, basePrice); ok {
+			return plan, rate, true
+		}
+	}
+	return nil, money.Money{}, false
+}
\ No newline at end of file
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/repository"
+	roomEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/entity"
+	roomRepository "github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/repository"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/money"
+	"github.com/google/uuid"
+)
+
+// memoryRoomTypes : in-memory RoomTypeRepository. Methods the tests do not need panic through the nil embedded interface
+type memoryRoomTypes struct {
+	roomRepository.RoomTypeRepository
+	roomTypes map[uuid.UUID]*roomEntity.RoomType
+}
+
+// GetByID returns nil for an unknown room type, like the database repository
+func (m *memoryRoomTypes) GetByID(ctx context.Context, id uuid.UUID) (*roomEntity.RoomType, error) {
+	return m.roomTypes[id], nil
+}
+
+// memoryRatePlans : in-memory RatePlanRepository. Methods the tests do not need panic through the nil embedded interface
+type memoryRatePlans struct {
+	repository.RatePlanRepository
+	plans []*entity.RatePlan
+}
+
+// GetActiveBetween returns every active plan, highest priority first. Plans not in force on any night are left for Quote to skip
+func (m *memoryRatePlans) GetActiveBetween(ctx context.Context, from, to time.Time) ([]*entity.RatePlan, error) {
+	var plans []*entity.RatePlan
+	for _, plan := range m.plans {
+		if plan.Active {
+			plans = append(plans, plan)
+		}
+	}
+	sort.SliceStable(plans, func(i, j int) bool { return plans[i].Priority > plans[j].Priority })
+	return plans, nil
+}
+
+func usd(amount string) money.Money {
+	return money.MustParse(amount, "USD")
+}
+
+// day returns midnight UTC of a day of 2025
+func day(month time.Month, d int) time.Time {
+	return time.Date(2025, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestQuote(t *testing.T) {
+	// a double: 100.00 a night for 2, up to 4 guests at 15.00 each a night above that
+	double := &roomEntity.RoomType{ID: uuid.New(), BasePrice: usd("100.00"), MaxOccupancy: 4, IncludedOccupancy: 2, ExtraGuestPrice: usd("15.00")}
+	// a single whose price in cents would not add up in floating point
+	single := &roomEntity.RoomType{ID: uuid.New(), BasePrice: usd("33.33"), MaxOccupancy: 2, IncludedOccupancy: 1, ExtraGuestPrice: usd("0.10")}
+
+	tests := []struct {
+		name      string
+		roomType  uuid.UUID
+		checkIn   time.Time
+		checkOut  time.Time
+		guests    int
+		wantErr   error
+		wantNight []string // total of each night
+		wantExtra string
+		wantTotal string
+	}{
+		{
+			name: "base price every night", roomType: double.ID, checkIn: day(time.February, 3), checkOut: day(time.February, 5), guests: 2,
+			wantNight: []string{"100.00", "100.00"}, wantExtra: "0.00", wantTotal: "200.00",
+		},
+		{
+			name: "fewer guests pay the same", roomType: double.ID, checkIn: day(time.February, 3), checkOut: day(time.February, 4), guests: 1,
+			wantNight: []string{"100.00"}, wantExtra: "0.00", wantTotal: "100.00",
+		},
+		{
+			name: "extra guests are charged every night", roomType: double.ID, checkIn: day(time.February, 3), checkOut: day(time.February, 5), guests: 4,
+			wantNight: []string{"130.00", "130.00"}, wantExtra: "60.00", wantTotal: "260.00",
+		},
+		{
+			name: "cents add up exactly", roomType: single.ID, checkIn: day(time.February, 3), checkOut: day(time.February, 6), guests: 2,
+			wantNight: []string{"33.43", "33.43", "33.43"}, wantExtra: "0.30", wantTotal: "100.29",
+		},
+		{
+			name: "more guests than the room holds", roomType: double.ID, checkIn: day(time.February, 3), checkOut: day(time.February, 5), guests: 5,
+			wantErr: entity.ErrOccupancyExceeded,
+		},
+		{
+			name: "check-out on check-in day", roomType: double.ID, checkIn: day(time.February, 3), checkOut: day(time.February, 3), guests: 2,
+			wantErr: entity.ErrInvalidStay,
+		},
+		{
+			name: "unknown room type", roomType: uuid.New(), checkIn: day(time.February, 3), checkOut: day(time.February, 5), guests: 2,
+			wantErr: entity.ErrRoomTypeNotFound,
+		},
+	}
+
+	service := NewPricingService(
+		&memoryRoomTypes{roomTypes: map[uuid.UUID]*roomEntity.RoomType{double.ID: double, single.ID: single}},
+		&memoryRatePlans{}, nil,
+	)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := service.Quote(context.Background(), tt.roomType, tt.checkIn, tt.checkOut, tt.guests)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Quote error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if quote.Nights != len(tt.wantNight) || len(quote.Nightly) != len(tt.wantNight) {
+				t.Fatalf("quote has %d nights (%d charges), want %d", quote.Nights, len(quote.Nightly), len(tt.wantNight))
+			}
+			for i, night := range quote.Nightly {
+				if !night.Date.Equal(tt.checkIn.AddDate(0, 0, i)) || night.Total.Decimal() != tt.wantNight[i] || night.RatePlanID != nil {
+					t.Errorf("night %d = %s for %s (plan %v), want %s for %s at the base price",
+						i, night.Date.Format(time.DateOnly), night.Total.Decimal(), night.RatePlanID, tt.checkIn.AddDate(0, 0, i).Format(time.DateOnly), tt.wantNight[i])
+				}
+			}
+			if quote.ExtraGuestTotal.Decimal() != tt.wantExtra || quote.Total.Decimal() != tt.wantTotal || quote.Currency != "USD" {
+				t.Errorf("quote = %s extra of %s %s total, want %s of %s USD", quote.ExtraGuestTotal.Decimal(), quote.Total.Decimal(), quote.Currency, tt.wantExtra, tt.wantTotal)
+			}
+		})
+	}
+}
+
+// ratePlans returns a set of plans covering double and suite rooms, highest priority first:
+// a closed plan that must be ignored, a suite-only rate, a weekend +12.5% on every room type and a March season for doubles
+func ratePlans(double, suite uuid.UUID) (closed, suiteRate, weekend, season *entity.RatePlan) {
+	percent := func(p float64) *float64 { return &p }
+	march, endOfMarch := day(time.March, 1), day(time.March, 31)
+
+	closed = &entity.RatePlan{ID: uuid.New(), Name: "closed", Priority: 10, PercentAdjustment: percent(-50)}
+	suiteRate = &entity.RatePlan{ID: uuid.New(), Name: "suite", Priority: 8, Active: true,
+		Rates: []entity.RatePlanRate{{RoomTypeID: suite, NightlyRate: usd("300.00")}}}
+	weekend = &entity.RatePlan{ID: uuid.New(), Name: "weekend", Priority: 5, Active: true, WeekdayMask: entity.Weekends, PercentAdjustment: percent(12.5)}
+	season = &entity.RatePlan{ID: uuid.New(), Name: "march", Priority: 1, Active: true, StartDate: &march, EndDate: &endOfMarch,
+		Rates: []entity.RatePlanRate{{RoomTypeID: double, NightlyRate: usd("120.00")}}}
+	return closed, suiteRate, weekend, season
+}
+
+func TestEffectiveRate(t *testing.T) {
+	double, suite, single := uuid.New(), uuid.New(), uuid.New()
+	closed, suiteRate, weekend, season := ratePlans(double, suite)
+	plans := []*entity.RatePlan{closed, suiteRate, weekend, season}
+
+	tests := []struct {
+		name      string
+		roomType  uuid.UUID
+		basePrice string
+		night     time.Time
+		wantPlan  *entity.RatePlan // nil when the base price applies
+		wantRate  string
+	}{
+		{name: "season rate on a weekday", roomType: double, basePrice: "100.00", night: day(time.March, 10), wantPlan: season, wantRate: "120.00"},
+		{name: "weekend outranks the season", roomType: double, basePrice: "100.00", night: day(time.March, 14), wantPlan: weekend, wantRate: "112.50"},
+		{name: "season ends on its end date", roomType: double, basePrice: "100.00", night: day(time.March, 31), wantPlan: season, wantRate: "120.00"},
+		{name: "no plan after the season", roomType: double, basePrice: "100.00", night: day(time.April, 1)},
+		{name: "plan for one room type", roomType: suite, basePrice: "200.00", night: day(time.March, 10), wantPlan: suiteRate, wantRate: "300.00"},
+		{name: "room type without a plan on a weekday", roomType: single, basePrice: "80.00", night: day(time.March, 10)},
+		{name: "percent rounds to the cent", roomType: single, basePrice: "99.99", night: day(time.February, 14), wantPlan: weekend, wantRate: "112.49"},
+		{name: "half a cent rounds up", roomType: single, basePrice: "10.04", night: day(time.February, 14), wantPlan: weekend, wantRate: "11.30"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, rate, ok := effectiveRate(plans, tt.roomType, usd(tt.basePrice), tt.night)
+			if ok != (tt.wantPlan != nil) || plan != tt.wantPlan {
+				t.Fatalf("effectiveRate picked %v (ok %v), want %v", plan, ok, tt.wantPlan)
+			}
+			if ok && rate.Decimal() != tt.wantRate {
+				t.Errorf("rate = %s, want %s", rate.Decimal(), tt.wantRate)
+			}
+		})
+	}
+}
+
+func TestQuoteWithRatePlans(t *testing.T) {
+	double := &roomEntity.RoomType{ID: uuid.New(), BasePrice: usd("100.00"), MaxOccupancy: 4, IncludedOccupancy: 2, ExtraGuestPrice: usd("15.00")}
+	closed, suiteRate, weekend, season := ratePlans(double.ID, uuid.New())
+	service := NewPricingService(
+		&memoryRoomTypes{roomTypes: map[uuid.UUID]*roomEntity.RoomType{double.ID: double}},
+		&memoryRatePlans{plans: []*entity.RatePlan{season, weekend, closed, suiteRate}}, nil,
+	)
+
+	// Thursday through Saturday night for 3: the season then the weekend, plus an extra guest every night
+	quote, err := service.Quote(context.Background(), double.ID, day(time.March, 13), day(time.March, 16), 3)
+	if err != nil {
+		t.Fatalf("Quote: %v", err)
+	}
+
+	want := []struct {
+		plan  *entity.RatePlan
+		base  string
+		total string
+	}{{season, "120.00", "135.00"}, {weekend, "112.50", "127.50"}, {weekend, "112.50", "127.50"}}
+	if len(quote.Nightly) != len(want) {
+		t.Fatalf("quote has %d nights, want %d", len(quote.Nightly), len(want))
+	}
+	for i, night := range quote.Nightly {
+		if night.RatePlanID == nil || *night.RatePlanID != want[i].plan.ID || night.RatePlanName != want[i].plan.Name ||
+			night.BaseRate.Decimal() != want[i].base || night.Total.Decimal() != want[i].total {
+			t.Errorf("night %d = %s + extra = %s under %q, want %s = %s under %q",
+				i, night.BaseRate.Decimal(), night.Total.Decimal(), night.RatePlanName, want[i].base, want[i].total, want[i].plan.Name)
+		}
+	}
+	if quote.BaseTotal.Decimal() != "345.00" || quote.ExtraGuestTotal.Decimal() != "45.00" || quote.Total.Decimal() != "390.00" {
+		t.Errorf("quote = %s + %s = %s, want 345.00 + 45.00 = 390.00", quote.BaseTotal.Decimal(), quote.ExtraGuestTotal.Decimal(), quote.Total.Decimal())
+	}
+}
\ No newline at end of file
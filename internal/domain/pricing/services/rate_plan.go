@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/repository"
+	"github.com/google/uuid"
+	"time"
+)
+
+// RatePlanService : management of seasonal & day-of-week rate plans
+type RatePlanService interface {
+	CreateRatePlan(ctx context.Context, plan *entity.RatePlan) (*entity.RatePlan, error)
+	GetRatePlan(ctx context.Context, id uuid.UUID) (*entity.RatePlan, error)
+	ListRatePlans(ctx context.Context) ([]*entity.RatePlan, error)
+	UpdateRatePlan(ctx context.Context, id uuid.UUID, plan *entity.RatePlan) (*entity.RatePlan, error)
+	DeleteRatePlan(ctx context.Context, id uuid.UUID) error
+}
+
+type RatePlanServiceImpl struct {
+	repo repository.RatePlanRepository
+}
+
+func NewRatePlanService(ratePlanRepo repository.RatePlanRepository) *RatePlanServiceImpl {
+	return &RatePlanServiceImpl{
+		repo: ratePlanRepo,
+	}
+}
+
+func (s *RatePlanServiceImpl) CreateRatePlan(ctx context.Context, plan *entity.RatePlan) (*entity.RatePlan, error) {
+	if err := validateRatePlan(plan); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (s *RatePlanServiceImpl) GetRatePlan(ctx context.Context, id uuid.UUID) (*entity.RatePlan, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *RatePlanServiceImpl) ListRatePlans(ctx context.Context) ([]*entity.RatePlan, error) {
+	return s.repo.GetAll(ctx)
+}
+
+// UpdateRatePlan replaces an existing plan, including its room type rates
+func (s *RatePlanServiceImpl) UpdateRatePlan(ctx context.Context, id uuid.UUID, plan *entity.RatePlan) (*entity.RatePlan, error) {
+	existing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRatePlan(plan); err != nil {
+		return nil, err
+	}
+
+	plan.ID = existing.ID
+	plan.CreatedAt = existing.CreatedAt
+	plan.UpdatedAt = time.Now()
+
+	if err := s.repo.Update(ctx, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (s *RatePlanServiceImpl) DeleteRatePlan(ctx context.Context, id uuid.UUID) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func validateRatePlan(plan *entity.RatePlan) error {
+	if plan.StartDate != nil && plan.EndDate != nil && plan.EndDate.Before(*plan.StartDate) {
+		return entity.ErrInvalidRatePlanDates
+	}
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"github.com/gatimugabriel/hotel-reservation-system/internal/constants"
+	"github.com/google/uuid"
+	"strings"
+)
+
+// Actor : the authenticated user a reservation operation is carried out for
+type Actor struct {
+	UserID uuid.UUID
+	Role   constants.Role
+}
+
+func NewActor(userID uuid.UUID, role string) Actor {
+	return Actor{UserID: userID, Role: constants.Role(strings.ToUpper(role))}
+}
+
+// IsStaff reports whether the actor works for the hotel and may act on every guest's reservations
+func (a Actor) IsStaff() bool {
+	switch a.Role {
+	case constants.STAFF, constants.MANAGER, constants.ADMIN, constants.PROPERTYOWNER:
+		return true
+	default:
+		return false
+	}
+}
+
+// CanAccess reports whether the actor may read or change the reservation: guests only their own, staff any
+func (a Actor) CanAccess(reservation *Reservation) bool {
+	return a.IsStaff() || (a.UserID != uuid.Nil && reservation.UserID == a.UserID)
+}
+
+// CanAccessBooking reports whether the actor may read or change the booking, under the same rules as CanAccess
+func (a Actor) CanAccessBooking(booking *Booking) bool {
+	return a.IsStaff() || (a.UserID != uuid.Nil && booking.UserID == a.UserID)
+}
+
+// CanAccessWaitlistEntry reports whether the actor may read or leave the waitlist entry, under the same rules as CanAccess
+func (a Actor) CanAccessWaitlistEntry(entry *WaitlistEntry) bool {
+	return a.IsStaff() || (a.UserID != uuid.Nil && entry.UserID == a.UserID)
+}
\ No newline at end of file
@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/gatimugabriel/hotel-reservation-system/internal/constants"
+	"github.com/google/uuid"
+)
+
+func TestActorCanAccess(t *testing.T) {
+	owner := uuid.New()
+	reservation := &Reservation{ID: uuid.New(), UserID: owner}
+
+	tests := []struct {
+		name  string
+		actor Actor
+		want  bool
+	}{
+		{"owning guest", Actor{UserID: owner, Role: constants.GUEST}, true},
+		{"other guest", Actor{UserID: uuid.New(), Role: constants.GUEST}, false},
+		{"staff", Actor{UserID: uuid.New(), Role: constants.STAFF}, true},
+		{"manager", Actor{UserID: uuid.New(), Role: constants.MANAGER}, true},
+		{"admin", Actor{UserID: uuid.New(), Role: constants.ADMIN}, true},
+		{"property owner", Actor{UserID: uuid.New(), Role: constants.PROPERTYOWNER}, true},
+		{"unknown role", Actor{UserID: uuid.New(), Role: "VISITOR"}, false},
+		{"anonymous", Actor{}, false},
+		{"lower case role from token", NewActor(uuid.New(), "manager"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.actor.CanAccess(reservation); got != tt.want {
+				t.Errorf("CanAccess() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
\ No newline at end of file
@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+)
+
+// Assignment : a room allocated to a reservation booked by room type
+type Assignment struct {
+	ReservationID uuid.UUID `json:"reservation_id"`
+	RoomID        uuid.UUID `json:"room_id"`
+	RoomNumber    int       `json:"room_number"`
+}
+
+// Unallocated : a reservation no room could be allocated to, and why
+type Unallocated struct {
+	ReservationID uuid.UUID `json:"reservation_id"`
+	Reason        string    `json:"reason"`
+}
+
+// AllocationResult : the outcome of allocating rooms to the arrivals of one day
+type AllocationResult struct {
+	Date       string        `json:"date"`
+	Assigned   []Assignment  `json:"assigned"`
+	Unassigned []Unallocated `json:"unassigned"`
+}
+
+// NewAssignment records a room allocated by the actor
+func NewAssignment(actor Actor, reservationID, roomID uuid.UUID) (*Change, error) {
+	return NewChange(actor, reservationID, ActionRoomAssigned, map[string]FieldChange{
+		"room_id": {From: nil, To: roomID},
+	})
+}
\ No newline at end of file
@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"encoding/json"
+	paymentEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/money"
+	"github.com/google/uuid"
+	"time"
+)
+
+// Booking : several reservations made together by one guest. They are paid with one payment
+// and share the booking's confirmation code, but each can still be cancelled on its own
+type Booking struct {
+	ID   uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Code string    `gorm:"type:varchar(16);not null;uniqueIndex" json:"code"`
+
+	UserID uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+
+	PaymentID uuid.UUID             `gorm:"type:uuid;index" json:"payment_id"`
+	Payment   paymentEntity.Payment `gorm:"foreignKey:PaymentID;references:ID" json:"payment,omitempty"`
+
+	TotalPrice   money.Money    `gorm:"type:decimal(10,2);not null" json:"total_price"`
+	Reservations []*Reservation `gorm:"foreignKey:BookingID" json:"reservations"`
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// BookingCancellation : the outcome of cancelling every room of a booking that could still be cancelled
+type BookingCancellation struct {
+	Booking       *Booking        `json:"booking"`
+	Cancellations []*Cancellation `json:"cancellations"`
+	Refunded      money.Money     `json:"refunded"`
+	RefundDue     money.Money     `json:"refund_due"`
+}
+
+// CreateBookingRequest represents the data object used to book several rooms with one payment
+type CreateBookingRequest struct {
+	Rooms          []StayRequest        `json:"rooms" validate:"required,min=1,max=10,dive"`
+	PaymentMethod  paymentEntity.Method `json:"payment_method,omitempty" validate:"required,oneof=CREDIT_CARD DEBIT_CARD PAYPAL BANK_TRANSFER CRYPTO CASH"`
+	PaymentDetails json.RawMessage      `json:"payment_details,omitempty" validate:"required"`
+}
\ No newline at end of file
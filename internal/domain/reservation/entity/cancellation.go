@@ -0,0 +1,48 @@
+package entity
+
+import (
+	pricingEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/money"
+	"github.com/google/uuid"
+)
+
+// Cancellation : outcome of cancelling a reservation, with the fee breakdown and what was refunded.
+// RefundDue is what the guest is owed but could not be refunded, left for staff to settle
+type Cancellation struct {
+	Reservation *Reservation                   `json:"reservation"`
+	Fee         *pricingEntity.CancellationFee `json:"fee"`
+	Refunded    money.Money                    `json:"refunded"`
+	RefundDue   money.Money                    `json:"refund_due"`
+}
+
+// FirstNight returns the price of the first night of the stay and the rate plan it was sold under.
+// Reservations priced before line items were kept are split evenly across their nights
+func (r *Reservation) FirstNight() (money.Money, *uuid.UUID) {
+	if len(r.LineItems) == 0 {
+		nights := int64(r.CheckOutDate.Sub(r.CheckInDate).Hours() / 24)
+		if nights < 1 {
+			return r.TotalPrice, nil
+		}
+		return money.New(r.TotalPrice.Minor()/nights, r.TotalPrice.Currency()), nil
+	}
+
+	first := r.LineItems[0].NightOf
+	for _, item := range r.LineItems {
+		if item.NightOf.Before(first) {
+			first = item.NightOf
+		}
+	}
+
+	total := money.Zero(r.TotalPrice.Currency())
+	var ratePlanID *uuid.UUID
+	for _, item := range r.LineItems {
+		if !item.NightOf.Equal(first) {
+			continue
+		}
+		total = total.Add(item.Amount)
+		if item.ChargeType == ChargeBaseRate {
+			ratePlanID = item.RatePlanID
+		}
+	}
+	return total, ratePlanID
+}
\ No newline at end of file
@@ -0,0 +1,88 @@
+package entity
+
+import (
+	"encoding/json"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/constants"
+	"github.com/google/uuid"
+	"time"
+)
+
+// ChangeAction : kind of change recorded in a reservation's history
+type ChangeAction string
+
+const (
+	ActionModified      ChangeAction = "MODIFIED"
+	ActionStatusChanged ChangeAction = "STATUS_CHANGED"
+	ActionRoomAssigned  ChangeAction = "ROOM_ASSIGNED"
+	ActionRefundFailed  ChangeAction = "REFUND_FAILED"
+)
+
+// FieldChange : previous & new value of a changed field
+type FieldChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// Change : an entry of a reservation's history, recording what was changed and by whom
+type Change struct {
+	ID            uuid.UUID       `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ReservationID uuid.UUID       `gorm:"type:uuid;not null;index" json:"reservation_id"`
+	Action        ChangeAction    `gorm:"type:varchar(30);not null" json:"action"`
+	ChangedBy     uuid.UUID       `gorm:"type:uuid;not null;index" json:"changed_by"`
+	ChangedByRole constants.Role  `gorm:"type:varchar(20);not null" json:"changed_by_role"`
+	Fields        json.RawMessage `gorm:"type:jsonb" json:"fields"`
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+func (Change) TableName() string {
+	return "reservation_changes"
+}
+
+// NewChange records an action taken by the actor on a reservation, with the fields it changed
+func NewChange(actor Actor, reservationID uuid.UUID, action ChangeAction, fields map[string]FieldChange) (*Change, error) {
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	return &Change{
+		ReservationID: reservationID,
+		Action:        action,
+		ChangedBy:     actor.UserID,
+		ChangedByRole: actor.Role,
+		Fields:        encoded,
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
+// Diff lists the modifiable fields whose value differs in the updated reservation
+func (r *Reservation) Diff(updated *Reservation) map[string]FieldChange {
+	fields := make(map[string]FieldChange)
+	if !r.CheckInDate.Equal(updated.CheckInDate) {
+		fields["check_in_date"] = FieldChange{r.CheckInDate.Format(time.DateOnly), updated.CheckInDate.Format(time.DateOnly)}
+	}
+	if !r.CheckOutDate.Equal(updated.CheckOutDate) {
+		fields["check_out_date"] = FieldChange{r.CheckOutDate.Format(time.DateOnly), updated.CheckOutDate.Format(time.DateOnly)}
+	}
+	if r.RoomTypeID != updated.RoomTypeID {
+		fields["room_type_id"] = FieldChange{r.RoomTypeID, updated.RoomTypeID}
+	}
+	if !sameRoom(r.RoomID, updated.RoomID) {
+		fields["room_id"] = FieldChange{r.RoomID, updated.RoomID}
+	}
+	if r.NumGuests != updated.NumGuests {
+		fields["num_guests"] = FieldChange{r.NumGuests, updated.NumGuests}
+	}
+	if r.TotalPrice != updated.TotalPrice {
+		fields["total_price"] = FieldChange{r.TotalPrice, updated.TotalPrice}
+	}
+	return fields
+}
+
+// sameRoom compares two optional room IDs by value
+func sameRoom(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
\ No newline at end of file
@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/money"
+	"github.com/google/uuid"
+)
+
+func TestReservationDiff(t *testing.T) {
+	roomType, otherType := uuid.New(), uuid.New()
+	room, otherRoom := uuid.New(), uuid.New()
+	original := func() *Reservation {
+		return &Reservation{
+			CheckInDate:  date(10),
+			CheckOutDate: date(13),
+			RoomTypeID:   roomType,
+			RoomID:       &room,
+			NumGuests:    2,
+			TotalPrice:   money.MustParse("300.00", "USD"),
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(r *Reservation)
+		want   map[string]FieldChange
+	}{
+		{"nothing changed", func(r *Reservation) {}, map[string]FieldChange{}},
+		{"same room, another pointer", func(r *Reservation) { same := room; r.RoomID = &same }, map[string]FieldChange{}},
+		{
+			"dates moved",
+			func(r *Reservation) { r.CheckInDate, r.CheckOutDate = date(11), date(15) },
+			map[string]FieldChange{
+				"check_in_date":  {"2025-03-10", "2025-03-11"},
+				"check_out_date": {"2025-03-13", "2025-03-15"},
+			},
+		},
+		{
+			"moved to a room of another type & re-priced",
+			func(r *Reservation) {
+				r.RoomTypeID, r.RoomID, r.TotalPrice = otherType, &otherRoom, money.MustParse("450.00", "USD")
+			},
+			map[string]FieldChange{
+				"room_type_id": {roomType, otherType},
+				"room_id":      {&room, &otherRoom},
+				"total_price":  {money.MustParse("300.00", "USD"), money.MustParse("450.00", "USD")},
+			},
+		},
+		{"room given up", func(r *Reservation) { r.RoomID = nil }, map[string]FieldChange{"room_id": {&room, (*uuid.UUID)(nil)}}},
+		{"guest added", func(r *Reservation) { r.NumGuests = 3 }, map[string]FieldChange{"num_guests": {2, 3}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := original()
+			tt.modify(updated)
+			if got := original().Diff(updated); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
\ No newline at end of file
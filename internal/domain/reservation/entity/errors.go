@@ -0,0 +1,34 @@
+package entity
+
+import "errors"
+
+var (
+	// ErrRoomUnavailable is returned when the requested room cannot be booked for the given dates,
+	// either because another stay overlaps them or because the room is out of service
+	ErrRoomUnavailable = errors.New("room is not available for the selected dates")
+
+	// ErrReservationNotFound is also returned for reservations the caller may not see, so their existence is not disclosed
+	ErrReservationNotFound = errors.New("reservation not found")
+	// ErrBookingNotFound is, like ErrReservationNotFound, also returned for bookings the caller may not see
+	ErrBookingNotFound = errors.New("booking not found")
+
+	ErrAlreadyCancelled = errors.New("reservation already cancelled")
+	// ErrNotCancellable is returned for stays that have started or are over
+	ErrNotCancellable = errors.New("reservation can no longer be cancelled")
+	ErrNotModifiable  = errors.New("only pending or confirmed reservations can be modified")
+	ErrNoChanges      = errors.New("no changes requested")
+
+	// ErrOutsideStay is returned when checking in before the check-in date or on/after the check-out date
+	ErrOutsideStay = errors.New("check-in is only possible between the check-in and check-out dates")
+	// ErrBeforeCheckIn is returned when marking a guest as a no-show before they were due to arrive
+	ErrBeforeCheckIn = errors.New("reservation has not reached its check-in date")
+	// ErrRoomNotAssigned is returned when checking in a room type booking that has no room allocated yet
+	ErrRoomNotAssigned = errors.New("a room must be assigned to the reservation before check-in")
+	ErrAlreadyAssigned = errors.New("reservation already has a room assigned")
+
+	// ErrRoomsAvailable is returned when joining the waitlist of a room type that can still be booked for the dates
+	ErrRoomsAvailable         = errors.New("rooms of that type are available for those dates, book one instead")
+	ErrAlreadyWaitlisted      = errors.New("already on the waitlist for that room type and dates")
+	ErrWaitlistEntryNotFound  = errors.New("waitlist entry not found")
+	ErrWaitlistEntryNotActive = errors.New("waitlist entry is no longer active")
+)
\ No newline at end of file
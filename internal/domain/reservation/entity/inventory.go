@@ -0,0 +1,33 @@
+package entity
+
+import (
+	roomEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/entity"
+	"github.com/google/uuid"
+	"time"
+)
+
+// FreeRooms returns how many rooms of one type are free on every night of the stay [checkIn, checkOut).
+// inService is the number of rooms of the type that can be booked, windows the maintenance windows of those rooms
+// and reservations the type's reservations around the stay, assigned to a room or not. The busiest night decides
+func FreeRooms(inService int, windows []*roomEntity.MaintenanceWindow, reservations []*Reservation, checkIn, checkOut time.Time) int {
+	free := inService
+	for night := checkIn; night.Before(checkOut); night = night.AddDate(0, 0, 1) {
+		// a room in two windows on the same night is only closed once
+		closed := make(map[uuid.UUID]bool)
+		for _, window := range windows {
+			if window.Overlaps(night, night.AddDate(0, 0, 1)) {
+				closed[window.RoomID] = true
+			}
+		}
+		occupied := 0
+		for _, reservation := range reservations {
+			if reservation.Overlaps(night, night.AddDate(0, 0, 1)) {
+				occupied++
+			}
+		}
+		if inService-len(closed)-occupied < free {
+			free = inService - len(closed) - occupied
+		}
+	}
+	return max(free, 0)
+}
\ No newline at end of file
@@ -0,0 +1,92 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	roomEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/entity"
+	"github.com/google/uuid"
+)
+
+func TestFreeRooms(t *testing.T) {
+	stay := func(checkIn, checkOut int, status Status) *Reservation {
+		return &Reservation{CheckInDate: date(checkIn), CheckOutDate: date(checkOut), Status: status}
+	}
+	offer := func(checkIn, checkOut int, expiresIn time.Duration) *Reservation {
+		expiresAt := time.Now().Add(expiresIn)
+		entry := &WaitlistEntry{CheckInDate: date(checkIn), CheckOutDate: date(checkOut), Status: WaitlistOffered, OfferExpiresAt: &expiresAt}
+		return entry.Hold()
+	}
+
+	tests := []struct {
+		name         string
+		inService    int
+		reservations []*Reservation
+		want         int
+	}{
+		{"nothing booked", 3, nil, 3},
+		{"one stay over the whole request", 3, []*Reservation{stay(10, 14, StatusConfirmed)}, 2},
+		// two stays that never share a night only take one room
+		{"back to back stays", 2, []*Reservation{stay(9, 12, StatusConfirmed), stay(12, 15, StatusPending)}, 1},
+		{"busiest night decides", 3, []*Reservation{
+			stay(10, 11, StatusConfirmed),
+			stay(12, 13, StatusConfirmed),
+			stay(12, 14, StatusInProgress),
+		}, 1},
+		{"sold out on one night", 2, []*Reservation{stay(11, 12, StatusConfirmed), stay(8, 13, StatusPending)}, 0},
+		{"cancelled stays free their room", 1, []*Reservation{stay(10, 14, StatusCancelled)}, 1},
+		{"stays outside the request", 1, []*Reservation{stay(1, 10, StatusConfirmed), stay(14, 20, StatusConfirmed)}, 1},
+		{"overbooked never goes negative", 1, []*Reservation{stay(10, 14, StatusConfirmed), stay(10, 14, StatusConfirmed)}, 0},
+		{"no rooms in service", 0, nil, 0},
+		{"waitlist offer holds a room", 2, []*Reservation{offer(10, 14, time.Hour)}, 1},
+		{"expired waitlist offer holds nothing", 2, []*Reservation{offer(10, 14, -time.Minute)}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// request: 10th -> 14th
+			if got := FreeRooms(tt.inService, nil, tt.reservations, date(10), date(14)); got != tt.want {
+				t.Errorf("FreeRooms = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	expiredAt := time.Now().Add(-time.Minute)
+	expiredHold := stay(10, 14, StatusPending)
+	expiredHold.HoldExpiresAt = &expiredAt
+	if got := FreeRooms(1, nil, []*Reservation{expiredHold}, date(10), date(14)); got != 1 {
+		t.Errorf("FreeRooms with an expired hold = %d, want 1", got)
+	}
+}
+
+func TestFreeRoomsAroundMaintenance(t *testing.T) {
+	roomA, roomB := uuid.New(), uuid.New()
+	window := func(room uuid.UUID, start, end int) *roomEntity.MaintenanceWindow {
+		return &roomEntity.MaintenanceWindow{RoomID: room, StartDate: date(start), EndDate: date(end)}
+	}
+	stay := &Reservation{CheckInDate: date(10), CheckOutDate: date(12), Status: StatusConfirmed}
+
+	tests := []struct {
+		name         string
+		windows      []*roomEntity.MaintenanceWindow
+		reservations []*Reservation
+		want         int
+	}{
+		{"window over the whole request", []*roomEntity.MaintenanceWindow{window(roomA, 1, 20)}, nil, 2},
+		{"window on one night", []*roomEntity.MaintenanceWindow{window(roomA, 13, 14)}, nil, 2},
+		{"windows ending as the request starts or starting as it ends", []*roomEntity.MaintenanceWindow{window(roomA, 5, 10), window(roomB, 14, 16)}, nil, 3},
+		{"two windows of one room", []*roomEntity.MaintenanceWindow{window(roomA, 10, 12), window(roomA, 11, 13)}, nil, 2},
+		// the stay and the window fall on different nights, so they can share a room
+		{"stay and window on different nights", []*roomEntity.MaintenanceWindow{window(roomA, 12, 14)}, []*Reservation{stay}, 2},
+		{"stay on a night a room is closed", []*roomEntity.MaintenanceWindow{window(roomA, 11, 12)}, []*Reservation{stay}, 1},
+		{"every room closed", []*roomEntity.MaintenanceWindow{window(roomA, 10, 14), window(roomB, 10, 14)}, []*Reservation{stay}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// three rooms in service, request: 10th -> 14th
+			if got := FreeRooms(3, tt.windows, tt.reservations, date(10), date(14)); got != tt.want {
+				t.Errorf("FreeRooms = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
\ No newline at end of file
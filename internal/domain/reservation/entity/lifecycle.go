@@ -0,0 +1,61 @@
+package entity
+
+import (
+	"fmt"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/constants"
+	"github.com/google/uuid"
+)
+
+// transitions : the reservation state machine. Each state lists the states it may move to;
+// CANCELLED, COMPLETED, NO_SHOW and EXPIRED are final
+var transitions = map[Status][]Status{
+	StatusPending:    {StatusConfirmed, StatusInProgress, StatusCancelled, StatusNoShow, StatusExpired},
+	StatusConfirmed:  {StatusInProgress, StatusCancelled, StatusNoShow},
+	StatusInProgress: {StatusCompleted},
+}
+
+// CanTransitionTo reports whether a reservation may move from this state to next
+func (s Status) CanTransitionTo(next Status) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinal reports whether no further transition is possible from this state
+func (s Status) IsFinal() bool {
+	return len(transitions[s]) == 0
+}
+
+// InvalidTransitionError is returned when a reservation cannot move between two states
+type InvalidTransitionError struct {
+	From Status
+	To   Status
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("reservation cannot move from %s to %s", e.From, e.To)
+}
+
+// CheckTransition returns an *InvalidTransitionError unless the reservation may move to next
+func (r *Reservation) CheckTransition(next Status) error {
+	if !r.Status.CanTransitionTo(next) {
+		return &InvalidTransitionError{From: r.Status, To: next}
+	}
+	return nil
+}
+
+// SystemRole identifies transitions made by the system itself, e.g. on a payment result
+const SystemRole constants.Role = "SYSTEM"
+
+// SystemActor : the actor recorded for automated changes
+var SystemActor = Actor{UserID: uuid.Nil, Role: SystemRole}
+
+// NewTransition records a status change made by the actor
+func NewTransition(actor Actor, reservationID uuid.UUID, from, to Status) (*Change, error) {
+	return NewChange(actor, reservationID, ActionStatusChanged, map[string]FieldChange{
+		"status": {From: from, To: to},
+	})
+}
\ No newline at end of file
@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStatusTransitions(t *testing.T) {
+	tests := []struct {
+		from Status
+		to   Status
+		want bool
+	}{
+		{StatusPending, StatusConfirmed, true},
+		{StatusPending, StatusInProgress, true}, // pay at the desk on arrival
+		{StatusPending, StatusCancelled, true},
+		{StatusPending, StatusExpired, true},
+		{StatusConfirmed, StatusInProgress, true},
+		{StatusConfirmed, StatusCancelled, true},
+		{StatusConfirmed, StatusNoShow, true},
+		{StatusInProgress, StatusCompleted, true},
+
+		{StatusConfirmed, StatusCompleted, false}, // must check in first
+		{StatusConfirmed, StatusPending, false},
+		{StatusInProgress, StatusCancelled, false},
+		{StatusInProgress, StatusNoShow, false},
+		{StatusCompleted, StatusInProgress, false},
+		{StatusCancelled, StatusConfirmed, false},
+		{StatusNoShow, StatusInProgress, false},
+		{StatusConfirmed, StatusExpired, false}, // paid reservations never expire
+		{StatusExpired, StatusConfirmed, false},
+		{StatusConfirmed, StatusConfirmed, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+				t.Errorf("CanTransitionTo = %v, want %v", got, tt.want)
+			}
+
+			err := (&Reservation{Status: tt.from}).CheckTransition(tt.to)
+			var transitionErr *InvalidTransitionError
+			if tt.want && err != nil {
+				t.Errorf("CheckTransition unexpected error: %v", err)
+			}
+			if !tt.want && (!errors.As(err, &transitionErr) || transitionErr.From != tt.from || transitionErr.To != tt.to) {
+				t.Errorf("CheckTransition error = %v, want an InvalidTransitionError", err)
+			}
+		})
+	}
+
+	for _, status := range []Status{StatusCompleted, StatusCancelled, StatusNoShow, StatusExpired} {
+		if !status.IsFinal() {
+			t.Errorf("%s.IsFinal() = false, want true", status)
+		}
+	}
+}
\ No newline at end of file
@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/money"
+	"github.com/google/uuid"
+	"time"
+)
+
+// ChargeType : kind of charge a line item represents
+type ChargeType string
+
+const (
+	ChargeBaseRate   ChargeType = "BASE_RATE"
+	ChargeExtraGuest ChargeType = "EXTRA_GUEST"
+)
+
+// LineItem : a single priced component of a reservation, stored as quoted at booking time
+type LineItem struct {
+	ID            uuid.UUID   `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ReservationID uuid.UUID   `gorm:"type:uuid;not null;index" json:"reservation_id"`
+	NightOf       time.Time   `gorm:"type:date;not null" json:"night_of"`
+	ChargeType    ChargeType  `gorm:"type:varchar(20);not null" json:"charge_type"`
+	Quantity      int         `gorm:"not null;default:1" json:"quantity"`
+	UnitPrice     money.Money `gorm:"type:decimal(10,2);not null" json:"unit_price"`
+	Amount        money.Money `gorm:"type:decimal(10,2);not null" json:"amount"`
+	RatePlanID    *uuid.UUID  `gorm:"type:uuid;index" json:"rate_plan_id,omitempty"`
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+func (LineItem) TableName() string {
+	return "reservation_line_items"
+}
\ No newline at end of file
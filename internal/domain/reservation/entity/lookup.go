@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/money"
+	"strings"
+	"time"
+)
+
+// LookupReservationRequest represents the data object used to find a reservation without logging in:
+// its confirmation code and the guest's last name or email
+type LookupReservationRequest struct {
+	Code     string `json:"code" validate:"required,min=8,max=16"`
+	LastName string `json:"last_name" validate:"required_without=Email,max=100"`
+	Email    string `json:"email" validate:"required_without=LastName,omitempty,email"`
+}
+
+// ReservationSummary : what anyone knowing a reservation's code and the guest's last name or email may see of it.
+// It leaves out the guest's contact details and everything about the payment but its total
+type ReservationSummary struct {
+	Code         string      `json:"code"`
+	Status       Status      `json:"status"`
+	GuestName    string      `json:"guest_name"`
+	CheckInDate  time.Time   `json:"check_in_date"`
+	CheckOutDate time.Time   `json:"check_out_date"`
+	NumGuests    int         `json:"num_guests"`
+	RoomType     string      `json:"room_type"`
+	RoomNumber   *int        `json:"room_number,omitempty"`
+	TotalPrice   money.Money `json:"total_price"`
+}
+
+// Summary returns the limited view of the reservation. The guest's last name is shortened to its initial
+func (r *Reservation) Summary() *ReservationSummary {
+	summary := &ReservationSummary{
+		Code:         r.Code,
+		Status:       r.Status,
+		GuestName:    r.User.FirstName,
+		CheckInDate:  r.CheckInDate,
+		CheckOutDate: r.CheckOutDate,
+		NumGuests:    r.NumGuests,
+		RoomType:     r.RoomTypeName(),
+		TotalPrice:   r.TotalPrice,
+	}
+	if lastName := strings.TrimSpace(r.User.LastName); lastName != "" {
+		summary.GuestName += " " + string([]rune(lastName)[:1]) + "."
+	}
+	if r.Room != nil {
+		summary.RoomNumber = &r.Room.RoomNumber
+	}
+	return summary
+}
+
+// BelongsTo reports whether the reservation's guest has the given last name or email, ignoring case.
+// Empty values never match
+func (r *Reservation) BelongsTo(lastName, email string) bool {
+	lastName, email = strings.TrimSpace(lastName), strings.TrimSpace(email)
+	return (lastName != "" && strings.EqualFold(lastName, strings.TrimSpace(r.User.LastName))) ||
+		(email != "" && strings.EqualFold(email, r.User.Email))
+}
\ No newline at end of file
@@ -5,6 +5,7 @@ import (
 	paymentEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment/entity"
 	roomEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/entity"
 	userEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/money"
 	"github.com/google/uuid"
 	"time"
 )
@@ -18,20 +19,68 @@ const (
 	StatusInProgress Status = "IN_PROGRESS"
 	StatusCompleted  Status = "COMPLETED"
 	StatusCancelled  Status = "CANCELLED"
+	StatusNoShow     Status = "NO_SHOW"
+	StatusExpired    Status = "EXPIRED" // an unpaid hold that ran out
 )
 
+// BlockingStatuses are the states in which a reservation occupies its room.
+// The reservations_no_overlap constraint is declared over the same list.
+var BlockingStatuses = []Status{StatusPending, StatusConfirmed, StatusInProgress, StatusCompleted}
+
+// OverlapCondition is the SQL form of Reservation.Overlaps.
+// Arguments, in order: requested check-out, requested check-in, BlockingStatuses, the current time
+const OverlapCondition = "check_in_date < ? AND check_out_date > ? AND status IN ? AND NOT (" + ExpiredHoldCondition + ")"
+
+// ExpiredHoldCondition matches PENDING reservations whose hold ran out, whether or not the sweeper has expired them yet.
+// A hold whose capture is outstanding keeps its room until the authorization is voided.
+// Argument: the current time
+const ExpiredHoldCondition = LapsedHoldCondition + " AND NOT " + CaptureOutstandingCondition
+
+// LapsedHoldCondition matches PENDING reservations whose hold time has passed, capture outstanding or not.
+// Argument: the current time
+const LapsedHoldCondition = "status = 'PENDING' AND hold_expires_at IS NOT NULL AND hold_expires_at <= ?"
+
+// CaptureOutstandingCondition is the SQL form of Reservation.CaptureOutstanding
+const CaptureOutstandingCondition = "EXISTS (SELECT 1 FROM payments WHERE payments.id = reservations.payment_id AND payments.payment_status = 'AUTHORIZED')"
+
+// HoldExpiredReason is recorded on the payments of expired holds
+const HoldExpiredReason = "reservation hold expired before payment"
+
+// IsBlocking reports whether a reservation in this state occupies its room
+func (s Status) IsBlocking() bool {
+	for _, status := range BlockingStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// CodeLength : number of Crockford base32 characters in the confirmation codes of reservations and bookings
+const CodeLength = 8
+
 // Reservation represents the booking of a room
 type Reservation struct {
-	ID             uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
-	CheckInDate    time.Time `gorm:"not null;uniqueIndex:idx_room_dates,priority:2" json:"check_in_date" validate:"required,gtefield=CreatedAt"`
-	CheckOutDate   time.Time `gorm:"not null;uniqueIndex:idx_room_dates,priority:3" json:"check_out_date" validate:"required,gtefield=CheckInDate"`
-	NumGuests      int       `gorm:"not null;default:1" json:"num_guests" validate:"required,min=1,max=10"`
-	SpecialRequest string    `gorm:"type:text" json:"special_request" validate:"max=500"`
-	TotalPrice     float64   `gorm:"type:decimal(10,2);not null" json:"total_price" validate:"required,min=0"`
-	Status         Status    `gorm:"type:varchar(20);not null;default:PENDING" json:"status" validate:"required,oneof=PENDING CONFIRMED CANCELLED COMPLETED"`
+	ID             uuid.UUID   `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Code           string      `gorm:"type:varchar(16);not null;default:'';index:idx_reservations_code,unique,where:code <> ''" json:"code"`
+	CheckInDate    time.Time   `gorm:"not null" json:"check_in_date" validate:"required,gtefield=CreatedAt"`
+	CheckOutDate   time.Time   `gorm:"not null" json:"check_out_date" validate:"required,gtefield=CheckInDate"`
+	NumGuests      int         `gorm:"not null;default:1" json:"num_guests" validate:"required,min=1,max=10"`
+	SpecialRequest string      `gorm:"type:text" json:"special_request" validate:"max=500"`
+	TotalPrice     money.Money `gorm:"type:decimal(10,2);not null" json:"total_price" validate:"min=0"`
+	Status         Status      `gorm:"type:varchar(20);not null;default:PENDING" json:"status" validate:"required,oneof=PENDING CONFIRMED IN_PROGRESS COMPLETED CANCELLED NO_SHOW EXPIRED"`
+
+	// HoldExpiresAt : until when a PENDING reservation keeps its room while waiting for payment. Nil holds never expire
+	HoldExpiresAt *time.Time `gorm:"index" json:"hold_expires_at,omitempty"`
 
-	RoomID uuid.UUID       `gorm:"type:uuid;not null;uniqueIndex:idx_room_dates,priority:1" json:"room_id" validate:"required"`
-	Room   roomEntity.Room `gorm:"foreignKey:RoomID;references:ID" json:"room"`
+	// RoomTypeID : the type of room booked. RoomID stays nil until a physical room of that type is allocated
+	RoomTypeID uuid.UUID            `gorm:"type:uuid;index" json:"room_type_id" validate:"required"`
+	RoomType   *roomEntity.RoomType `gorm:"foreignKey:RoomTypeID;references:ID" json:"room_type,omitempty"`
+
+	RoomID *uuid.UUID       `gorm:"type:uuid;index" json:"room_id"`
+	Room   *roomEntity.Room `gorm:"foreignKey:RoomID;references:ID" json:"room,omitempty"`
+
+	Preferences RoomPreferences `gorm:"embedded" json:"preferences"`
 
 	UserID uuid.UUID       `gorm:"type:uuid;not null;index" json:"user_id" validate:"required"`
 	User   userEntity.User `gorm:"foreignKey:UserID;references:ID" json:"user"`
@@ -39,20 +88,97 @@ type Reservation struct {
 	PaymentID uuid.UUID             `gorm:"type:uuid;index" json:"payment_id"`
 	Payment   paymentEntity.Payment `gorm:"foreignKey:PaymentID;references:ID" json:"payment,omitempty"`
 
+	// BookingID : the booking the reservation was made in together with others, which then share its payment
+	BookingID *uuid.UUID `gorm:"type:uuid;index" json:"booking_id,omitempty"`
+
+	LineItems []LineItem `gorm:"foreignKey:ReservationID;constraint:OnDelete:CASCADE" json:"line_items,omitempty"`
+
 	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
 }
 
+// RoomPreferences : what the guest would like from the room allocated to them. They are honoured when a room allows
+type RoomPreferences struct {
+	Floor      *int `gorm:"column:preferred_floor" json:"floor,omitempty" validate:"omitempty,min=0"`
+	Accessible bool `gorm:"column:accessible_room;not null;default:false" json:"accessible"`
+}
+
+// Overlaps reports whether this reservation occupies its room during any part of the requested stay.
+// Stays are half-open [check_in, check_out) ranges, so a check-out and a check-in on the same day do not clash
+func (r *Reservation) Overlaps(checkIn, checkOut time.Time) bool {
+	return r.Status.IsBlocking() && !r.HoldExpired(time.Now()) && r.CheckInDate.Before(checkOut) && r.CheckOutDate.After(checkIn)
+}
+
+// IsAssigned reports whether a physical room has been allocated to this reservation
+func (r *Reservation) IsAssigned() bool {
+	return r.RoomID != nil
+}
+
+// RoomTypeName returns the name of the booked room type, if it was loaded
+func (r *Reservation) RoomTypeName() string {
+	if r.RoomType == nil {
+		return ""
+	}
+	return r.RoomType.Name
+}
+
+// HoldExpired reports whether this reservation is an unpaid hold that ran out at or before now.
+// A hold whose capture is outstanding has not, so the payment must be loaded
+func (r *Reservation) HoldExpired(now time.Time) bool {
+	return r.Status == StatusPending && r.HoldExpiresAt != nil && !r.HoldExpiresAt.After(now) && !r.CaptureOutstanding()
+}
+
+// CaptureOutstanding reports whether the reservation's payment was authorized but its capture, which timed out, may still go through
+func (r *Reservation) CaptureOutstanding() bool {
+	return r.Payment.PaymentStatus == paymentEntity.StatusAuthorized
+}
+
+// ModifyReservationRequest represents the data object used to change an existing reservation.
+// Omitted fields are left as they are
+type ModifyReservationRequest struct {
+	CheckInDate  *string `json:"check_in_date"`
+	CheckoutDate *string `json:"check_out_date"`
+	RoomTypeID   *string `json:"room_type_id"`
+	RoomID       *string `json:"room_id"`
+	RoomNumber   *int    `json:"room_number"`
+	NumGuests    *int    `json:"num_guests" validate:"omitempty,min=1,max=10"`
+}
+
+// Modification : the parsed changes of a ModifyReservationRequest
+type Modification struct {
+	CheckIn    *time.Time
+	CheckOut   *time.Time
+	RoomTypeID *uuid.UUID // moves the stay to a free room of that type, unless RoomID is given
+	RoomID     *uuid.UUID
+	NumGuests  *int
+}
+
+// ModificationResult : a modified reservation, with the price difference and how it was settled
+type ModificationResult struct {
+	Reservation     *Reservation              `json:"reservation"`
+	PriceDifference money.Money               `json:"price_difference"`
+	Adjustment      *paymentEntity.Adjustment `json:"adjustment,omitempty"`
+}
+
 // CreateReservationRequest represents the data object used when user need to create a reservation
 type CreateReservationRequest struct {
+	StayRequest
+	PaymentMethod  paymentEntity.Method `json:"payment_method,omitempty" validate:"required,oneof=CREDIT_CARD DEBIT_CARD PAYPAL BANK_TRANSFER CRYPTO CASH"`
+	PaymentDetails json.RawMessage      `json:"payment_details,omitempty" validate:"required"`
+}
+
+// StayRequest : the room and dates of one reservation.
+// Either a specific room (room_id or room_number) or a room type (room_type_id) is booked;
+// a room type booking gets its room allocated later
+type StayRequest struct {
 	RoomID     *string `json:"room_id"`
 	RoomNumber *int    `json:"room_number"`
+	RoomTypeID *string `json:"room_type_id"`
 
 	CheckInDate  string `json:"check_in_date" validate:"required"`
 	CheckoutDate string `json:"check_out_date" validate:"required"`
 
-	NumGuests      int                  `json:"num_guests" validate:"required"`
-	SpecialRequest *string              `json:"special_request"`
-	PaymentMethod  paymentEntity.Method `json:"payment_method,omitempty" validate:"required,oneof=CREDIT_CARD DEBIT_CARD PAYPAL BANK_TRANSFER CRYPTO CASH"`
-	PaymentDetails json.RawMessage      `json:"payment_details,omitempty" validate:"required"`
+	NumGuests      int              `json:"num_guests" validate:"required"`
+	SpecialRequest *string          `json:"special_request"`
+	Preferences    *RoomPreferences `json:"preferences"`
 }
\ No newline at end of file
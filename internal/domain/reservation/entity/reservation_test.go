@@ -0,0 +1,105 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	paymentEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment/entity"
+)
+
+func date(day int) time.Time {
+	return time.Date(2025, time.March, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestReservationOverlaps(t *testing.T) {
+	// existing stay: 10th -> 14th (nights of the 10th, 11th, 12th & 13th)
+	existing := func(status Status) *Reservation {
+		return &Reservation{CheckInDate: date(10), CheckOutDate: date(14), Status: status}
+	}
+
+	tests := []struct {
+		name     string
+		status   Status
+		checkIn  time.Time
+		checkOut time.Time
+		want     bool
+	}{
+		{"identical stay", StatusConfirmed, date(10), date(14), true},
+		{"request enclosed by existing", StatusConfirmed, date(11), date(13), true},
+		{"request encloses existing", StatusConfirmed, date(8), date(16), true},
+		{"request overlaps start", StatusConfirmed, date(8), date(11), true},
+		{"request overlaps end", StatusConfirmed, date(13), date(16), true},
+		{"single night at start", StatusConfirmed, date(10), date(11), true},
+		{"single night at end", StatusConfirmed, date(13), date(14), true},
+		{"check-in on existing check-out day", StatusConfirmed, date(14), date(16), false},
+		{"check-out on existing check-in day", StatusConfirmed, date(8), date(10), false},
+		{"entirely before", StatusConfirmed, date(1), date(5), false},
+		{"entirely after", StatusConfirmed, date(20), date(25), false},
+		{"pending reservation blocks", StatusPending, date(11), date(12), true},
+		{"in progress reservation blocks", StatusInProgress, date(11), date(12), true},
+		{"completed reservation blocks", StatusCompleted, date(11), date(12), true},
+		{"cancelled reservation never blocks", StatusCancelled, date(10), date(14), false},
+		{"no-show reservation never blocks", StatusNoShow, date(10), date(14), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := existing(tt.status).Overlaps(tt.checkIn, tt.checkOut); got != tt.want {
+				t.Errorf("Overlaps(%s, %s) = %v, want %v",
+					tt.checkIn.Format("2006-01-02"), tt.checkOut.Format("2006-01-02"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpiredHoldDoesNotBlock(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(15*time.Minute)
+
+	tests := []struct {
+		name          string
+		status        Status
+		holdExpiresAt *time.Time
+		payment       paymentEntity.Status
+		want          bool
+	}{
+		{"hold still running", StatusPending, &future, paymentEntity.StatusPending, true},
+		{"hold ran out", StatusPending, &past, paymentEntity.StatusPending, false},
+		{"hold ran out with its capture outstanding", StatusPending, &past, paymentEntity.StatusAuthorized, true},
+		{"pending without a hold", StatusPending, nil, paymentEntity.StatusPending, true},
+		{"confirmed keeps its room after the hold", StatusConfirmed, &past, paymentEntity.StatusSuccess, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reservation := &Reservation{CheckInDate: date(10), CheckOutDate: date(14), Status: tt.status, HoldExpiresAt: tt.holdExpiresAt}
+			reservation.Payment.PaymentStatus = tt.payment
+			if got := reservation.Overlaps(date(11), date(12)); got != tt.want {
+				t.Errorf("Overlaps = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusIsBlocking(t *testing.T) {
+	tests := []struct {
+		status Status
+		want   bool
+	}{
+		{StatusPending, true},
+		{StatusConfirmed, true},
+		{StatusInProgress, true},
+		{StatusCompleted, true},
+		{StatusCancelled, false},
+		{StatusNoShow, false},
+		{StatusExpired, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			if got := tt.status.IsBlocking(); got != tt.want {
+				t.Errorf("%s.IsBlocking() = %v, want %v", tt.status, got, tt.want)
+			}
+		})
+	}
+}
\ No newline at end of file
@@ -0,0 +1,79 @@
+package entity
+
+import (
+	roomEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/entity"
+	userEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/entity"
+	"github.com/google/uuid"
+	"time"
+)
+
+// WaitlistStatus : state of a waitlist entry
+type WaitlistStatus string
+
+const (
+	// WaitlistWaiting : the guest waits for a room of the type to free up for their dates
+	WaitlistWaiting WaitlistStatus = "WAITING"
+	// WaitlistOffered : a freed room is held for the guest until the offer expires
+	WaitlistOffered WaitlistStatus = "OFFERED"
+	// WaitlistBooked : the guest booked the room they were offered
+	WaitlistBooked WaitlistStatus = "BOOKED"
+	// WaitlistExpired : the offer ran out, or the dates passed, before the guest booked
+	WaitlistExpired WaitlistStatus = "EXPIRED"
+	// WaitlistLeft : the guest left the waitlist
+	WaitlistLeft WaitlistStatus = "LEFT"
+)
+
+// ActiveWaitlistStatuses are the statuses of entries still waiting for, or holding, a room
+var ActiveWaitlistStatuses = []WaitlistStatus{WaitlistWaiting, WaitlistOffered}
+
+// WaitlistEntry : a guest waiting for a room of a sold-out type for their dates.
+// Guests are offered freed rooms in the order they joined
+type WaitlistEntry struct {
+	ID           uuid.UUID      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	CheckInDate  time.Time      `gorm:"not null" json:"check_in_date"`
+	CheckOutDate time.Time      `gorm:"not null" json:"check_out_date"`
+	NumGuests    int            `gorm:"not null;default:1" json:"num_guests"`
+	Status       WaitlistStatus `gorm:"type:varchar(20);not null;default:WAITING;index" json:"status"`
+
+	// OfferExpiresAt : until when the room offered to the guest is held for them
+	OfferExpiresAt *time.Time `json:"offer_expires_at,omitempty"`
+
+	RoomTypeID uuid.UUID            `gorm:"type:uuid;not null;index" json:"room_type_id"`
+	RoomType   *roomEntity.RoomType `gorm:"foreignKey:RoomTypeID;references:ID" json:"room_type,omitempty"`
+
+	UserID uuid.UUID        `gorm:"type:uuid;not null;index" json:"user_id"`
+	User   *userEntity.User `gorm:"foreignKey:UserID;references:ID" json:"-"`
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+func (WaitlistEntry) TableName() string {
+	return "waitlist_entries"
+}
+
+// IsActive reports whether the entry still waits for, or holds, a room
+func (w *WaitlistEntry) IsActive() bool {
+	return w.Status == WaitlistWaiting || w.Status == WaitlistOffered
+}
+
+// Hold returns the offered room as a reservation held until the offer expires, so inventory counts it like one
+func (w *WaitlistEntry) Hold() *Reservation {
+	return &Reservation{
+		ID:            w.ID,
+		Status:        StatusPending,
+		RoomTypeID:    w.RoomTypeID,
+		UserID:        w.UserID,
+		CheckInDate:   w.CheckInDate,
+		CheckOutDate:  w.CheckOutDate,
+		HoldExpiresAt: w.OfferExpiresAt,
+	}
+}
+
+// JoinWaitlistRequest represents the data object used to join the waitlist of a sold-out room type
+type JoinWaitlistRequest struct {
+	RoomTypeID   string `json:"room_type_id" validate:"required,uuid"`
+	CheckInDate  string `json:"check_in_date" validate:"required"`
+	CheckoutDate string `json:"check_out_date" validate:"required"`
+	NumGuests    int    `json:"num_guests" validate:"required,min=1,max=10"`
+}
\ No newline at end of file
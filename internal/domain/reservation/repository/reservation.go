@@ -4,10 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	paymentEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment/entity"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/entity"
+	roomEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/entity"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/infrastructure/database"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"time"
 )
 
@@ -17,10 +21,26 @@ type ReservationRepository interface {
 	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.Reservation, error)
 	GetByRoomID(ctx context.Context, roomID uuid.UUID) ([]*entity.Reservation, error)
 	GetByDateRange(ctx context.Context, checkIn, checkOut time.Time) ([]*entity.Reservation, error)
+	GetByCode(ctx context.Context, code string) (*entity.Reservation, error)
 
 	Create(ctx context.Context, reservation *entity.Reservation) error
+	Book(ctx context.Context, reservation *entity.Reservation) error
 	Update(ctx context.Context, reservation *entity.Reservation) (*entity.Reservation, error)
+	Transition(ctx context.Context, id uuid.UUID, from, to entity.Status, change *entity.Change) error
+	Modify(ctx context.Context, reservation *entity.Reservation, change *entity.Change) error
 	Delete(ctx context.Context, id uuid.UUID) error
+
+	GetChanges(ctx context.Context, reservationID uuid.UUID) ([]*entity.Change, error)
+	AddChange(ctx context.Context, change *entity.Change) error
+	ExpireHolds(ctx context.Context, now time.Time) (int, error)
+	GetLapsedAuthorizedHolds(ctx context.Context, now time.Time) ([]*entity.Reservation, error)
+	CountFreeRooms(ctx context.Context, roomTypeID uuid.UUID, checkIn, checkOut time.Time, exclude, guest uuid.UUID) (int, error)
+
+	GetUnassignedArrivals(ctx context.Context, date time.Time) ([]*entity.Reservation, error)
+	AssignRoom(ctx context.Context, reservationID, roomID uuid.UUID, change *entity.Change) error
+
+	CreateBooking(ctx context.Context, booking *entity.Booking) error
+	GetBooking(ctx context.Context, id uuid.UUID) (*entity.Booking, error)
 }
 
 type ReservationRepositoryImpl struct {
@@ -31,16 +51,34 @@ func NewReservationRepository(db *database.Service) *ReservationRepositoryImpl {
 	return &ReservationRepositoryImpl{db: db}
 }
 
+// withoutPassword preloads a reservation's guest without their password hash
+func withoutPassword(db *gorm.DB) *gorm.DB {
+	return db.Omit("password_hash")
+}
+
+// orderedLineItems preloads a reservation's line items night by night
+func orderedLineItems(db *gorm.DB) *gorm.DB {
+	return db.Order("night_of, charge_type")
+}
+
+// orderedAdjustments preloads the adjustments of a reservation's payment oldest first
+func orderedAdjustments(db *gorm.DB) *gorm.DB {
+	return db.Order("created_at, id")
+}
+
 func (repo *ReservationRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*entity.Reservation, error) {
 	var reservation entity.Reservation
 	if err := repo.db.WithContext(ctx).
-		Preload("User").
+		Preload("User", withoutPassword).
 		Preload("Payment").
+		Preload("Payment.Adjustments", orderedAdjustments).
+		Preload("RoomType").
 		Preload("Room").
 		Preload("Room.RoomType").
+		Preload("LineItems", orderedLineItems).
 		Where("id = ?", id).First(&reservation).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("reservation not found")
+			return nil, entity.ErrReservationNotFound
 		}
 		return nil, err
 	}
@@ -50,10 +88,13 @@ func (repo *ReservationRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID
 func (repo *ReservationRepositoryImpl) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.Reservation, error) {
 	var reservations []*entity.Reservation
 	if err := repo.db.WithContext(ctx).
-		Preload("User").
+		Preload("User", withoutPassword).
 		Preload("Payment").
+		Preload("Payment.Adjustments", orderedAdjustments).
+		Preload("RoomType").
 		Preload("Room").
 		Preload("Room.RoomType").
+		Preload("LineItems", orderedLineItems).
 		Where("user_id = ?", userID).Find(&reservations).Error; err != nil {
 		return nil, fmt.Errorf("failed to get reservations by user ID: %w", err)
 	}
@@ -63,21 +104,42 @@ func (repo *ReservationRepositoryImpl) GetByUserID(ctx context.Context, userID u
 func (repo *ReservationRepositoryImpl) GetByRoomID(ctx context.Context, roomID uuid.UUID) ([]*entity.Reservation, error) {
 	var reservations []*entity.Reservation
 	if err := repo.db.WithContext(ctx).
-		Preload("User").
+		Preload("User", withoutPassword).
 		Preload("Payment").
+		Preload("Payment.Adjustments", orderedAdjustments).
+		Preload("RoomType").
 		Preload("Room").
 		Preload("Room.RoomType").
+		Preload("LineItems", orderedLineItems).
 		Where("room_id = ?", roomID).Find(&reservations).Error; err != nil {
 		return nil, fmt.Errorf("failed to get reservations by room ID: %w", err)
 	}
 	return reservations, nil
 }
 
+// GetByCode returns the reservation with the given confirmation code, with its guest, room type and room
+func (repo *ReservationRepositoryImpl) GetByCode(ctx context.Context, code string) (*entity.Reservation, error) {
+	var reservation entity.Reservation
+	if err := repo.db.WithContext(ctx).
+		Preload("User", withoutPassword).
+		Preload("RoomType").
+		Preload("Room").
+		Where("code = ?", code).First(&reservation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrReservationNotFound
+		}
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// GetByDateRange returns the reservations that occupy a room during any part of [checkIn, checkOut)
 func (repo *ReservationRepositoryImpl) GetByDateRange(ctx context.Context, checkIn, checkOut time.Time) ([]*entity.Reservation, error) {
 	var reservations []*entity.Reservation
 
 	err := repo.db.WithContext(ctx).
-		Where("(check_in_date BETWEEN ? AND ?) OR (check_out_date BETWEEN ? AND ?)", checkIn, checkOut, checkIn, checkOut).
+		Preload("Payment").
+		Where(entity.OverlapCondition, checkOut, checkIn, entity.BlockingStatuses, time.Now()).
 		Find(&reservations).Error
 
 	if err != nil {
@@ -94,6 +156,241 @@ func (repo *ReservationRepositoryImpl) Create(ctx context.Context, reservation *
 	return nil
 }
 
+// Book stores a reservation together with its payment in one transaction, once reserveInventory has confirmed
+// a room of the booked type is still free
+func (repo *ReservationRepositoryImpl) Book(ctx context.Context, reservation *entity.Reservation) error {
+	return repo.db.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := reserveInventory(tx, reservation); err != nil {
+			return err
+		}
+
+		if err := tx.Create(&reservation.Payment).Error; err != nil {
+			return fmt.Errorf("failed to create payment: %w", err)
+		}
+		reservation.PaymentID = reservation.Payment.ID
+
+		return insertReservation(tx, reservation)
+	})
+}
+
+// CreateBooking stores a booking, its payment and every one of its reservations in one transaction.
+// Each reservation goes through reserveInventory like in Book, so when one room is no longer free nothing is stored.
+// The booking is given a confirmation code no other booking has
+func (repo *ReservationRepositoryImpl) CreateBooking(ctx context.Context, booking *entity.Booking) error {
+	return repo.db.Transaction(ctx, func(tx *gorm.DB) error {
+		// lock the room types in one order, so two bookings sharing types wait for each other instead of deadlocking
+		roomTypeIDs := make([]uuid.UUID, 0, len(booking.Reservations))
+		for _, reservation := range booking.Reservations {
+			roomTypeIDs = append(roomTypeIDs, reservation.RoomTypeID)
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", roomTypeIDs).Order("id").Find(&[]roomEntity.RoomType{}).Error; err != nil {
+			return fmt.Errorf("failed to lock room types: %w", err)
+		}
+
+		code, err := uniqueCode(tx, &entity.Booking{})
+		if err != nil {
+			return err
+		}
+		booking.Code = code
+
+		if err := tx.Create(&booking.Payment).Error; err != nil {
+			return fmt.Errorf("failed to create payment: %w", err)
+		}
+		booking.PaymentID = booking.Payment.ID
+
+		if err := tx.Omit(clause.Associations).Create(booking).Error; err != nil {
+			return fmt.Errorf("failed to create booking: %w", err)
+		}
+
+		// reservations are stored one by one, so each inventory check counts the rooms taken by the previous ones
+		for _, reservation := range booking.Reservations {
+			reservation.BookingID = &booking.ID
+			reservation.PaymentID = booking.PaymentID
+			if err := reserveInventory(tx, reservation); err != nil {
+				return err
+			}
+			if err := insertReservation(tx, reservation); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// maxCodeAttempts : how many random codes uniqueCode draws before giving up
+const maxCodeAttempts = 5
+
+// uniqueCode draws random confirmation codes until one is not used by another row of the model's table
+func uniqueCode(tx *gorm.DB, model interface{}) (string, error) {
+	for attempt := 0; attempt < maxCodeAttempts; attempt++ {
+		code, err := utils.GenerateCode(entity.CodeLength)
+		if err != nil {
+			return "", err
+		}
+
+		var taken int64
+		if err := tx.Model(model).Where("code = ?", code).Count(&taken).Error; err != nil {
+			return "", fmt.Errorf("failed to check confirmation code: %w", err)
+		}
+		if taken == 0 {
+			return code, nil
+		}
+	}
+	return "", errors.New("failed to generate a unique confirmation code")
+}
+
+// insertReservation stores a reservation whose payment is already stored under a new confirmation code, with its line items,
+// and takes up the waitlist offer it answers.
+// An overlap on an assigned room rejected by the reservations_no_overlap constraint is reported as entity.ErrRoomUnavailable
+func insertReservation(tx *gorm.DB, reservation *entity.Reservation) error {
+	code, err := uniqueCode(tx, &entity.Reservation{})
+	if err != nil {
+		return err
+	}
+	reservation.Code = code
+
+	if err := tx.Omit(clause.Associations).Create(reservation).Error; err != nil {
+		if utils.IsExclusionViolation(err) {
+			return entity.ErrRoomUnavailable
+		}
+		return fmt.Errorf("failed to create reservation: %w", err)
+	}
+
+	for i := range reservation.LineItems {
+		reservation.LineItems[i].ReservationID = reservation.ID
+	}
+	if len(reservation.LineItems) > 0 {
+		if err := tx.Create(&reservation.LineItems).Error; err != nil {
+			return fmt.Errorf("failed to create reservation line items: %w", err)
+		}
+	}
+
+	// a guest booking the room type for dates they were offered takes up the offer
+	if err := tx.Model(&entity.WaitlistEntry{}).
+		Where("user_id = ? AND room_type_id = ? AND status = ?", reservation.UserID, reservation.RoomTypeID, entity.WaitlistOffered).
+		Where("check_in_date < ? AND check_out_date > ?", reservation.CheckOutDate, reservation.CheckInDate).
+		Updates(map[string]interface{}{
+			"status":     entity.WaitlistBooked,
+			"updated_at": time.Now(),
+		}).Error; err != nil {
+		return fmt.Errorf("failed to take up waitlist offer: %w", err)
+	}
+	return nil
+}
+
+// GetBooking returns a booking with its payment and reservations, in the order they were booked
+func (repo *ReservationRepositoryImpl) GetBooking(ctx context.Context, id uuid.UUID) (*entity.Booking, error) {
+	var booking entity.Booking
+	if err := repo.db.WithContext(ctx).
+		Preload("Payment").
+		Preload("Payment.Adjustments", orderedAdjustments).
+		Preload("Reservations", func(db *gorm.DB) *gorm.DB { return db.Order("created_at, id") }).
+		Preload("Reservations.User", withoutPassword).
+		Preload("Reservations.Payment").
+		Preload("Reservations.Payment.Adjustments", orderedAdjustments).
+		Preload("Reservations.RoomType").
+		Preload("Reservations.Room").
+		Preload("Reservations.Room.RoomType").
+		Preload("Reservations.LineItems", orderedLineItems).
+		Where("id = ?", id).First(&booking).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrBookingNotFound
+		}
+		return nil, err
+	}
+	return &booking, nil
+}
+
+// reserveInventory locks the reservation's room type, and its room when one is assigned, for the rest of the transaction,
+// expires the run-out holds of the type around the stay and makes sure a room of the type is still free on every night.
+// Locking the type serialises every booking of it, whether or not it names a room
+func reserveInventory(tx *gorm.DB, reservation *entity.Reservation) error {
+	var roomType roomEntity.RoomType
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", reservation.RoomTypeID).First(&roomType).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("room type not found")
+		}
+		return fmt.Errorf("failed to lock room type: %w", err)
+	}
+
+	if reservation.RoomID != nil {
+		var room roomEntity.Room
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", reservation.RoomID).First(&room).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("room not found")
+			}
+			return fmt.Errorf("failed to lock room: %w", err)
+		}
+		if room.Status == roomEntity.UnderMaintenance {
+			return fmt.Errorf("%w: room is under maintenance", entity.ErrRoomUnavailable)
+		}
+		if room.RoomTypeID != reservation.RoomTypeID {
+			return fmt.Errorf("%w: room is not of the booked type", entity.ErrRoomUnavailable)
+		}
+	}
+
+	if _, err := expireHolds(tx, time.Now(), "room_type_id = ? AND check_in_date < ? AND check_out_date > ? AND id <> ?",
+		reservation.RoomTypeID, reservation.CheckOutDate, reservation.CheckInDate, reservation.ID); err != nil {
+		return err
+	}
+
+	free, err := countFreeRooms(tx, reservation.RoomTypeID, reservation.CheckInDate, reservation.CheckOutDate, reservation.ID, reservation.UserID)
+	if err != nil {
+		return err
+	}
+	if free == 0 {
+		return fmt.Errorf("%w: no rooms of that type left", entity.ErrRoomUnavailable)
+	}
+	return nil
+}
+
+// CountFreeRooms returns how many rooms of a type are free on every night of [checkIn, checkOut) for the guest.
+// The reservation with ID exclude, if any, is not counted, so a reservation being modified does not compete with itself,
+// and neither are the rooms offered to the guest from the waitlist. uuid.Nil stands for any guest
+func (repo *ReservationRepositoryImpl) CountFreeRooms(ctx context.Context, roomTypeID uuid.UUID, checkIn, checkOut time.Time, exclude, guest uuid.UUID) (int, error) {
+	return countFreeRooms(repo.db.WithContext(ctx), roomTypeID, checkIn, checkOut, exclude, guest)
+}
+
+// countFreeRooms counts the type's rooms in service, the maintenance windows closing them, its reservations,
+// assigned or not, and the rooms held for other guests by waitlist offers around the stay.
+// Rooms in service are the available ones, the rooms the allocator assigns
+func countFreeRooms(tx *gorm.DB, roomTypeID uuid.UUID, checkIn, checkOut time.Time, exclude, guest uuid.UUID) (int, error) {
+	inService := tx.Model(&roomEntity.Room{}).Where("room_type_id = ? AND status = ?", roomTypeID, roomEntity.Available).Session(&gorm.Session{})
+	var rooms int64
+	if err := inService.Count(&rooms).Error; err != nil {
+		return 0, fmt.Errorf("failed to count rooms: %w", err)
+	}
+
+	var windows []*roomEntity.MaintenanceWindow
+	if err := tx.Where("room_id IN (?) AND start_date < ? AND end_date > ?", inService.Select("id"), checkOut, checkIn).
+		Find(&windows).Error; err != nil {
+		return 0, fmt.Errorf("failed to get maintenance windows of room type: %w", err)
+	}
+
+	var reservations []*entity.Reservation
+	if err := tx.Preload("Payment").Where("room_type_id = ? AND id <> ?", roomTypeID, exclude).
+		Where(entity.OverlapCondition, checkOut, checkIn, entity.BlockingStatuses, time.Now()).
+		Find(&reservations).Error; err != nil {
+		return 0, fmt.Errorf("failed to get reservations of room type: %w", err)
+	}
+
+	var offers []*entity.WaitlistEntry
+	if err := tx.Where("room_type_id = ? AND user_id <> ? AND status = ? AND offer_expires_at > ?",
+		roomTypeID, guest, entity.WaitlistOffered, time.Now()).
+		Where("check_in_date < ? AND check_out_date > ?", checkOut, checkIn).
+		Find(&offers).Error; err != nil {
+		return 0, fmt.Errorf("failed to get waitlist offers of room type: %w", err)
+	}
+	for _, offer := range offers {
+		reservations = append(reservations, offer.Hold())
+	}
+
+	return entity.FreeRooms(int(rooms), windows, reservations, checkIn, checkOut), nil
+}
+
 func (repo *ReservationRepositoryImpl) Update(ctx context.Context, reservation *entity.Reservation) (*entity.Reservation, error) {
 	updatedReservation := reservation
 
@@ -103,6 +400,205 @@ func (repo *ReservationRepositoryImpl) Update(ctx context.Context, reservation *
 	return updatedReservation, nil
 }
 
+// Modify saves new dates, room, guest count & price of a pending or confirmed reservation, replaces its line items
+// and records the change, all in one transaction.
+// As in Book, the inventory is checked under lock and an overlap is reported as entity.ErrRoomUnavailable
+func (repo *ReservationRepositoryImpl) Modify(ctx context.Context, reservation *entity.Reservation, change *entity.Change) error {
+	return repo.db.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := reserveInventory(tx, reservation); err != nil {
+			return err
+		}
+
+		result := tx.Model(&entity.Reservation{}).
+			Where("id = ? AND status IN ?", reservation.ID, []entity.Status{entity.StatusPending, entity.StatusConfirmed}).
+			Updates(map[string]interface{}{
+				"room_type_id":   reservation.RoomTypeID,
+				"room_id":        reservation.RoomID,
+				"check_in_date":  reservation.CheckInDate,
+				"check_out_date": reservation.CheckOutDate,
+				"num_guests":     reservation.NumGuests,
+				"total_price":    reservation.TotalPrice,
+				"updated_at":     time.Now(),
+			})
+		if result.Error != nil {
+			if utils.IsExclusionViolation(result.Error) {
+				return entity.ErrRoomUnavailable
+			}
+			return fmt.Errorf("failed to update reservation: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return entity.ErrNotModifiable
+		}
+
+		if err := tx.Where("reservation_id = ?", reservation.ID).Delete(&entity.LineItem{}).Error; err != nil {
+			return fmt.Errorf("failed to remove reservation line items: %w", err)
+		}
+		for i := range reservation.LineItems {
+			reservation.LineItems[i].ID = uuid.Nil
+			reservation.LineItems[i].ReservationID = reservation.ID
+		}
+		if len(reservation.LineItems) > 0 {
+			if err := tx.Create(&reservation.LineItems).Error; err != nil {
+				return fmt.Errorf("failed to create reservation line items: %w", err)
+			}
+		}
+
+		if err := tx.Create(change).Error; err != nil {
+			return fmt.Errorf("failed to record reservation change: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetUnassignedArrivals returns the live reservations checking in on the given day that have no room yet, oldest booking first
+func (repo *ReservationRepositoryImpl) GetUnassignedArrivals(ctx context.Context, date time.Time) ([]*entity.Reservation, error) {
+	var reservations []*entity.Reservation
+	if err := repo.db.WithContext(ctx).
+		Where("room_id IS NULL AND check_in_date >= ? AND check_in_date < ?", date, date.AddDate(0, 0, 1)).
+		Where("status IN ? AND NOT ("+entity.ExpiredHoldCondition+")", entity.BlockingStatuses, time.Now()).
+		Order("created_at, id").Find(&reservations).Error; err != nil {
+		return nil, fmt.Errorf("failed to get unassigned arrivals: %w", err)
+	}
+	return reservations, nil
+}
+
+// AssignRoom allocates a room to a reservation that has none and records the change, in one transaction.
+// A room taken in the meantime is reported as entity.ErrRoomUnavailable by the reservations_no_overlap constraint
+func (repo *ReservationRepositoryImpl) AssignRoom(ctx context.Context, reservationID, roomID uuid.UUID, change *entity.Change) error {
+	return repo.db.Transaction(ctx, func(tx *gorm.DB) error {
+		result := tx.Model(&entity.Reservation{}).
+			Where("id = ? AND room_id IS NULL AND status IN ?", reservationID, []entity.Status{entity.StatusPending, entity.StatusConfirmed}).
+			Updates(map[string]interface{}{
+				"room_id":    roomID,
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil {
+			if utils.IsExclusionViolation(result.Error) {
+				return entity.ErrRoomUnavailable
+			}
+			return fmt.Errorf("failed to assign room: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return entity.ErrAlreadyAssigned
+		}
+
+		if err := tx.Create(change).Error; err != nil {
+			return fmt.Errorf("failed to record reservation change: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetChanges returns the history of a reservation, oldest first
+func (repo *ReservationRepositoryImpl) GetChanges(ctx context.Context, reservationID uuid.UUID) ([]*entity.Change, error) {
+	var changes []*entity.Change
+	if err := repo.db.WithContext(ctx).
+		Where("reservation_id = ?", reservationID).
+		Order("created_at").Find(&changes).Error; err != nil {
+		return nil, fmt.Errorf("failed to get reservation history: %w", err)
+	}
+	return changes, nil
+}
+
+// AddChange records a change to a reservation that is not made through its other methods, e.g. a refund that failed
+func (repo *ReservationRepositoryImpl) AddChange(ctx context.Context, change *entity.Change) error {
+	if err := repo.db.WithContext(ctx).Create(change).Error; err != nil {
+		return fmt.Errorf("failed to record reservation change: %w", err)
+	}
+	return nil
+}
+
+// Transition moves a reservation from one status to another and records the change, in one transaction.
+// The update only applies while the reservation is still in the from status, so a concurrent transition
+// is reported as an *entity.InvalidTransitionError instead of being overwritten
+func (repo *ReservationRepositoryImpl) Transition(ctx context.Context, id uuid.UUID, from, to entity.Status, change *entity.Change) error {
+	return repo.db.Transaction(ctx, func(tx *gorm.DB) error {
+		result := tx.Model(&entity.Reservation{}).
+			Where("id = ? AND status = ?", id, from).
+			Updates(map[string]interface{}{
+				"status":     to,
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to update reservation status: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return &entity.InvalidTransitionError{From: from, To: to}
+		}
+
+		if err := tx.Create(change).Error; err != nil {
+			return fmt.Errorf("failed to record reservation change: %w", err)
+		}
+		return nil
+	})
+}
+
+// ExpireHolds expires every unpaid hold that ran out at or before now and reports how many were expired
+func (repo *ReservationRepositoryImpl) ExpireHolds(ctx context.Context, now time.Time) (int, error) {
+	var expired int
+	err := repo.db.Transaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		expired, err = expireHolds(tx, now, "")
+		return err
+	})
+	return expired, err
+}
+
+// GetLapsedAuthorizedHolds returns the PENDING reservations whose hold ran out at or before now while their capture is outstanding.
+// They keep their rooms until their authorization is voided
+func (repo *ReservationRepositoryImpl) GetLapsedAuthorizedHolds(ctx context.Context, now time.Time) ([]*entity.Reservation, error) {
+	var reservations []*entity.Reservation
+	if err := repo.db.WithContext(ctx).
+		Where(entity.LapsedHoldCondition+" AND "+entity.CaptureOutstandingCondition, now).
+		Find(&reservations).Error; err != nil {
+		return nil, fmt.Errorf("failed to get lapsed holds awaiting capture: %w", err)
+	}
+	return reservations, nil
+}
+
+// expireHolds moves the PENDING reservations whose hold ran out at or before now, narrowed by the optional query,
+// to EXPIRED, which releases their rooms. Their payments still PENDING are marked FAILED and every transition is recorded.
+// Holds whose capture is outstanding are left alone until their authorization is voided.
+// Rows locked by another transaction are skipped and left for the next sweep
+func expireHolds(tx *gorm.DB, now time.Time, query string, args ...interface{}) (int, error) {
+	held := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where(entity.ExpiredHoldCondition, now)
+	if query != "" {
+		held = held.Where(query, args...)
+	}
+
+	var reservations []entity.Reservation
+	if err := held.Find(&reservations).Error; err != nil {
+		return 0, fmt.Errorf("failed to find expired holds: %w", err)
+	}
+
+	for _, reservation := range reservations {
+		change, err := entity.NewTransition(entity.SystemActor, reservation.ID, reservation.Status, entity.StatusExpired)
+		if err != nil {
+			return 0, fmt.Errorf("failed to record reservation change: %w", err)
+		}
+		if err := tx.Model(&entity.Reservation{}).Where("id = ?", reservation.ID).Updates(map[string]interface{}{
+			"status":     entity.StatusExpired,
+			"updated_at": now,
+		}).Error; err != nil {
+			return 0, fmt.Errorf("failed to expire reservation: %w", err)
+		}
+		if err := tx.Model(&paymentEntity.Payment{}).
+			Where("id = ? AND payment_status = ?", reservation.PaymentID, paymentEntity.StatusPending).
+			Updates(map[string]interface{}{
+				"payment_status": paymentEntity.StatusFailed,
+				"failure_reason": entity.HoldExpiredReason,
+				"updated_at":     now,
+			}).Error; err != nil {
+			return 0, fmt.Errorf("failed to fail payment of expired reservation: %w", err)
+		}
+		if err := tx.Create(change).Error; err != nil {
+			return 0, fmt.Errorf("failed to record reservation change: %w", err)
+		}
+	}
+	return len(reservations), nil
+}
+
 func (repo *ReservationRepositoryImpl) Delete(ctx context.Context, id uuid.UUID) error {
 	if err := repo.db.WithContext(ctx).Delete(&entity.Reservation{}, id).Error; err != nil {
 		return fmt.Errorf("failed to delete reservation: %w", err)
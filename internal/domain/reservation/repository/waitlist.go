@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/entity"
+	roomEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/infrastructure/database"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// WaitlistRepository repository for the waitlists of sold-out room types
+type WaitlistRepository interface {
+	Create(ctx context.Context, entry *entity.WaitlistEntry) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.WaitlistEntry, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.WaitlistEntry, error)
+	FindActive(ctx context.Context, userID, roomTypeID uuid.UUID, checkIn, checkOut time.Time) (*entity.WaitlistEntry, error)
+	Leave(ctx context.Context, id uuid.UUID) error
+
+	GetWaiting(ctx context.Context, from time.Time) ([]*entity.WaitlistEntry, error)
+	Offer(ctx context.Context, entry *entity.WaitlistEntry, expiresAt time.Time) error
+	ExpireOffers(ctx context.Context, now time.Time) (int, error)
+}
+
+type WaitlistRepositoryImpl struct {
+	db *database.Service
+}
+
+func NewWaitlistRepository(db *database.Service) *WaitlistRepositoryImpl {
+	return &WaitlistRepositoryImpl{db: db}
+}
+
+func (repo *WaitlistRepositoryImpl) Create(ctx context.Context, entry *entity.WaitlistEntry) error {
+	if err := repo.db.WithContext(ctx).Omit(clause.Associations).Create(entry).Error; err != nil {
+		return fmt.Errorf("failed to join waitlist: %w", err)
+	}
+	return nil
+}
+
+func (repo *WaitlistRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*entity.WaitlistEntry, error) {
+	var entry entity.WaitlistEntry
+	if err := repo.db.WithContext(ctx).
+		Preload("RoomType").
+		Where("id = ?", id).First(&entry).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrWaitlistEntryNotFound
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// GetByUserID returns every waitlist entry of a guest, latest first
+func (repo *WaitlistRepositoryImpl) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.WaitlistEntry, error) {
+	var entries []*entity.WaitlistEntry
+	if err := repo.db.WithContext(ctx).
+		Preload("RoomType").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to get waitlist entries: %w", err)
+	}
+	return entries, nil
+}
+
+// FindActive returns the guest's active entry for exactly that room type and stay, or nil when there is none
+func (repo *WaitlistRepositoryImpl) FindActive(ctx context.Context, userID, roomTypeID uuid.UUID, checkIn, checkOut time.Time) (*entity.WaitlistEntry, error) {
+	var entries []*entity.WaitlistEntry
+	if err := repo.db.WithContext(ctx).
+		Where("user_id = ? AND room_type_id = ? AND check_in_date = ? AND check_out_date = ? AND status IN ?",
+			userID, roomTypeID, checkIn, checkOut, entity.ActiveWaitlistStatuses).
+		Limit(1).Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to get waitlist entry: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return entries[0], nil
+}
+
+// Leave takes an active entry off the waitlist, releasing the room offered to it if any
+func (repo *WaitlistRepositoryImpl) Leave(ctx context.Context, id uuid.UUID) error {
+	result := repo.db.WithContext(ctx).Model(&entity.WaitlistEntry{}).
+		Where("id = ? AND status IN ?", id, entity.ActiveWaitlistStatuses).
+		Updates(map[string]interface{}{
+			"status":     entity.WaitlistLeft,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to leave waitlist: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrWaitlistEntryNotActive
+	}
+	return nil
+}
+
+// GetWaiting returns the entries still waiting for a stay starting on or after from, in the order the guests joined
+func (repo *WaitlistRepositoryImpl) GetWaiting(ctx context.Context, from time.Time) ([]*entity.WaitlistEntry, error) {
+	var entries []*entity.WaitlistEntry
+	if err := repo.db.WithContext(ctx).
+		Preload("User", withoutPassword).
+		Preload("RoomType").
+		Where("status = ? AND check_in_date >= ?", entity.WaitlistWaiting, from).
+		Order("created_at, id").Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to get waitlist: %w", err)
+	}
+	return entries, nil
+}
+
+// Offer holds a room of the entry's type for its guest until expiresAt, provided one is still free on every night
+// of the stay. Like a booking it locks the room type, so the room cannot be booked and offered at the same time.
+// When no room is free it returns entity.ErrRoomUnavailable and the entry keeps waiting
+func (repo *WaitlistRepositoryImpl) Offer(ctx context.Context, entry *entity.WaitlistEntry, expiresAt time.Time) error {
+	return repo.db.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", entry.RoomTypeID).First(&roomEntity.RoomType{}).Error; err != nil {
+			return fmt.Errorf("failed to lock room type: %w", err)
+		}
+
+		free, err := countFreeRooms(tx, entry.RoomTypeID, entry.CheckInDate, entry.CheckOutDate, uuid.Nil, entry.UserID)
+		if err != nil {
+			return err
+		}
+		if free == 0 {
+			return entity.ErrRoomUnavailable
+		}
+
+		result := tx.Model(&entity.WaitlistEntry{}).
+			Where("id = ? AND status = ?", entry.ID, entity.WaitlistWaiting).
+			Updates(map[string]interface{}{
+				"status":           entity.WaitlistOffered,
+				"offer_expires_at": expiresAt,
+				"updated_at":       time.Now(),
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to offer room: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return entity.ErrWaitlistEntryNotActive
+		}
+
+		entry.Status = entity.WaitlistOffered
+		entry.OfferExpiresAt = &expiresAt
+		return nil
+	})
+}
+
+// ExpireOffers expires the offers that ran out at or before now, and the entries whose stay has started without a room,
+// and reports how many entries were expired
+func (repo *WaitlistRepositoryImpl) ExpireOffers(ctx context.Context, now time.Time) (int, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	result := repo.db.WithContext(ctx).Model(&entity.WaitlistEntry{}).
+		Where("(status = ? AND offer_expires_at <= ?) OR (status = ? AND check_in_date < ?)",
+			entity.WaitlistOffered, now, entity.WaitlistWaiting, today).
+		Updates(map[string]interface{}{
+			"status":     entity.WaitlistExpired,
+			"updated_at": now,
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to expire waitlist offers: %w", result.Error)
+	}
+	return int(result.RowsAffected), nil
+}
\ No newline at end of file
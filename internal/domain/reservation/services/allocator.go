@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/repository"
+	roomEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/entity"
+	roomRepository "github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/repository"
+	"github.com/google/uuid"
+	"log"
+	"sort"
+	"time"
+)
+
+const (
+	// allocationInterval : how often the background run allocates rooms
+	allocationInterval = 24 * time.Hour
+	// allocationDaysAhead : besides today's, the arrivals of this many following days get a room on each run
+	allocationDaysAhead = 1
+)
+
+// RoomAllocator assigns physical rooms to reservations booked by room type, ahead of arrival
+type RoomAllocator interface {
+	AssignRooms(ctx context.Context, actor entity.Actor, date time.Time) (*entity.AllocationResult, error)
+}
+
+type RoomAllocatorImpl struct {
+	reservationRepo repository.ReservationRepository
+	roomRepo        roomRepository.RoomRepository
+}
+
+func NewRoomAllocator(reservationRepo repository.ReservationRepository, roomRepo roomRepository.RoomRepository) *RoomAllocatorImpl {
+	return &RoomAllocatorImpl{
+		reservationRepo: reservationRepo,
+		roomRepo:        roomRepo,
+	}
+}
+
+// AssignRooms allocates rooms to the reservations arriving on date that have none yet.
+// Rooms are picked from the available rooms of the booked type that no stay or maintenance window occupies,
+// preferring, in order: the room of the guest's adjoining stay, the accessibility the guest asked for,
+// the closest floor to the one they asked for, and the lowest room number. The same data always gives the same plan
+func (a *RoomAllocatorImpl) AssignRooms(ctx context.Context, actor entity.Actor, date time.Time) (*entity.AllocationResult, error) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	result := &entity.AllocationResult{
+		Date:       day.Format(time.DateOnly),
+		Assigned:   []entity.Assignment{},
+		Unassigned: []entity.Unallocated{},
+	}
+
+	arrivals, err := a.reservationRepo.GetUnassignedArrivals(ctx, day)
+	if err != nil {
+		return nil, err
+	}
+	if len(arrivals) == 0 {
+		return result, nil
+	}
+
+	lastCheckOut := day.AddDate(0, 0, 1)
+	for _, arrival := range arrivals {
+		if arrival.CheckOutDate.After(lastCheckOut) {
+			lastCheckOut = arrival.CheckOutDate
+		}
+	}
+
+	// from the day before, so stays ending on the arrival day are there for continuity
+	booked, err := a.reservationRepo.GetByDateRange(ctx, day.AddDate(0, 0, -1), lastCheckOut)
+	if err != nil {
+		return nil, err
+	}
+	rooms, err := a.roomRepo.GetRooms(ctx, map[string]interface{}{"status": roomEntity.Available})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rooms: %w", err)
+	}
+	windows, err := a.roomRepo.GetMaintenanceWindows(ctx, day, lastCheckOut)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, allocation := range allocate(rooms, windows, booked, arrivals) {
+		reservation := allocation.reservation
+		if allocation.room == nil {
+			result.Unassigned = append(result.Unassigned, entity.Unallocated{ReservationID: reservation.ID, Reason: allocation.reason})
+			continue
+		}
+
+		change, err := entity.NewAssignment(actor, reservation.ID, allocation.room.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record reservation change: %w", err)
+		}
+		err = a.reservationRepo.AssignRoom(ctx, reservation.ID, allocation.room.ID, change)
+		if errors.Is(err, entity.ErrRoomUnavailable) || errors.Is(err, entity.ErrAlreadyAssigned) {
+			// booked or allocated concurrently; the next run picks the reservation up again if it still needs a room
+			result.Unassigned = append(result.Unassigned, entity.Unallocated{ReservationID: reservation.ID, Reason: err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		result.Assigned = append(result.Assigned, entity.Assignment{
+			ReservationID: reservation.ID,
+			RoomID:        allocation.room.ID,
+			RoomNumber:    allocation.room.RoomNumber,
+		})
+	}
+	return result, nil
+}
+
+// Run allocates rooms to the arrivals of today and the following allocationDaysAhead days straight away,
+// then again every allocationInterval, until ctx is cancelled
+func (a *RoomAllocatorImpl) Run(ctx context.Context) {
+	ticker := time.NewTicker(allocationInterval)
+	defer ticker.Stop()
+
+	for {
+		today := time.Now().UTC()
+		for days := 0; days <= allocationDaysAhead && ctx.Err() == nil; days++ {
+			result, err := a.AssignRooms(ctx, entity.SystemActor, today.AddDate(0, 0, days))
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("room allocator: %v", err)
+				}
+				continue
+			}
+			if len(result.Assigned) > 0 || len(result.Unassigned) > 0 {
+				log.Printf("room allocator: %s: assigned %d, could not assign %d", result.Date, len(result.Assigned), len(result.Unassigned))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// allocation : the room planned for a reservation, or the reason there is none
+type allocation struct {
+	reservation *entity.Reservation
+	room        *roomEntity.Room
+	reason      string
+}
+
+// allocate plans a room for each arrival. It only reads its arguments, and orders everything it iterates over,
+// so the plan does not depend on the order the repositories return rows in
+func allocate(rooms []*roomEntity.Room, windows []*roomEntity.MaintenanceWindow, booked, arrivals []*entity.Reservation) []allocation {
+	// what already occupies each room, extended as the plan assigns rooms
+	occupied := make(map[uuid.UUID][]*entity.Reservation)
+	for _, reservation := range booked {
+		if reservation.IsAssigned() {
+			occupied[*reservation.RoomID] = append(occupied[*reservation.RoomID], reservation)
+		}
+	}
+	closed := make(map[uuid.UUID][]*roomEntity.MaintenanceWindow)
+	for _, window := range windows {
+		closed[window.RoomID] = append(closed[window.RoomID], window)
+	}
+
+	// guests continuing a stay first, so their room is still free, then guests needing an accessible room,
+	// then the longest stays as they are the hardest to fit
+	continuing := make(map[uuid.UUID]bool)
+	for _, reservation := range arrivals {
+		continuing[reservation.ID] = len(adjoiningRooms(reservation, booked, nil)) > 0
+	}
+	pending := append([]*entity.Reservation(nil), arrivals...)
+	sort.SliceStable(pending, func(i, j int) bool {
+		a, b := pending[i], pending[j]
+		if continuing[a.ID] != continuing[b.ID] {
+			return continuing[a.ID]
+		}
+		if a.Preferences.Accessible != b.Preferences.Accessible {
+			return a.Preferences.Accessible
+		}
+		if nightsA, nightsB := a.CheckOutDate.Sub(a.CheckInDate), b.CheckOutDate.Sub(b.CheckInDate); nightsA != nightsB {
+			return nightsA > nightsB
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID.String() < b.ID.String()
+	})
+
+	plan := make([]allocation, 0, len(pending))
+	for _, reservation := range pending {
+		var candidates []*roomEntity.Room
+		for _, room := range rooms {
+			if room.RoomTypeID == reservation.RoomTypeID && room.Status == roomEntity.Available &&
+				isFree(room.ID, reservation, occupied, closed) {
+				candidates = append(candidates, room)
+			}
+		}
+		if len(candidates) == 0 {
+			plan = append(plan, allocation{reservation: reservation, reason: "no room of the booked type is free for the whole stay"})
+			continue
+		}
+
+		adjoining := adjoiningRooms(reservation, booked, plan)
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return roomScore(candidates[i], reservation, adjoining).less(roomScore(candidates[j], reservation, adjoining))
+		})
+
+		room := candidates[0]
+		assigned := *reservation
+		assigned.RoomID = &room.ID
+		occupied[room.ID] = append(occupied[room.ID], &assigned)
+		plan = append(plan, allocation{reservation: &assigned, room: room})
+	}
+	return plan
+}
+
+// isFree reports whether no stay and no maintenance window occupies the room during the reservation
+func isFree(roomID uuid.UUID, reservation *entity.Reservation, occupied map[uuid.UUID][]*entity.Reservation, closed map[uuid.UUID][]*roomEntity.MaintenanceWindow) bool {
+	for _, other := range occupied[roomID] {
+		if other.ID != reservation.ID && other.Overlaps(reservation.CheckInDate, reservation.CheckOutDate) {
+			return false
+		}
+	}
+	for _, window := range closed[roomID] {
+		if window.Overlaps(reservation.CheckInDate, reservation.CheckOutDate) {
+			return false
+		}
+	}
+	return true
+}
+
+// adjoiningRooms returns the rooms of the guest's stays that end as this one starts or start as it ends,
+// so keeping the guest in one of them saves a room move
+func adjoiningRooms(reservation *entity.Reservation, booked []*entity.Reservation, plan []allocation) map[uuid.UUID]bool {
+	rooms := make(map[uuid.UUID]bool)
+	consider := func(other *entity.Reservation) {
+		if other.ID == reservation.ID || other.UserID != reservation.UserID || !other.IsAssigned() || !other.Status.IsBlocking() {
+			return
+		}
+		if other.CheckOutDate.Equal(reservation.CheckInDate) || other.CheckInDate.Equal(reservation.CheckOutDate) {
+			rooms[*other.RoomID] = true
+		}
+	}
+	for _, other := range booked {
+		consider(other)
+	}
+	for _, planned := range plan {
+		consider(planned.reservation)
+	}
+	return rooms
+}
+
+// score : how well a room suits a reservation, compared field by field. Lower is better
+type score struct {
+	move          int // 1 when the guest would have to change rooms between adjoining stays
+	accessibility int // 1 when the room's accessibility is not what the guest needs
+	floorDistance int
+	roomNumber    int
+}
+
+func roomScore(room *roomEntity.Room, reservation *entity.Reservation, adjoining map[uuid.UUID]bool) score {
+	s := score{roomNumber: room.RoomNumber}
+	if len(adjoining) > 0 && !adjoining[room.ID] {
+		s.move = 1
+	}
+	// accessible rooms are kept for the guests who need them
+	if room.Accessible != reservation.Preferences.Accessible {
+		s.accessibility = 1
+	}
+	if floor := reservation.Preferences.Floor; floor != nil {
+		s.floorDistance = abs(room.FloorNumber - *floor)
+	}
+	return s
+}
+
+func (s score) less(other score) bool {
+	if s.move != other.move {
+		return s.move < other.move
+	}
+	if s.accessibility != other.accessibility {
+		return s.accessibility < other.accessibility
+	}
+	if s.floorDistance != other.floorDistance {
+		return s.floorDistance < other.floorDistance
+	}
+	return s.roomNumber < other.roomNumber
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
\ No newline at end of file
@@ -0,0 +1,339 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gatimugabriel/hotel-reservation-system/internal/constants"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/entity"
+	roomEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/entity"
+	roomRepository "github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/repository"
+	"github.com/google/uuid"
+)
+
+func (m *memoryReservations) GetUnassignedArrivals(ctx context.Context, date time.Time) ([]*entity.Reservation, error) {
+	var arrivals []*entity.Reservation
+	for _, reservation := range m.sorted() {
+		if !reservation.IsAssigned() && reservation.CheckInDate.Equal(date) &&
+			reservation.Status.IsBlocking() && !reservation.HoldExpired(time.Now()) {
+			copied := *reservation
+			arrivals = append(arrivals, &copied)
+		}
+	}
+	return arrivals, nil
+}
+
+func (m *memoryReservations) GetByDateRange(ctx context.Context, checkIn, checkOut time.Time) ([]*entity.Reservation, error) {
+	var overlapping []*entity.Reservation
+	for _, reservation := range m.sorted() {
+		if reservation.Overlaps(checkIn, checkOut) {
+			copied := *reservation
+			overlapping = append(overlapping, &copied)
+		}
+	}
+	return overlapping, nil
+}
+
+func (m *memoryReservations) AssignRoom(ctx context.Context, reservationID, roomID uuid.UUID, change *entity.Change) error {
+	reservation := m.reservations[reservationID]
+	if reservation.IsAssigned() {
+		return entity.ErrAlreadyAssigned
+	}
+	for _, other := range m.reservations {
+		if other.IsAssigned() && *other.RoomID == roomID && other.Overlaps(reservation.CheckInDate, reservation.CheckOutDate) {
+			return entity.ErrRoomUnavailable
+		}
+	}
+	reservation.RoomID = &roomID
+	m.changes = append(m.changes, change)
+	return nil
+}
+
+// sorted returns the reservations in a stable order, as a database would with an ORDER BY
+func (m *memoryReservations) sorted() []*entity.Reservation {
+	var reservations []*entity.Reservation
+	for _, reservation := range m.reservations {
+		reservations = append(reservations, reservation)
+	}
+	sort.Slice(reservations, func(i, j int) bool { return reservations[i].ID.String() < reservations[j].ID.String() })
+	return reservations
+}
+
+// memoryRooms : in-memory RoomRepository. Methods the tests do not need panic through the nil embedded interface
+type memoryRooms struct {
+	roomRepository.RoomRepository
+	rooms   []*roomEntity.Room
+	windows []*roomEntity.MaintenanceWindow
+}
+
+func (m *memoryRooms) GetRooms(ctx context.Context, filters map[string]interface{}) ([]*roomEntity.Room, error) {
+	var rooms []*roomEntity.Room
+	for _, room := range m.rooms {
+		if status, ok := filters["status"]; ok && room.Status != status {
+			continue
+		}
+		if id, ok := filters["id"]; ok && room.ID != id {
+			continue
+		}
+		if roomTypeID, ok := filters["room_type_id"]; ok && room.RoomTypeID != roomTypeID {
+			continue
+		}
+		rooms = append(rooms, room)
+	}
+	return rooms, nil
+}
+
+func (m *memoryRooms) GetMaintenanceWindows(ctx context.Context, from, to time.Time) ([]*roomEntity.MaintenanceWindow, error) {
+	var windows []*roomEntity.MaintenanceWindow
+	for _, window := range m.windows {
+		if window.Overlaps(from, to) {
+			windows = append(windows, window)
+		}
+	}
+	return windows, nil
+}
+
+// allocationFixture : one room type with rooms 101-104 on floor 1 and 201-202 on floor 2; 202 is accessible
+type allocationFixture struct {
+	roomType uuid.UUID
+	rooms    map[int]*roomEntity.Room
+	arrival  time.Time
+}
+
+func newAllocationFixture() *allocationFixture {
+	f := &allocationFixture{
+		roomType: uuid.New(),
+		rooms:    make(map[int]*roomEntity.Room),
+		arrival:  time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC),
+	}
+	for _, number := range []int{101, 102, 103, 104, 201, 202} {
+		f.rooms[number] = &roomEntity.Room{
+			ID:          uuid.New(),
+			RoomNumber:  number,
+			FloorNumber: number / 100,
+			Status:      roomEntity.Available,
+			RoomTypeID:  f.roomType,
+			Accessible:  number == 202,
+		}
+	}
+	return f
+}
+
+func (f *allocationFixture) roomList() []*roomEntity.Room {
+	var rooms []*roomEntity.Room
+	for _, room := range f.rooms {
+		rooms = append(rooms, room)
+	}
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].ID.String() < rooms[j].ID.String() })
+	return rooms
+}
+
+// stay books the fixture's room type for the guest, starting offset days after the arrival day
+func (f *allocationFixture) stay(guest uuid.UUID, offset, nights int, room *roomEntity.Room) *entity.Reservation {
+	reservation := &entity.Reservation{
+		ID:           uuid.New(),
+		UserID:       guest,
+		Status:       entity.StatusConfirmed,
+		RoomTypeID:   f.roomType,
+		CheckInDate:  f.arrival.AddDate(0, 0, offset),
+		CheckOutDate: f.arrival.AddDate(0, 0, offset+nights),
+		CreatedAt:    f.arrival.AddDate(0, -1, 0),
+	}
+	if room != nil {
+		reservation.RoomID = &room.ID
+	}
+	return reservation
+}
+
+func assignedRoom(t *testing.T, repo *memoryReservations, reservation *entity.Reservation) *uuid.UUID {
+	t.Helper()
+	return repo.reservations[reservation.ID].RoomID
+}
+
+func TestAssignRoomsRespectsOccupancyStatusAndMaintenance(t *testing.T) {
+	f := newAllocationFixture()
+	f.rooms[101].Status = roomEntity.UnderMaintenance
+	f.rooms[103].Status = roomEntity.Unavailable
+	windows := []*roomEntity.MaintenanceWindow{
+		// closes 104 on the last night of the arrival's stay
+		{RoomID: f.rooms[104].ID, StartDate: f.arrival.AddDate(0, 0, 2), EndDate: f.arrival.AddDate(0, 0, 5)},
+	}
+
+	occupant := f.stay(uuid.New(), -2, 3, f.rooms[102]) // in 102 until the 11th
+	arrival := f.stay(uuid.New(), 0, 3, nil)
+	other := f.stay(uuid.New(), 0, 1, nil)
+	other.CreatedAt = arrival.CreatedAt.Add(time.Hour)
+	// another type's booking is left alone
+	foreign := f.stay(uuid.New(), 0, 1, nil)
+	foreign.RoomTypeID = uuid.New()
+
+	repo := newMemoryReservations(occupant, arrival, other, foreign)
+	allocator := NewRoomAllocator(repo, &memoryRooms{rooms: f.roomList(), windows: windows})
+	result, err := allocator.AssignRooms(context.Background(), entity.SystemActor, f.arrival)
+	if err != nil {
+		t.Fatalf("AssignRooms unexpected error: %v", err)
+	}
+
+	// 101 is under maintenance, 102 occupied, 103 unavailable and 104 closed by the window for the long stay,
+	// so the long stay goes to 201; the one night stay fits in 104 before the window
+	if got := assignedRoom(t, repo, arrival); got == nil || *got != f.rooms[201].ID {
+		t.Errorf("long stay assigned %v, want room 201", got)
+	}
+	if got := assignedRoom(t, repo, other); got == nil || *got != f.rooms[104].ID {
+		t.Errorf("one night stay assigned %v, want room 104", got)
+	}
+	if assignedRoom(t, repo, foreign) != nil {
+		t.Error("reservation of another room type was assigned a room")
+	}
+
+	if len(result.Assigned) != 2 || len(result.Unassigned) != 1 || result.Unassigned[0].ReservationID != foreign.ID {
+		t.Errorf("result = %+v, want 2 assigned and the other type's booking unassigned", result)
+	}
+	if result.Date != "2025-03-10" {
+		t.Errorf("result date = %s, want 2025-03-10", result.Date)
+	}
+	for _, change := range repo.changes {
+		if change.Action != entity.ActionRoomAssigned || change.ChangedByRole != entity.SystemRole {
+			t.Errorf("change = %+v, want a room assignment by the system", change)
+		}
+	}
+}
+
+func TestAssignRoomsKeepsGuestInRoomAcrossConsecutiveStays(t *testing.T) {
+	f := newAllocationFixture()
+	guest := uuid.New()
+
+	// the guest's first stay ends in 104 on the arrival day; the next one starts that same day
+	first := f.stay(guest, -2, 2, f.rooms[104])
+	second := f.stay(guest, 0, 2, nil)
+	repo := newMemoryReservations(first, second)
+
+	_, err := NewRoomAllocator(repo, &memoryRooms{rooms: f.roomList()}).AssignRooms(context.Background(), entity.SystemActor, f.arrival)
+	if err != nil {
+		t.Fatalf("AssignRooms unexpected error: %v", err)
+	}
+	if got := assignedRoom(t, repo, second); got == nil || *got != f.rooms[104].ID {
+		t.Errorf("consecutive stay assigned %v, want the guest's current room 104", got)
+	}
+}
+
+func TestAssignRoomsHonoursPreferences(t *testing.T) {
+	f := newAllocationFixture()
+	upstairs, accessible, plain := f.stay(uuid.New(), 0, 1, nil), f.stay(uuid.New(), 0, 1, nil), f.stay(uuid.New(), 0, 1, nil)
+	floor := 2
+	upstairs.Preferences.Floor = &floor
+	accessible.Preferences.Accessible = true
+	repo := newMemoryReservations(upstairs, accessible, plain)
+
+	_, err := NewRoomAllocator(repo, &memoryRooms{rooms: f.roomList()}).AssignRooms(context.Background(), entity.SystemActor, f.arrival)
+	if err != nil {
+		t.Fatalf("AssignRooms unexpected error: %v", err)
+	}
+
+	want := map[*entity.Reservation]int{
+		accessible: 202, // the only accessible room
+		upstairs:   201, // floor 2, leaving 202 for guests who need it
+		plain:      101,
+	}
+	for reservation, number := range want {
+		if got := assignedRoom(t, repo, reservation); got == nil || *got != f.rooms[number].ID {
+			t.Errorf("reservation preferring %+v assigned %v, want room %d", reservation.Preferences, got, number)
+		}
+	}
+}
+
+func TestAssignRoomsReportsWhatCannotBeAssigned(t *testing.T) {
+	f := newAllocationFixture()
+	var reservations []*entity.Reservation
+	for i := 0; i < 7; i++ { // one more than there are rooms
+		reservation := f.stay(uuid.New(), 0, 1, nil)
+		reservation.CreatedAt = reservation.CreatedAt.Add(time.Duration(i) * time.Minute)
+		reservations = append(reservations, reservation)
+	}
+	repo := newMemoryReservations(reservations...)
+
+	result, err := NewRoomAllocator(repo, &memoryRooms{rooms: f.roomList()}).AssignRooms(context.Background(), entity.SystemActor, f.arrival)
+	if err != nil {
+		t.Fatalf("AssignRooms unexpected error: %v", err)
+	}
+	if len(result.Assigned) != 6 || len(result.Unassigned) != 1 {
+		t.Fatalf("result = %+v, want 6 assigned and 1 unassigned", result)
+	}
+	// the latest booking is the one left without a room
+	if result.Unassigned[0].ReservationID != reservations[6].ID {
+		t.Errorf("unassigned %s, want the latest booking %s", result.Unassigned[0].ReservationID, reservations[6].ID)
+	}
+
+	// a second run only looks at what is still unassigned
+	again, err := NewRoomAllocator(repo, &memoryRooms{rooms: f.roomList()}).AssignRooms(context.Background(), entity.SystemActor, f.arrival)
+	if err != nil || len(again.Assigned) != 0 || len(again.Unassigned) != 1 {
+		t.Errorf("second run = %+v, %v, want nothing new assigned", again, err)
+	}
+}
+
+func TestAllocateIsDeterministic(t *testing.T) {
+	f := newAllocationFixture()
+	guest := uuid.New()
+	floor := 1
+	booked := []*entity.Reservation{
+		f.stay(guest, -1, 1, f.rooms[103]),
+		f.stay(uuid.New(), -3, 5, f.rooms[101]),
+	}
+	var arrivals []*entity.Reservation
+	for i := 0; i < 5; i++ {
+		arrivals = append(arrivals, f.stay(uuid.New(), 0, 1+i%3, nil))
+	}
+	arrivals[0].UserID = guest
+	arrivals[1].Preferences.Floor = &floor
+	arrivals[2].Preferences.Accessible = true
+
+	plan := func(rooms []*roomEntity.Room, arrivals []*entity.Reservation) map[uuid.UUID]uuid.UUID {
+		rooms = append([]*roomEntity.Room(nil), rooms...)
+		assigned := make(map[uuid.UUID]uuid.UUID)
+		for _, allocation := range allocate(rooms, nil, booked, arrivals) {
+			if allocation.room != nil {
+				assigned[allocation.reservation.ID] = allocation.room.ID
+			}
+		}
+		return assigned
+	}
+
+	want := plan(f.roomList(), arrivals)
+	if len(want) != len(arrivals) {
+		t.Fatalf("planned %d rooms, want %d", len(want), len(arrivals))
+	}
+	if want[arrivals[0].ID] != f.rooms[103].ID {
+		t.Errorf("returning guest planned into %s, want room 103", want[arrivals[0].ID])
+	}
+
+	// reversing the order the repositories return rows in must not change the plan
+	rooms := f.roomList()
+	for i, j := 0, len(rooms)-1; i < j; i, j = i+1, j-1 {
+		rooms[i], rooms[j] = rooms[j], rooms[i]
+	}
+	reversed := append([]*entity.Reservation(nil), arrivals...)
+	for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
+		reversed[i], reversed[j] = reversed[j], reversed[i]
+	}
+	got := plan(rooms, reversed)
+	for id, room := range want {
+		if got[id] != room {
+			t.Errorf("reservation %s planned into %s, then %s", id, room, got[id])
+		}
+	}
+}
+
+func TestAssignRoomsRecordsActor(t *testing.T) {
+	f := newAllocationFixture()
+	manager := entity.Actor{UserID: uuid.New(), Role: constants.MANAGER}
+	repo := newMemoryReservations(f.stay(uuid.New(), 0, 1, nil))
+
+	if _, err := NewRoomAllocator(repo, &memoryRooms{rooms: f.roomList()}).AssignRooms(context.Background(), manager, f.arrival); err != nil {
+		t.Fatalf("AssignRooms unexpected error: %v", err)
+	}
+	if len(repo.changes) != 1 || repo.changes[0].ChangedBy != manager.UserID || repo.changes[0].ChangedByRole != constants.MANAGER {
+		t.Errorf("changes = %+v, want one assignment by the manager", repo.changes)
+	}
+}
\ No newline at end of file
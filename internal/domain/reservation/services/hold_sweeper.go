@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/repository"
+	"github.com/google/uuid"
+	"log"
+	"time"
+)
+
+// HoldSweeper periodically expires unpaid reservations whose hold ran out, releasing their rooms
+// and failing their payments. Availability already ignores expired holds; the sweeper makes it final.
+// A hold whose capture is outstanding keeps its room until the sweeper has voided its authorization,
+// so a capture still in flight cannot go through once the room is released.
+// It then offers the rooms freed by expired holds, cancellations or no-shows to the waitlist
+type HoldSweeper struct {
+	reservationRepo repository.ReservationRepository
+	waitlist        WaitlistService
+	payments        payment.Service
+	interval        time.Duration
+}
+
+func NewHoldSweeper(reservationRepo repository.ReservationRepository, waitlist WaitlistService, payments payment.Service, interval time.Duration) *HoldSweeper {
+	return &HoldSweeper{
+		reservationRepo: reservationRepo,
+		waitlist:        waitlist,
+		payments:        payments,
+		interval:        interval,
+	}
+}
+
+// Run sweeps once immediately and then every interval, until ctx is cancelled
+func (s *HoldSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Sweep(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("hold sweeper: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep expires the holds that ran out by now, voiding the authorizations of those whose capture is outstanding first,
+// offers freed rooms to the waitlist and reports how many holds expired
+func (s *HoldSweeper) Sweep(ctx context.Context) (int, error) {
+	now := time.Now()
+	if err := s.voidLapsedAuthorizations(ctx, now); err != nil {
+		return 0, err
+	}
+	expired, err := s.reservationRepo.ExpireHolds(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	if expired > 0 {
+		log.Printf("hold sweeper: expired %d unpaid reservation(s)", expired)
+	}
+
+	offered, err := s.waitlist.OfferFreedRooms(ctx)
+	if err != nil {
+		return expired, fmt.Errorf("failed to offer freed rooms to the waitlist: %w", err)
+	}
+	if offered > 0 {
+		log.Printf("hold sweeper: offered %d room(s) to the waitlist", offered)
+	}
+	return expired, nil
+}
+
+// voidLapsedAuthorizations voids the payments of the holds that ran out with their capture outstanding, letting them expire.
+// A payment the gateway does not void, its capture having gone through, keeps its hold for the processor's webhook to confirm
+func (s *HoldSweeper) voidLapsedAuthorizations(ctx context.Context, now time.Time) error {
+	held, err := s.reservationRepo.GetLapsedAuthorizedHolds(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	for _, reservation := range held {
+		if seen[reservation.PaymentID] {
+			continue
+		}
+		seen[reservation.PaymentID] = true
+		if _, err := s.payments.VoidPayment(ctx, reservation.PaymentID, entity.HoldExpiredReason); err != nil {
+			log.Printf("hold sweeper: reservation %s keeps its hold: %v", reservation.ID, err)
+		}
+	}
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment"
+	paymentEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/money"
+	"github.com/google/uuid"
+)
+
+// GetLapsedAuthorizedHolds returns the pending reservations whose hold ran out while their payment is AUTHORIZED
+func (m *memoryReservations) GetLapsedAuthorizedHolds(ctx context.Context, now time.Time) ([]*entity.Reservation, error) {
+	var held []*entity.Reservation
+	for id := range m.reservations {
+		reservation, _ := m.GetByID(ctx, id)
+		if reservation.Status == entity.StatusPending && reservation.HoldExpiresAt != nil &&
+			!reservation.HoldExpiresAt.After(now) && reservation.CaptureOutstanding() {
+			held = append(held, reservation)
+		}
+	}
+	return held, nil
+}
+
+// ExpireHolds expires the holds that ran out, failing their payments still PENDING, like the database repository does
+func (m *memoryReservations) ExpireHolds(ctx context.Context, now time.Time) (int, error) {
+	expired := 0
+	for id, stored := range m.reservations {
+		reservation, _ := m.GetByID(ctx, id)
+		if !reservation.HoldExpired(now) {
+			continue
+		}
+		stored.Status = entity.StatusExpired
+		if payment := m.payments.payments[stored.PaymentID]; payment.PaymentStatus == paymentEntity.StatusPending {
+			payment.PaymentStatus = paymentEntity.StatusFailed
+			payment.FailureReason = entity.HoldExpiredReason
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// noWaitlist : WaitlistService with nobody waiting
+type noWaitlist struct {
+	WaitlistService
+}
+
+func (noWaitlist) OfferFreedRooms(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func TestHoldSweeperVoidsOutstandingCaptures(t *testing.T) {
+	ctx := context.Background()
+	payments := &memoryPayments{payments: make(map[uuid.UUID]*paymentEntity.Payment)}
+	gateway := payment.NewFakeGateway()
+	paymentService := payment.NewPaymentService(payments, gateway)
+	repo := newMemoryReservations()
+	repo.payments = payments
+	lapsed := time.Now().Add(-time.Minute)
+
+	// hold reserves a room for a card payment whose capture timed out, leaving it AUTHORIZED
+	hold := func() *entity.Reservation {
+		charge := &paymentEntity.Payment{
+			ID:            uuid.New(),
+			Amount:        money.MustParse("200.00", "USD"),
+			Currency:      "USD",
+			PaymentMethod: paymentEntity.MethodCreditCard,
+			PaymentStatus: paymentEntity.StatusPending,
+			TransactionID: uuid.NewString(),
+		}
+		payments.Create(ctx, charge)
+		gateway.Script(payment.FakeApprove, payment.FakeTimeout)
+		if err := paymentService.ProcessPayment(ctx, charge); !errors.Is(err, payment.ErrGatewayTimeout) {
+			t.Fatalf("ProcessPayment error = %v, want %v", err, payment.ErrGatewayTimeout)
+		}
+		reservation := &entity.Reservation{ID: uuid.New(), Status: entity.StatusPending, HoldExpiresAt: &lapsed, PaymentID: charge.ID}
+		repo.reservations[reservation.ID] = reservation
+		return reservation
+	}
+	inFlight := hold()
+	captured := hold()
+	// the second capture went through after all, so the gateway refuses to void it
+	capturedPayment := payments.payments[captured.PaymentID]
+	if err := gateway.Capture(ctx, capturedPayment.GatewayReference, capturedPayment.Amount); err != nil {
+		t.Fatal(err)
+	}
+
+	expired, err := NewHoldSweeper(repo, noWaitlist{}, paymentService, time.Minute).Sweep(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expired != 1 {
+		t.Errorf("expired = %d, want 1", expired)
+	}
+
+	if inFlight.Status != entity.StatusExpired {
+		t.Errorf("hold with its capture voided = %s, want %s", inFlight.Status, entity.StatusExpired)
+	}
+	if got := payments.payments[inFlight.PaymentID]; got.PaymentStatus != paymentEntity.StatusVoided || got.FailureReason != entity.HoldExpiredReason {
+		t.Errorf("voided payment = %s %q, want %s %q", got.PaymentStatus, got.FailureReason, paymentEntity.StatusVoided, entity.HoldExpiredReason)
+	}
+
+	if captured.Status != entity.StatusPending {
+		t.Errorf("hold whose capture went through = %s, want it kept %s for the webhook", captured.Status, entity.StatusPending)
+	}
+	if got := payments.payments[captured.PaymentID].PaymentStatus; got != paymentEntity.StatusAuthorized {
+		t.Errorf("captured payment = %s, want %s", got, paymentEntity.StatusAuthorized)
+	}
+}
\ No newline at end of file
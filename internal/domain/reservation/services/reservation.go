@@ -2,38 +2,63 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	paymentRepository "github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment/repository"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment"
+	paymentEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment/entity"
+	pricingEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/entity"
+	pricingServices "github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/services"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/entity"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/repository"
 	roomEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/entity"
 	roomRepository "github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/repository"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/money"
 	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils"
 	"github.com/google/uuid"
+	"strconv"
+	"time"
 )
 
 type ReservationService interface {
 	CreateReservation(ctx context.Context, reservation *entity.Reservation) (*entity.Reservation, error)
 	UpdateReservation(ctx context.Context, reservation *entity.Reservation) (*entity.Reservation, error)
+	CancelReservation(ctx context.Context, actor entity.Actor, id uuid.UUID) (*entity.Cancellation, error)
+	ModifyReservation(ctx context.Context, actor entity.Actor, id uuid.UUID, modification entity.Modification) (*entity.ModificationResult, error)
+	CheckIn(ctx context.Context, actor entity.Actor, id uuid.UUID) (*entity.Reservation, error)
+	CheckOut(ctx context.Context, actor entity.Actor, id uuid.UUID) (*entity.Reservation, error)
+	MarkNoShow(ctx context.Context, actor entity.Actor, id uuid.UUID) (*entity.Reservation, error)
 
 	GetUserReservations(ctx context.Context, userID uuid.UUID) ([]*entity.Reservation, error)
-	GetReservation(ctx context.Context, id uuid.UUID) (*entity.Reservation, error)
+	GetReservation(ctx context.Context, actor entity.Actor, id uuid.UUID) (*entity.Reservation, error)
+	GetReservationHistory(ctx context.Context, actor entity.Actor, id uuid.UUID) ([]*entity.Change, error)
 	GetRoomByNumber(ctx context.Context, roomNumber int) (*roomEntity.Room, error)
 
+	CreateBooking(ctx context.Context, booking *entity.Booking) (*entity.Booking, error)
+	GetBooking(ctx context.Context, actor entity.Actor, id uuid.UUID) (*entity.Booking, error)
+	CancelBooking(ctx context.Context, actor entity.Actor, id uuid.UUID) (*entity.BookingCancellation, error)
+
+	LookupReservation(ctx context.Context, code, lastName, email string) (*entity.ReservationSummary, error)
+
 	ValidateReservation(ctx context.Context, reservation *entity.Reservation) error
 }
 
 type ReservationServiceImpl struct {
 	reservationRepo repository.ReservationRepository
 	roomRepo        roomRepository.RoomRepository
-	paymentRepo     paymentRepository.PaymentRepository
+	paymentService  payment.Service
+	pricingService  pricingServices.PricingService
+	holdTTL         time.Duration
 }
 
-func NewReservationService(reservationRepo repository.ReservationRepository, roomRepo roomRepository.RoomRepository, paymentRepo paymentRepository.PaymentRepository) *ReservationServiceImpl {
+// NewReservationService : holdTTL is how long a new reservation keeps its room while its payment is outstanding.
+// Zero disables holds
+func NewReservationService(reservationRepo repository.ReservationRepository, roomRepo roomRepository.RoomRepository, paymentService payment.Service, pricingService pricingServices.PricingService, holdTTL time.Duration) *ReservationServiceImpl {
 	return &ReservationServiceImpl{
 		reservationRepo: reservationRepo,
 		roomRepo:        roomRepo,
-		paymentRepo:     paymentRepo,
+		paymentService:  paymentService,
+		pricingService:  pricingService,
+		holdTTL:         holdTTL,
 	}
 }
 
@@ -42,44 +67,171 @@ func (r *ReservationServiceImpl) CreateReservation(ctx context.Context, reservat
 		return nil, err
 	}
 
-	// Create the payment record
-	if err := r.paymentRepo.Create(ctx, &reservation.Payment); err != nil {
-		return nil, fmt.Errorf("failed to create payment: %w", err)
+	// Price the stay on the server, whatever the client sent
+	if err := r.priceReservation(ctx, reservation); err != nil {
+		return nil, err
 	}
 
-	// Create the reservation
-	if err := r.reservationRepo.Create(ctx, reservation); err != nil {
+	// Persist payment & reservation atomically, holding the room until the payment settles
+	r.hold(reservation, reservation.Payment.PaymentMethod)
+	if err := r.reservationRepo.Book(ctx, reservation); err != nil {
 		return nil, fmt.Errorf("failed to create reservation: %w", err)
 	}
 
+	// Charge the guest & settle the reservation on the gateway's answer
+	if err := r.settlePayment(ctx, &reservation.Payment, reservation); err != nil {
+		return nil, err
+	}
+
 	// Fetch the created reservation with preloaded associations
 	createdReservation, err := r.reservationRepo.GetByID(ctx, reservation.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get created reservation: %w", err)
 	}
 
-	// Send email notification
-	go func() {
-		reservationData := utils.ReservationEmailData{
-			ID:            createdReservation.ID.String(),
-			CheckInDate:   createdReservation.CheckInDate,
-			CheckOutDate:  createdReservation.CheckOutDate,
-			RoomNumber:    createdReservation.Room.RoomNumber,
-			RoomType:      createdReservation.Room.RoomType.Name,
-			GuestName:     fmt.Sprintf("%s %s", createdReservation.User.FirstName, createdReservation.User.LastName),
-			TotalPrice:    createdReservation.TotalPrice,
-			PaymentStatus: string(createdReservation.Payment.PaymentStatus),
-		}
-
-		err := utils.SendEmailNotification(createdReservation.User.Email, reservationData)
-		if err != nil {
-			fmt.Println("Failed to send email notification:", err)
-		}
-	}()
+	go sendConfirmation(createdReservation)
 
 	return createdReservation, nil
 }
 
+// CreateBooking books every reservation of the booking, all or none, and charges them together with the booking's payment.
+// Each reservation is validated & priced like a single one; the payment is for the sum of their prices
+func (r *ReservationServiceImpl) CreateBooking(ctx context.Context, booking *entity.Booking) (*entity.Booking, error) {
+	var total money.Money
+	for _, reservation := range booking.Reservations {
+		if err := r.ValidateReservation(ctx, reservation); err != nil {
+			return nil, err
+		}
+		if err := r.priceReservation(ctx, reservation); err != nil {
+			return nil, err
+		}
+		total = total.Add(reservation.TotalPrice)
+
+		reservation.UserID = booking.UserID
+		r.hold(reservation, booking.Payment.PaymentMethod)
+	}
+	booking.TotalPrice = total
+	booking.Payment.Amount = total
+	booking.Payment.Currency = total.Currency()
+
+	if err := r.reservationRepo.CreateBooking(ctx, booking); err != nil {
+		return nil, fmt.Errorf("failed to create booking: %w", err)
+	}
+
+	if err := r.settlePayment(ctx, &booking.Payment, booking.Reservations...); err != nil {
+		return nil, err
+	}
+
+	createdBooking, err := r.reservationRepo.GetBooking(ctx, booking.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get created booking: %w", err)
+	}
+
+	for _, reservation := range createdBooking.Reservations {
+		go sendConfirmation(reservation)
+	}
+
+	return createdBooking, nil
+}
+
+// hold marks a new reservation PENDING and keeps its room for holdTTL while the payment is outstanding.
+// Cash is settled at the front desk, so cash bookings are not held
+func (r *ReservationServiceImpl) hold(reservation *entity.Reservation, method paymentEntity.Method) {
+	reservation.Status = entity.StatusPending
+	if r.holdTTL > 0 && method != paymentEntity.MethodCash {
+		holdExpiresAt := time.Now().Add(r.holdTTL)
+		reservation.HoldExpiresAt = &holdExpiresAt
+	}
+}
+
+// sendConfirmation emails the guest the details of their new reservation
+func sendConfirmation(reservation *entity.Reservation) {
+	reservationData := utils.ReservationEmailData{
+		ID:            reservation.ID.String(),
+		Code:          reservation.Code,
+		CheckInDate:   reservation.CheckInDate,
+		CheckOutDate:  reservation.CheckOutDate,
+		RoomNumber:    roomNumber(reservation),
+		RoomType:      reservation.RoomTypeName(),
+		GuestName:     fmt.Sprintf("%s %s", reservation.User.FirstName, reservation.User.LastName),
+		TotalPrice:    reservation.TotalPrice,
+		PaymentStatus: string(reservation.Payment.PaymentStatus),
+	}
+
+	err := utils.SendEmailNotification(reservation.User.Email, reservationData)
+	if err != nil {
+		fmt.Println("Failed to send email notification:", err)
+	}
+}
+
+// settlePayment processes the payment of booked reservations and moves the reservations along with it:
+// a captured payment confirms them, a declined one cancels them (releasing their rooms).
+// A gateway timeout or a cash payment leaves them all PENDING until the payment is settled later
+func (r *ReservationServiceImpl) settlePayment(ctx context.Context, charge *paymentEntity.Payment, reservations ...*entity.Reservation) error {
+	err := r.paymentService.ProcessPayment(ctx, charge)
+	switch {
+	case errors.Is(err, payment.ErrPaymentDeclined):
+		for _, reservation := range reservations {
+			if statusErr := r.transition(ctx, entity.SystemActor, reservation, entity.StatusCancelled); statusErr != nil {
+				return statusErr
+			}
+		}
+		return err
+	case errors.Is(err, payment.ErrGatewayTimeout):
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to process payment: %w", err)
+	}
+
+	if charge.PaymentStatus == paymentEntity.StatusSuccess {
+		for _, reservation := range reservations {
+			if err := r.transition(ctx, entity.SystemActor, reservation, entity.StatusConfirmed); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// priceReservation quotes the stay for the reserved room type and stores the quote on the reservation & its payment
+func (r *ReservationServiceImpl) priceReservation(ctx context.Context, reservation *entity.Reservation) error {
+	quote, err := r.pricingService.Quote(ctx, reservation.RoomTypeID, reservation.CheckInDate, reservation.CheckOutDate, reservation.NumGuests)
+	if err != nil {
+		return fmt.Errorf("failed to price reservation: %w", err)
+	}
+
+	reservation.TotalPrice = quote.Total
+	reservation.LineItems = lineItemsFromQuote(quote)
+	reservation.Payment.Amount = quote.Total
+	reservation.Payment.Currency = quote.Currency
+	return nil
+}
+
+// lineItemsFromQuote flattens a quote into one line item per night and charge type
+func lineItemsFromQuote(quote *pricingEntity.Quote) []entity.LineItem {
+	var items []entity.LineItem
+	for _, night := range quote.Nightly {
+		items = append(items, entity.LineItem{
+			NightOf:    night.Date,
+			ChargeType: entity.ChargeBaseRate,
+			Quantity:   1,
+			UnitPrice:  night.BaseRate,
+			Amount:     night.BaseRate,
+			RatePlanID: night.RatePlanID,
+		})
+		if night.ExtraGuests > 0 {
+			items = append(items, entity.LineItem{
+				NightOf:    night.Date,
+				ChargeType: entity.ChargeExtraGuest,
+				Quantity:   night.ExtraGuests,
+				UnitPrice:  night.ExtraGuestRate,
+				Amount:     night.ExtraGuestCharge,
+			})
+		}
+	}
+	return items
+}
+
 func (r *ReservationServiceImpl) UpdateReservation(ctx context.Context, reservation *entity.Reservation) (*entity.Reservation, error) {
 	updatedReservation, err := r.reservationRepo.Update(ctx, reservation)
 	if err != nil {
@@ -88,6 +240,374 @@ func (r *ReservationServiceImpl) UpdateReservation(ctx context.Context, reservat
 	return updatedReservation, nil
 }
 
+// CancelReservation cancels a pending or confirmed reservation, charging the fee of the applicable cancellation policy
+// and refunding the rest of what the guest has paid. A refund that fails leaves the reservation cancelled, with the refund due
+// recorded in its history
+func (r *ReservationServiceImpl) CancelReservation(ctx context.Context, actor entity.Actor, id uuid.UUID) (*entity.Cancellation, error) {
+	reservation, err := r.GetReservation(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+	return r.cancel(ctx, actor, reservation)
+}
+
+// cancel cancels a reservation the actor may access, see CancelReservation
+func (r *ReservationServiceImpl) cancel(ctx context.Context, actor entity.Actor, reservation *entity.Reservation) (*entity.Cancellation, error) {
+	if reservation.Status == entity.StatusCancelled {
+		return nil, entity.ErrAlreadyCancelled
+	}
+	if !reservation.Status.CanTransitionTo(entity.StatusCancelled) {
+		return nil, entity.ErrNotCancellable
+	}
+
+	firstNight, ratePlanID := reservation.FirstNight()
+	fee, err := r.pricingService.CancellationFee(ctx, pricingEntity.CancellationRequest{
+		RoomTypeID:  reservation.RoomTypeID,
+		RatePlanID:  ratePlanID,
+		CheckIn:     reservation.CheckInDate,
+		CancelledAt: time.Now(),
+		Total:       reservation.TotalPrice,
+		FirstNight:  firstNight,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The reservation is cancelled before any money moves, so a refund is never sent for a cancellation that did not happen
+	if err := r.transition(ctx, actor, reservation, entity.StatusCancelled); err != nil {
+		return nil, fmt.Errorf("failed to cancel reservation: %w", err)
+	}
+
+	// The guest gets back what they were charged, net of adjustments & earlier refunds, less the fee:
+	// a modified stay is settled on what was actually paid for it.
+	// The payment of a booking is shared with its other rooms, so only this room's part of it can go back
+	refund := reservation.Payment.Refundable().Sub(fee.Fee)
+	if reservation.BookingID != nil {
+		refund = money.Min(fee.Refundable, reservation.Payment.Refundable())
+	}
+	refunded := money.Zero(refund.Currency())
+	refundDue := money.Zero(refund.Currency())
+	if refund.IsPositive() {
+		payment, err := r.paymentService.RefundPayment(ctx, reservation.PaymentID, refund)
+		if err != nil {
+			// the cancellation stands: the refund owed is recorded in the reservation's history for staff to settle
+			refundDue = refund
+			r.recordFailedRefund(ctx, actor, reservation, refund, err)
+		} else {
+			reservation.Payment = *payment
+			refunded = refund
+		}
+	}
+
+	// Send email notification
+	go func() {
+		cancellationData := utils.CancellationEmailData{
+			ID:            reservation.ID.String(),
+			CheckInDate:   reservation.CheckInDate,
+			CheckOutDate:  reservation.CheckOutDate,
+			RoomType:      reservation.RoomTypeName(),
+			GuestName:     fmt.Sprintf("%s %s", reservation.User.FirstName, reservation.User.LastName),
+			PolicyName:    fee.PolicyName,
+			TotalPrice:    fee.Total,
+			Fee:           fee.Fee,
+			Refunded:      refunded,
+			PaymentStatus: string(reservation.Payment.PaymentStatus),
+		}
+
+		err := utils.SendCancellationEmail(reservation.User.Email, cancellationData)
+		if err != nil {
+			fmt.Println("Failed to send cancellation email:", err)
+		}
+	}()
+
+	return &entity.Cancellation{
+		Reservation: reservation,
+		Fee:         fee,
+		Refunded:    refunded,
+		RefundDue:   refundDue,
+	}, nil
+}
+
+// recordFailedRefund records in the reservation's history the refund owed to its guest that the payment service could not make
+func (r *ReservationServiceImpl) recordFailedRefund(ctx context.Context, actor entity.Actor, reservation *entity.Reservation, refund money.Money, refundErr error) {
+	fmt.Printf("Failed to refund cancelled reservation %s: %v\n", reservation.ID, refundErr)
+	change, err := entity.NewChange(actor, reservation.ID, entity.ActionRefundFailed, map[string]entity.FieldChange{
+		"refund_due": {From: money.Zero(refund.Currency()), To: refund},
+	})
+	if err == nil {
+		err = r.reservationRepo.AddChange(ctx, change)
+	}
+	if err != nil {
+		fmt.Println("Failed to record the failed refund:", err)
+	}
+}
+
+// GetBooking returns a booking the actor may access.
+// Other guests' bookings are reported as entity.ErrBookingNotFound, exactly like missing ones
+func (r *ReservationServiceImpl) GetBooking(ctx context.Context, actor entity.Actor, id uuid.UUID) (*entity.Booking, error) {
+	booking, err := r.reservationRepo.GetBooking(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booking: %w", err)
+	}
+	if !actor.CanAccessBooking(booking) {
+		return nil, entity.ErrBookingNotFound
+	}
+	return booking, nil
+}
+
+// LookupReservation finds a reservation by its code for someone who is not logged in.
+// A wrong last name or email is reported like an unknown code, so the answer never confirms that a code exists
+func (r *ReservationServiceImpl) LookupReservation(ctx context.Context, code, lastName, email string) (*entity.ReservationSummary, error) {
+	reservation, err := r.reservationRepo.GetByCode(ctx, utils.NormalizeCode(code))
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up reservation: %w", err)
+	}
+	if !reservation.BelongsTo(lastName, email) {
+		return nil, entity.ErrReservationNotFound
+	}
+	return reservation.Summary(), nil
+}
+
+// CancelBooking cancels every room of a booking that can still be cancelled, each under its own cancellation policy
+// as if cancelled on its own. Rooms already cancelled, or whose stay has started, are left as they are
+func (r *ReservationServiceImpl) CancelBooking(ctx context.Context, actor entity.Actor, id uuid.UUID) (*entity.BookingCancellation, error) {
+	booking, err := r.GetBooking(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entity.BookingCancellation{Booking: booking, Cancellations: []*entity.Cancellation{}}
+	result.Refunded = money.Zero(booking.Payment.Currency)
+	result.RefundDue = money.Zero(booking.Payment.Currency)
+	cancelled := 0
+	for _, reservation := range booking.Reservations {
+		if reservation.Status == entity.StatusCancelled {
+			cancelled++
+			continue
+		}
+		if !reservation.Status.CanTransitionTo(entity.StatusCancelled) {
+			continue
+		}
+
+		cancellation, err := r.cancel(ctx, actor, reservation)
+		if err != nil {
+			return nil, fmt.Errorf("failed to cancel reservation %s: %w", reservation.ID, err)
+		}
+		result.Cancellations = append(result.Cancellations, cancellation)
+		result.Refunded = result.Refunded.Add(cancellation.Refunded)
+		result.RefundDue = result.RefundDue.Add(cancellation.RefundDue)
+	}
+
+	if len(result.Cancellations) == 0 {
+		if cancelled == len(booking.Reservations) {
+			return nil, entity.ErrAlreadyCancelled
+		}
+		return nil, entity.ErrNotCancellable
+	}
+	return result, nil
+}
+
+// CheckIn starts the stay of a pending or confirmed reservation. It is only possible from the check-in date
+// until the day before check-out
+func (r *ReservationServiceImpl) CheckIn(ctx context.Context, actor entity.Actor, id uuid.UUID) (*entity.Reservation, error) {
+	return r.changeStatus(ctx, actor, id, entity.StatusInProgress, func(reservation *entity.Reservation, now time.Time) error {
+		if now.Before(reservation.CheckInDate) || !now.Before(reservation.CheckOutDate) {
+			return entity.ErrOutsideStay
+		}
+		if !reservation.IsAssigned() {
+			return entity.ErrRoomNotAssigned
+		}
+		return nil
+	})
+}
+
+// CheckOut completes the stay of a checked-in reservation
+func (r *ReservationServiceImpl) CheckOut(ctx context.Context, actor entity.Actor, id uuid.UUID) (*entity.Reservation, error) {
+	return r.changeStatus(ctx, actor, id, entity.StatusCompleted, nil)
+}
+
+// MarkNoShow closes a reservation whose guest never arrived. It is only possible from the check-in date
+func (r *ReservationServiceImpl) MarkNoShow(ctx context.Context, actor entity.Actor, id uuid.UUID) (*entity.Reservation, error) {
+	return r.changeStatus(ctx, actor, id, entity.StatusNoShow, func(reservation *entity.Reservation, now time.Time) error {
+		if now.Before(reservation.CheckInDate) {
+			return entity.ErrBeforeCheckIn
+		}
+		return nil
+	})
+}
+
+// changeStatus moves a reservation the actor may access to the next state, once the optional guard agrees
+func (r *ReservationServiceImpl) changeStatus(ctx context.Context, actor entity.Actor, id uuid.UUID, next entity.Status, guard func(*entity.Reservation, time.Time) error) (*entity.Reservation, error) {
+	reservation, err := r.GetReservation(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := reservation.CheckTransition(next); err != nil {
+		return nil, err
+	}
+	if guard != nil {
+		if err := guard(reservation, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.transition(ctx, actor, reservation, next); err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// transition moves a reservation through the state machine and records who moved it in its history
+func (r *ReservationServiceImpl) transition(ctx context.Context, actor entity.Actor, reservation *entity.Reservation, next entity.Status) error {
+	if err := reservation.CheckTransition(next); err != nil {
+		return err
+	}
+	change, err := entity.NewTransition(actor, reservation.ID, reservation.Status, next)
+	if err != nil {
+		return fmt.Errorf("failed to record reservation change: %w", err)
+	}
+	if err := r.reservationRepo.Transition(ctx, reservation.ID, reservation.Status, next, change); err != nil {
+		return err
+	}
+	reservation.Status = next
+	return nil
+}
+
+// ModifyReservation changes the dates, room or guest count of a pending or confirmed reservation.
+// The new stay is checked for availability & re-priced exactly like a new booking, the price difference
+// is charged or refunded on the payment, and the change is recorded in the reservation's history.
+// A difference that cannot be settled leaves the reservation as it was
+func (r *ReservationServiceImpl) ModifyReservation(ctx context.Context, actor entity.Actor, id uuid.UUID, modification entity.Modification) (*entity.ModificationResult, error) {
+	original, err := r.GetReservation(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+	if original.Status != entity.StatusPending && original.Status != entity.StatusConfirmed {
+		return nil, entity.ErrNotModifiable
+	}
+
+	updated := *original
+	updated.LineItems = nil
+	if modification.CheckIn != nil {
+		updated.CheckInDate = *modification.CheckIn
+	}
+	if modification.CheckOut != nil {
+		updated.CheckOutDate = *modification.CheckOut
+	}
+	if modification.NumGuests != nil {
+		updated.NumGuests = *modification.NumGuests
+	}
+	if !updated.CheckOutDate.After(updated.CheckInDate) {
+		return nil, pricingEntity.ErrInvalidStay
+	}
+
+	switch {
+	case modification.RoomID != nil:
+		// the room decides the type, and ValidateReservation rejects a room that is not of a requested one
+		updated.RoomID = modification.RoomID
+		updated.RoomTypeID = uuid.Nil
+		if modification.RoomTypeID != nil {
+			updated.RoomTypeID = *modification.RoomTypeID
+		}
+	case modification.RoomTypeID != nil && *modification.RoomTypeID != original.RoomTypeID:
+		updated.RoomTypeID = *modification.RoomTypeID
+		// a reservation already in a room moves to a free room of the new type, a room type booking stays unassigned
+		if original.IsAssigned() {
+			room, err := r.findFreeRoom(ctx, *modification.RoomTypeID, &updated)
+			if err != nil {
+				return nil, err
+			}
+			updated.RoomID = &room.ID
+		}
+	}
+
+	if err := r.ValidateReservation(ctx, &updated); err != nil {
+		return nil, err
+	}
+	if err := r.priceReservation(ctx, &updated); err != nil {
+		return nil, err
+	}
+
+	fields := original.Diff(&updated)
+	if len(fields) == 0 {
+		return nil, entity.ErrNoChanges
+	}
+	change, err := entity.NewChange(actor, original.ID, entity.ActionModified, fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record reservation change: %w", err)
+	}
+
+	if err := r.reservationRepo.Modify(ctx, &updated, change); err != nil {
+		return nil, fmt.Errorf("failed to modify reservation: %w", err)
+	}
+
+	// The new stay is only kept once its price difference is settled (or left pending with the gateway):
+	// when the charge or refund fails, or is declined, the old stay is put back
+	difference := updated.TotalPrice.Sub(original.TotalPrice)
+	adjustment, err := r.paymentService.AdjustPayment(ctx, original.PaymentID, difference, "reservation modified")
+	if err == nil && adjustment != nil && adjustment.Status == paymentEntity.StatusFailed {
+		err = fmt.Errorf("%w: the price difference could not be charged", payment.ErrPaymentDeclined)
+	}
+	if err != nil {
+		if revertErr := r.revertModification(ctx, original, &updated); revertErr != nil {
+			return nil, fmt.Errorf("reservation modified but the price difference could not be settled (%v) nor the modification undone: %w", err, revertErr)
+		}
+		return nil, fmt.Errorf("reservation not modified: %w", err)
+	}
+
+	modified, err := r.reservationRepo.GetByID(ctx, original.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get modified reservation: %w", err)
+	}
+	return &entity.ModificationResult{
+		Reservation:     modified,
+		PriceDifference: difference,
+		Adjustment:      adjustment,
+	}, nil
+}
+
+// revertModification puts back the stay a modification replaced, recording it in the reservation's history as a change by the system
+func (r *ReservationServiceImpl) revertModification(ctx context.Context, original, modified *entity.Reservation) error {
+	change, err := entity.NewChange(entity.SystemActor, original.ID, entity.ActionModified, modified.Diff(original))
+	if err != nil {
+		return fmt.Errorf("failed to record reservation change: %w", err)
+	}
+	return r.reservationRepo.Modify(ctx, original, change)
+}
+
+// findFreeRoom picks a room of the given type that is in service and free for the reservation's dates
+func (r *ReservationServiceImpl) findFreeRoom(ctx context.Context, roomTypeID uuid.UUID, reservation *entity.Reservation) (*roomEntity.Room, error) {
+	rooms, err := r.roomRepo.GetRooms(ctx, map[string]interface{}{"room_type_id": roomTypeID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rooms of that type: %w", err)
+	}
+	conflicting, err := r.reservationRepo.GetByDateRange(ctx, reservation.CheckInDate, reservation.CheckOutDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check room availability: %w", err)
+	}
+
+	busy := make(map[uuid.UUID]bool)
+	for _, existing := range conflicting {
+		if existing.ID != reservation.ID && existing.IsAssigned() {
+			busy[*existing.RoomID] = true
+		}
+	}
+	for _, room := range rooms {
+		if room.Status != roomEntity.UnderMaintenance && !busy[room.ID] {
+			return room, nil
+		}
+	}
+	return nil, entity.ErrRoomUnavailable
+}
+
+// GetReservationHistory returns the recorded changes of a reservation the actor may access
+func (r *ReservationServiceImpl) GetReservationHistory(ctx context.Context, actor entity.Actor, id uuid.UUID) ([]*entity.Change, error) {
+	if _, err := r.GetReservation(ctx, actor, id); err != nil {
+		return nil, err
+	}
+	return r.reservationRepo.GetChanges(ctx, id)
+}
+
 func (r *ReservationServiceImpl) GetUserReservations(ctx context.Context, userID uuid.UUID) ([]*entity.Reservation, error) {
 	reservations, err := r.reservationRepo.GetByUserID(ctx, userID)
 	if err != nil {
@@ -96,11 +616,16 @@ func (r *ReservationServiceImpl) GetUserReservations(ctx context.Context, userID
 	return reservations, nil
 }
 
-func (r *ReservationServiceImpl) GetReservation(ctx context.Context, id uuid.UUID) (*entity.Reservation, error) {
+// GetReservation returns a reservation the actor may access.
+// Other guests' reservations are reported as entity.ErrReservationNotFound, exactly like missing ones
+func (r *ReservationServiceImpl) GetReservation(ctx context.Context, actor entity.Actor, id uuid.UUID) (*entity.Reservation, error) {
 	reservation, err := r.reservationRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get reservation: %w", err)
 	}
+	if !actor.CanAccess(reservation) {
+		return nil, entity.ErrReservationNotFound
+	}
 	return reservation, nil
 }
 
@@ -116,28 +641,55 @@ func (r *ReservationServiceImpl) GetRoomByNumber(ctx context.Context, roomNumber
 	return rooms[0], nil
 }
 
+// ValidateReservation checks that the reservation's room, when one is assigned, is in service and free for the stay,
+// and that a room of the booked type is left on every night. The room type of an assigned reservation is taken from its room
 func (r *ReservationServiceImpl) ValidateReservation(ctx context.Context, reservation *entity.Reservation) error {
-	conflictingReservations, err := r.reservationRepo.GetByDateRange(ctx, reservation.CheckInDate, reservation.CheckOutDate)
-	if err != nil {
-		return fmt.Errorf("failed to check room availability: %w", err)
-	}
+	if reservation.IsAssigned() {
+		// Check if room exists and has not been marked under maintenance
+		rooms, err := r.roomRepo.GetRooms(ctx, map[string]interface{}{"id": *reservation.RoomID})
+		if err != nil || len(rooms) == 0 {
+			return fmt.Errorf("failed to get room with that ID: %v", err)
+		}
 
-	for _, existing := range conflictingReservations {
-		if existing.RoomID == reservation.RoomID && existing.ID != reservation.ID {
-			return fmt.Errorf("room is not available for the selected dates, already booked/it might have been booked before you reserved it")
+		room := rooms[0]
+		if room.Status == roomEntity.UnderMaintenance {
+			return fmt.Errorf("%w: room is under maintenance", entity.ErrRoomUnavailable)
+		}
+		if reservation.RoomTypeID == uuid.Nil {
+			reservation.RoomTypeID = room.RoomTypeID
+		}
+		if room.RoomTypeID != reservation.RoomTypeID {
+			return fmt.Errorf("%w: room is not of the requested type", entity.ErrRoomUnavailable)
 		}
-	}
 
-	// Check if room exists and has not been marked under maintenance
-	rooms, err := r.roomRepo.GetRooms(ctx, map[string]interface{}{"id": reservation.RoomID})
-	if err != nil || len(rooms) == 0 {
-		return fmt.Errorf("failed to get room with that ID: %v", err)
+		conflictingReservations, err := r.reservationRepo.GetByDateRange(ctx, reservation.CheckInDate, reservation.CheckOutDate)
+		if err != nil {
+			return fmt.Errorf("failed to check room availability: %w", err)
+		}
+		for _, existing := range conflictingReservations {
+			if existing.IsAssigned() && *existing.RoomID == *reservation.RoomID && existing.ID != reservation.ID &&
+				existing.Overlaps(reservation.CheckInDate, reservation.CheckOutDate) {
+				return entity.ErrRoomUnavailable
+			}
+		}
 	}
 
-	room := rooms[0]
-	if room.Status == "under_maintenance" {
-		return fmt.Errorf("room is under maintenance")
+	// Rooms of the type booked without a room take part too, so count what is left night by night
+	free, err := r.reservationRepo.CountFreeRooms(ctx, reservation.RoomTypeID, reservation.CheckInDate, reservation.CheckOutDate, reservation.ID, reservation.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to check room availability: %w", err)
+	}
+	if free == 0 {
+		return fmt.Errorf("%w: no rooms of that type left", entity.ErrRoomUnavailable)
 	}
 
 	return nil
+}
+
+// roomNumber describes the reservation's room to the guest. Room type bookings get their room allocated later
+func roomNumber(reservation *entity.Reservation) string {
+	if reservation.Room == nil {
+		return "Assigned at check-in"
+	}
+	return strconv.Itoa(reservation.Room.RoomNumber)
 }
\ No newline at end of file
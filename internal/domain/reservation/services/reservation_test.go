@@ -0,0 +1,829 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gatimugabriel/hotel-reservation-system/internal/constants"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment"
+	paymentEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment/entity"
+	paymentRepository "github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment/repository"
+	pricingEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/repository"
+	roomEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/entity"
+	userEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/money"
+	"github.com/google/uuid"
+)
+
+// memoryReservations : in-memory ReservationRepository. Methods the tests do not need panic through the nil embedded interface.
+// Reservations are read with their payment from payments, when set, and capacity holds the number of rooms of each type
+type memoryReservations struct {
+	repository.ReservationRepository
+	reservations map[uuid.UUID]*entity.Reservation
+	bookings     map[uuid.UUID]*entity.Booking
+	changes      []*entity.Change
+	payments     *memoryPayments
+	capacity     map[uuid.UUID]int
+}
+
+func newMemoryReservations(reservations ...*entity.Reservation) *memoryReservations {
+	m := &memoryReservations{
+		reservations: make(map[uuid.UUID]*entity.Reservation),
+		bookings:     make(map[uuid.UUID]*entity.Booking),
+	}
+	for _, reservation := range reservations {
+		m.reservations[reservation.ID] = reservation
+	}
+	return m
+}
+
+func (m *memoryReservations) GetByID(ctx context.Context, id uuid.UUID) (*entity.Reservation, error) {
+	reservation, ok := m.reservations[id]
+	if !ok {
+		return nil, entity.ErrReservationNotFound
+	}
+	copied := *reservation
+	if m.payments != nil {
+		if payment, err := m.payments.GetByID(ctx, reservation.PaymentID); err == nil {
+			copied.Payment = *payment
+		}
+	}
+	return &copied, nil
+}
+
+func (m *memoryReservations) Transition(ctx context.Context, id uuid.UUID, from, to entity.Status, change *entity.Change) error {
+	reservation := m.reservations[id]
+	if reservation.Status != from {
+		return &entity.InvalidTransitionError{From: from, To: to}
+	}
+	reservation.Status = to
+	m.changes = append(m.changes, change)
+	return nil
+}
+
+func (m *memoryReservations) AddChange(ctx context.Context, change *entity.Change) error {
+	m.changes = append(m.changes, change)
+	return nil
+}
+
+// Modify stores the new stay, refusing it like the database would when its room is taken
+func (m *memoryReservations) Modify(ctx context.Context, reservation *entity.Reservation, change *entity.Change) error {
+	stored := m.reservations[reservation.ID]
+	if stored.Status != entity.StatusPending && stored.Status != entity.StatusConfirmed {
+		return entity.ErrNotModifiable
+	}
+	for _, other := range m.reservations {
+		if other.ID != reservation.ID && reservation.IsAssigned() && other.IsAssigned() && *other.RoomID == *reservation.RoomID &&
+			other.Overlaps(reservation.CheckInDate, reservation.CheckOutDate) {
+			return entity.ErrRoomUnavailable
+		}
+	}
+
+	stored.RoomTypeID, stored.RoomID = reservation.RoomTypeID, reservation.RoomID
+	stored.CheckInDate, stored.CheckOutDate = reservation.CheckInDate, reservation.CheckOutDate
+	stored.NumGuests, stored.TotalPrice, stored.LineItems = reservation.NumGuests, reservation.TotalPrice, reservation.LineItems
+	m.changes = append(m.changes, change)
+	return nil
+}
+
+// CountFreeRooms counts the rooms of the type that no other reservation holds for any part of the stay
+func (m *memoryReservations) CountFreeRooms(ctx context.Context, roomTypeID uuid.UUID, checkIn, checkOut time.Time, exclude, guest uuid.UUID) (int, error) {
+	free := m.capacity[roomTypeID]
+	for _, other := range m.reservations {
+		if other.ID != exclude && other.RoomTypeID == roomTypeID && other.Overlaps(checkIn, checkOut) {
+			free--
+		}
+	}
+	return max(free, 0), nil
+}
+
+func (m *memoryReservations) GetByCode(ctx context.Context, code string) (*entity.Reservation, error) {
+	for _, reservation := range m.reservations {
+		if reservation.Code == code {
+			copied := *reservation
+			return &copied, nil
+		}
+	}
+	return nil, entity.ErrReservationNotFound
+}
+
+// GetBooking returns the booking with the stored reservations that belong to it
+func (m *memoryReservations) GetBooking(ctx context.Context, id uuid.UUID) (*entity.Booking, error) {
+	booking, ok := m.bookings[id]
+	if !ok {
+		return nil, entity.ErrBookingNotFound
+	}
+	copied := *booking
+	copied.Reservations = nil
+	for _, reservation := range m.reservations {
+		if reservation.BookingID != nil && *reservation.BookingID == id {
+			r := *reservation
+			copied.Reservations = append(copied.Reservations, &r)
+		}
+	}
+	return &copied, nil
+}
+
+// memoryPayments : in-memory PaymentRepository, keeping the adjustments on their payment like the preloading repository
+type memoryPayments struct {
+	paymentRepository.PaymentRepository
+	payments map[uuid.UUID]*paymentEntity.Payment
+}
+
+func (m *memoryPayments) Create(ctx context.Context, payment *paymentEntity.Payment) error {
+	copied := *payment
+	m.payments[payment.ID] = &copied
+	return nil
+}
+
+func (m *memoryPayments) GetByID(ctx context.Context, id uuid.UUID) (*paymentEntity.Payment, error) {
+	payment, ok := m.payments[id]
+	if !ok {
+		return nil, paymentEntity.ErrPaymentNotFound
+	}
+	copied := *payment
+	copied.Adjustments = append([]paymentEntity.Adjustment(nil), payment.Adjustments...)
+	return &copied, nil
+}
+
+func (m *memoryPayments) Update(ctx context.Context, payment *paymentEntity.Payment) error {
+	return m.Create(ctx, payment)
+}
+
+func (m *memoryPayments) CreateAdjustment(ctx context.Context, adjustment *paymentEntity.Adjustment) error {
+	adjustment.ID = uuid.New()
+	payment := m.payments[adjustment.PaymentID]
+	payment.Adjustments = append(payment.Adjustments, *adjustment)
+	return nil
+}
+
+// flatPricing : PricingService charging each room type a flat nightly rate for up to maxGuests.
+// Cancelling costs the first night
+type flatPricing struct {
+	rates     map[uuid.UUID]money.Money
+	maxGuests int
+}
+
+func (p *flatPricing) Quote(ctx context.Context, roomTypeID uuid.UUID, checkIn, checkOut time.Time, guests int) (*pricingEntity.Quote, error) {
+	if !checkOut.After(checkIn) {
+		return nil, pricingEntity.ErrInvalidStay
+	}
+	if guests > p.maxGuests {
+		return nil, pricingEntity.ErrOccupancyExceeded
+	}
+
+	rate := p.rates[roomTypeID]
+	quote := &pricingEntity.Quote{RoomTypeID: roomTypeID, CheckIn: checkIn, CheckOut: checkOut, Guests: guests, Currency: rate.Currency()}
+	quote.Total = money.Zero(rate.Currency())
+	for night := checkIn; night.Before(checkOut); night = night.AddDate(0, 0, 1) {
+		quote.Nightly = append(quote.Nightly, pricingEntity.NightlyCharge{Date: night, BaseRate: rate, Total: rate})
+		quote.Total = quote.Total.Add(rate)
+	}
+	quote.Nights = len(quote.Nightly)
+	quote.BaseTotal = quote.Total
+	return quote, nil
+}
+
+func (p *flatPricing) CancellationFee(ctx context.Context, req pricingEntity.CancellationRequest) (*pricingEntity.CancellationFee, error) {
+	return &pricingEntity.CancellationFee{PolicyName: "First night", Total: req.Total, Fee: req.FirstNight, Refundable: req.Total.Sub(req.FirstNight)}, nil
+}
+
+// modificationFixture : a confirmed stay of three nights in standard room 101 at 100.00 a night, paid by card.
+// Standard rooms are 101 & 102; suite 201 costs 150.00 a night. Stays are for at most 2 guests
+type modificationFixture struct {
+	standard, suite uuid.UUID
+	rooms           map[int]*roomEntity.Room
+	reservation     *entity.Reservation
+	repo            *memoryReservations
+	payments        *memoryPayments
+	gateway         *payment.FakeGateway
+	service         *ReservationServiceImpl
+	guest           entity.Actor
+}
+
+func newModificationFixture(t *testing.T) *modificationFixture {
+	t.Helper()
+	f := &modificationFixture{
+		standard: uuid.New(),
+		suite:    uuid.New(),
+		rooms:    make(map[int]*roomEntity.Room),
+		payments: &memoryPayments{payments: make(map[uuid.UUID]*paymentEntity.Payment)},
+		gateway:  payment.NewFakeGateway(),
+		guest:    entity.Actor{UserID: uuid.New(), Role: constants.GUEST},
+	}
+	var rooms []*roomEntity.Room
+	for _, number := range []int{101, 102, 201} {
+		roomType := f.standard
+		if number == 201 {
+			roomType = f.suite
+		}
+		f.rooms[number] = &roomEntity.Room{ID: uuid.New(), RoomNumber: number, Status: roomEntity.Available, RoomTypeID: roomType}
+		rooms = append(rooms, f.rooms[number])
+	}
+	pricing := &flatPricing{
+		rates:     map[uuid.UUID]money.Money{f.standard: money.MustParse("100.00", "USD"), f.suite: money.MustParse("150.00", "USD")},
+		maxGuests: 2,
+	}
+	paymentService := payment.NewPaymentService(f.payments, f.gateway)
+
+	charge := &paymentEntity.Payment{
+		ID:            uuid.New(),
+		Amount:        money.MustParse("300.00", "USD"),
+		Currency:      "USD",
+		PaymentMethod: paymentEntity.MethodCreditCard,
+		PaymentStatus: paymentEntity.StatusPending,
+		TransactionID: uuid.NewString(),
+		UserID:        f.guest.UserID,
+	}
+	f.payments.Create(context.Background(), charge)
+	if err := paymentService.ProcessPayment(context.Background(), charge); err != nil {
+		t.Fatal(err)
+	}
+
+	f.reservation = &entity.Reservation{
+		ID:           uuid.New(),
+		UserID:       f.guest.UserID,
+		Status:       entity.StatusConfirmed,
+		RoomTypeID:   f.standard,
+		RoomID:       &f.rooms[101].ID,
+		CheckInDate:  time.Date(2030, time.May, 10, 0, 0, 0, 0, time.UTC),
+		CheckOutDate: time.Date(2030, time.May, 13, 0, 0, 0, 0, time.UTC),
+		NumGuests:    2,
+		TotalPrice:   charge.Amount,
+		PaymentID:    charge.ID,
+	}
+	f.repo = newMemoryReservations(f.reservation)
+	f.repo.payments = f.payments
+	f.repo.capacity = map[uuid.UUID]int{f.standard: 2, f.suite: 1}
+	f.service = NewReservationService(f.repo, &memoryRooms{rooms: rooms}, paymentService, pricing, 0)
+	return f
+}
+
+// charged is what the gateway holds of the guest's money for the stay: the payment and its adjustments, net of refunds
+func (f *modificationFixture) charged() money.Money {
+	stored := f.payments.payments[f.reservation.PaymentID]
+	charged := f.gateway.Captured(stored.GatewayReference)
+	for _, adjustment := range stored.Adjustments {
+		if adjustment.GatewayReference != "" {
+			charged = charged.Add(f.gateway.Captured(adjustment.GatewayReference))
+		}
+	}
+	return charged
+}
+
+func TestGetReservationAccess(t *testing.T) {
+	owner := uuid.New()
+	reservation := &entity.Reservation{ID: uuid.New(), UserID: owner, Status: entity.StatusConfirmed}
+	service := NewReservationService(newMemoryReservations(reservation), nil, nil, nil, 0)
+
+	tests := []struct {
+		name    string
+		actor   entity.Actor
+		wantErr error
+	}{
+		{"owning guest", entity.Actor{UserID: owner, Role: constants.GUEST}, nil},
+		{"other guest", entity.Actor{UserID: uuid.New(), Role: constants.GUEST}, entity.ErrReservationNotFound},
+		{"staff", entity.Actor{UserID: uuid.New(), Role: constants.STAFF}, nil},
+		{"manager", entity.Actor{UserID: uuid.New(), Role: constants.MANAGER}, nil},
+		{"admin", entity.Actor{UserID: uuid.New(), Role: constants.ADMIN}, nil},
+		{"property owner", entity.Actor{UserID: uuid.New(), Role: constants.PROPERTYOWNER}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := service.GetReservation(context.Background(), tt.actor, reservation.ID)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || got != nil {
+					t.Fatalf("GetReservation = %v, %v, want %v", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got.ID != reservation.ID {
+				t.Fatalf("GetReservation = %v, %v, want the reservation", got, err)
+			}
+		})
+	}
+
+	// a missing reservation looks exactly like someone else's
+	_, err := service.GetReservation(context.Background(), entity.Actor{UserID: owner, Role: constants.GUEST}, uuid.New())
+	if !errors.Is(err, entity.ErrReservationNotFound) {
+		t.Errorf("GetReservation of a missing ID error = %v, want %v", err, entity.ErrReservationNotFound)
+	}
+}
+
+func TestCancelReservationByOtherGuest(t *testing.T) {
+	reservation := &entity.Reservation{ID: uuid.New(), UserID: uuid.New(), Status: entity.StatusConfirmed}
+	repo := newMemoryReservations(reservation)
+	service := NewReservationService(repo, nil, nil, nil, 0)
+
+	intruder := entity.Actor{UserID: uuid.New(), Role: constants.GUEST}
+	if _, err := service.CancelReservation(context.Background(), intruder, reservation.ID); !errors.Is(err, entity.ErrReservationNotFound) {
+		t.Fatalf("CancelReservation error = %v, want %v", err, entity.ErrReservationNotFound)
+	}
+	if status := repo.reservations[reservation.ID].Status; status != entity.StatusConfirmed {
+		t.Errorf("reservation status = %s, want it left %s", status, entity.StatusConfirmed)
+	}
+}
+
+func TestCancelReservationNotCancellable(t *testing.T) {
+	reservation := &entity.Reservation{ID: uuid.New(), UserID: uuid.New(), Status: entity.StatusCancelled}
+	service := NewReservationService(newMemoryReservations(reservation), nil, nil, nil, 0)
+
+	staff := entity.Actor{UserID: uuid.New(), Role: constants.STAFF}
+	if _, err := service.CancelReservation(context.Background(), staff, reservation.ID); !errors.Is(err, entity.ErrAlreadyCancelled) {
+		t.Fatalf("CancelReservation error = %v, want %v", err, entity.ErrAlreadyCancelled)
+	}
+}
+
+func TestCheckInAndOut(t *testing.T) {
+	today := time.Now().Truncate(24 * time.Hour)
+	staff := entity.Actor{UserID: uuid.New(), Role: constants.STAFF}
+	reservation := &entity.Reservation{
+		ID:           uuid.New(),
+		UserID:       uuid.New(),
+		Status:       entity.StatusConfirmed,
+		CheckInDate:  today,
+		CheckOutDate: today.AddDate(0, 0, 2),
+	}
+	repo := newMemoryReservations(reservation)
+	service := NewReservationService(repo, nil, nil, nil, 0)
+
+	if _, err := service.CheckOut(context.Background(), staff, reservation.ID); !isInvalidTransition(err) {
+		t.Fatalf("CheckOut before check-in error = %v, want an InvalidTransitionError", err)
+	}
+	if _, err := service.CheckIn(context.Background(), staff, reservation.ID); !errors.Is(err, entity.ErrRoomNotAssigned) {
+		t.Fatalf("CheckIn without a room error = %v, want %v", err, entity.ErrRoomNotAssigned)
+	}
+
+	roomID := uuid.New()
+	reservation.RoomID = &roomID
+
+	checkedIn, err := service.CheckIn(context.Background(), staff, reservation.ID)
+	if err != nil || checkedIn.Status != entity.StatusInProgress {
+		t.Fatalf("CheckIn = %v, %v, want IN_PROGRESS", checkedIn, err)
+	}
+	if _, err := service.MarkNoShow(context.Background(), staff, reservation.ID); !isInvalidTransition(err) {
+		t.Fatalf("MarkNoShow after check-in error = %v, want an InvalidTransitionError", err)
+	}
+
+	checkedOut, err := service.CheckOut(context.Background(), staff, reservation.ID)
+	if err != nil || checkedOut.Status != entity.StatusCompleted {
+		t.Fatalf("CheckOut = %v, %v, want COMPLETED", checkedOut, err)
+	}
+
+	if len(repo.changes) != 2 {
+		t.Fatalf("recorded %d changes, want 2", len(repo.changes))
+	}
+	for _, change := range repo.changes {
+		if change.Action != entity.ActionStatusChanged || change.ChangedBy != staff.UserID || change.ChangedByRole != staff.Role {
+			t.Errorf("change = %+v, want a status change by the staff member", change)
+		}
+	}
+}
+
+func TestStayDateGuards(t *testing.T) {
+	today := time.Now().Truncate(24 * time.Hour)
+	staff := entity.Actor{UserID: uuid.New(), Role: constants.STAFF}
+	upcoming := &entity.Reservation{
+		ID:           uuid.New(),
+		Status:       entity.StatusConfirmed,
+		CheckInDate:  today.AddDate(0, 0, 3),
+		CheckOutDate: today.AddDate(0, 0, 5),
+	}
+	past := &entity.Reservation{
+		ID:           uuid.New(),
+		Status:       entity.StatusConfirmed,
+		CheckInDate:  today.AddDate(0, 0, -3),
+		CheckOutDate: today.AddDate(0, 0, -1),
+	}
+	service := NewReservationService(newMemoryReservations(upcoming, past), nil, nil, nil, 0)
+
+	if _, err := service.CheckIn(context.Background(), staff, upcoming.ID); !errors.Is(err, entity.ErrOutsideStay) {
+		t.Errorf("CheckIn before the check-in date error = %v, want %v", err, entity.ErrOutsideStay)
+	}
+	if _, err := service.CheckIn(context.Background(), staff, past.ID); !errors.Is(err, entity.ErrOutsideStay) {
+		t.Errorf("CheckIn after the check-out date error = %v, want %v", err, entity.ErrOutsideStay)
+	}
+	if _, err := service.MarkNoShow(context.Background(), staff, upcoming.ID); !errors.Is(err, entity.ErrBeforeCheckIn) {
+		t.Errorf("MarkNoShow before the check-in date error = %v, want %v", err, entity.ErrBeforeCheckIn)
+	}
+
+	marked, err := service.MarkNoShow(context.Background(), staff, past.ID)
+	if err != nil || marked.Status != entity.StatusNoShow {
+		t.Errorf("MarkNoShow = %v, %v, want NO_SHOW", marked, err)
+	}
+}
+
+func isInvalidTransition(err error) bool {
+	var transitionErr *entity.InvalidTransitionError
+	return errors.As(err, &transitionErr)
+}
+
+func TestCancelBookingWithNothingLeftToCancel(t *testing.T) {
+	guest := uuid.New()
+	tests := []struct {
+		name     string
+		statuses []entity.Status
+		wantErr  error
+	}{
+		{"every room cancelled", []entity.Status{entity.StatusCancelled, entity.StatusCancelled}, entity.ErrAlreadyCancelled},
+		{"stays started or over", []entity.Status{entity.StatusCancelled, entity.StatusInProgress, entity.StatusCompleted}, entity.ErrNotCancellable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			booking := &entity.Booking{ID: uuid.New(), UserID: guest}
+			repo := newMemoryReservations()
+			repo.bookings[booking.ID] = booking
+			for _, status := range tt.statuses {
+				reservation := &entity.Reservation{ID: uuid.New(), UserID: guest, Status: status, BookingID: &booking.ID}
+				repo.reservations[reservation.ID] = reservation
+			}
+			service := NewReservationService(repo, nil, nil, nil, 0)
+
+			_, err := service.CancelBooking(context.Background(), entity.Actor{UserID: guest, Role: constants.GUEST}, booking.ID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CancelBooking error = %v, want %v", err, tt.wantErr)
+			}
+			if len(repo.changes) != 0 {
+				t.Errorf("changes = %+v, want none", repo.changes)
+			}
+		})
+	}
+}
+
+func TestGetBookingByOtherGuest(t *testing.T) {
+	booking := &entity.Booking{ID: uuid.New(), UserID: uuid.New()}
+	repo := newMemoryReservations()
+	repo.bookings[booking.ID] = booking
+	service := NewReservationService(repo, nil, nil, nil, 0)
+
+	intruder := entity.Actor{UserID: uuid.New(), Role: constants.GUEST}
+	if _, err := service.GetBooking(context.Background(), intruder, booking.ID); !errors.Is(err, entity.ErrBookingNotFound) {
+		t.Fatalf("GetBooking error = %v, want %v", err, entity.ErrBookingNotFound)
+	}
+	if _, err := service.CancelBooking(context.Background(), intruder, booking.ID); !errors.Is(err, entity.ErrBookingNotFound) {
+		t.Fatalf("CancelBooking error = %v, want %v", err, entity.ErrBookingNotFound)
+	}
+
+	staff := entity.Actor{UserID: uuid.New(), Role: constants.STAFF}
+	if got, err := service.GetBooking(context.Background(), staff, booking.ID); err != nil || got.ID != booking.ID {
+		t.Fatalf("GetBooking by staff = %v, %v, want the booking", got, err)
+	}
+}
+
+func TestLookupReservation(t *testing.T) {
+	reservation := &entity.Reservation{
+		ID:     uuid.New(),
+		Code:   "7K3QX9TB",
+		Status: entity.StatusConfirmed,
+		User:   userEntity.User{FirstName: "Amina", LastName: "Odhiambo", Email: "amina@example.com"},
+	}
+	service := NewReservationService(newMemoryReservations(reservation), nil, nil, nil, 0)
+
+	tests := []struct {
+		name            string
+		code            string
+		lastName, email string
+		wantErr         error
+	}{
+		{"last name", "7K3QX9TB", "Odhiambo", "", nil},
+		{"email in another case", "7K3QX9TB", "", "Amina@Example.com", nil},
+		{"code as typed", "7k3q-x9tb", "odhiambo", "", nil},
+		{"wrong last name", "7K3QX9TB", "Otieno", "", entity.ErrReservationNotFound},
+		{"wrong email", "7K3QX9TB", "", "someone@example.com", entity.ErrReservationNotFound},
+		{"unknown code", "7K3QX9TC", "Odhiambo", "", entity.ErrReservationNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary, err := service.LookupReservation(context.Background(), tt.code, tt.lastName, tt.email)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (summary.Code != reservation.Code || summary.GuestName != "Amina O.") {
+				t.Errorf("summary = %+v, want code %s for Amina O.", summary, reservation.Code)
+			}
+		})
+	}
+}
+
+func TestCancelModifiedReservation(t *testing.T) {
+	tests := []struct {
+		name         string
+		nights       int // of the stay once modified
+		wantRefunded string
+	}{
+		// the first night's 100.00 is kept every time, whatever else the guest paid comes back
+		{name: "not modified", nights: 3, wantRefunded: "200.00"},
+		{name: "stay extended", nights: 4, wantRefunded: "300.00"},
+		{name: "stay shortened", nights: 2, wantRefunded: "100.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newModificationFixture(t)
+			ctx := context.Background()
+			if tt.nights != 3 {
+				checkOut := f.reservation.CheckInDate.AddDate(0, 0, tt.nights)
+				if _, err := f.service.ModifyReservation(ctx, f.guest, f.reservation.ID, entity.Modification{CheckOut: &checkOut}); err != nil {
+					t.Fatalf("ModifyReservation unexpected error: %v", err)
+				}
+			}
+
+			cancellation, err := f.service.CancelReservation(ctx, f.guest, f.reservation.ID)
+			if err != nil {
+				t.Fatalf("CancelReservation unexpected error: %v", err)
+			}
+			if cancellation.Refunded.Decimal() != tt.wantRefunded {
+				t.Errorf("refunded = %s, want %s", cancellation.Refunded.Decimal(), tt.wantRefunded)
+			}
+			if charged := f.charged(); charged.Decimal() != "100.00" {
+				t.Errorf("guest charged %s in the end, want the 100.00 fee", charged.Decimal())
+			}
+		})
+	}
+}
+
+func TestModifyReservationUndoneWhenTheDifferenceIsDeclined(t *testing.T) {
+	f := newModificationFixture(t)
+	f.gateway.Script(payment.FakeDecline)
+	checkOut := f.reservation.CheckOutDate.AddDate(0, 0, 2)
+
+	_, err := f.service.ModifyReservation(context.Background(), f.guest, f.reservation.ID, entity.Modification{CheckOut: &checkOut})
+	if !errors.Is(err, payment.ErrPaymentDeclined) {
+		t.Fatalf("ModifyReservation error = %v, want %v", err, payment.ErrPaymentDeclined)
+	}
+
+	stored := f.repo.reservations[f.reservation.ID]
+	if !stored.CheckOutDate.Equal(time.Date(2030, time.May, 13, 0, 0, 0, 0, time.UTC)) || stored.TotalPrice.Decimal() != "300.00" {
+		t.Errorf("stay = until %s for %s, want the unpaid extension undone", stored.CheckOutDate.Format(time.DateOnly), stored.TotalPrice.Decimal())
+	}
+	if len(f.repo.changes) != 2 || f.repo.changes[1].ChangedByRole != entity.SystemRole {
+		t.Errorf("changes = %+v, want the modification & its undoing by the system", f.repo.changes)
+	}
+	if charged := f.charged(); charged.Decimal() != "300.00" {
+		t.Errorf("guest charged %s, want only the original 300.00", charged.Decimal())
+	}
+}
+
+func TestModifyReservation(t *testing.T) {
+	ptr := func(n int) *int { return &n }
+	tests := []struct {
+		name         string
+		setup        func(f *modificationFixture) // optional, before modifying
+		modification func(f *modificationFixture) entity.Modification
+		wantErr      error
+		wantRoom     int    // the reservation's room afterwards
+		wantTotal    string // the reservation's price afterwards
+		wantCharged  string // what the guest has been charged in the end
+		wantFields   []string
+	}{
+		{
+			name: "stay extended is charged",
+			modification: func(f *modificationFixture) entity.Modification {
+				checkOut := f.reservation.CheckOutDate.AddDate(0, 0, 1)
+				return entity.Modification{CheckOut: &checkOut}
+			},
+			wantRoom: 101, wantTotal: "400.00", wantCharged: "400.00", wantFields: []string{"check_out_date", "total_price"},
+		},
+		{
+			name: "stay shortened is refunded",
+			modification: func(f *modificationFixture) entity.Modification {
+				checkIn := f.reservation.CheckInDate.AddDate(0, 0, 1)
+				return entity.Modification{CheckIn: &checkIn}
+			},
+			wantRoom: 101, wantTotal: "200.00", wantCharged: "200.00", wantFields: []string{"check_in_date", "total_price"},
+		},
+		{
+			name: "new room type moves the stay to a free room of it",
+			modification: func(f *modificationFixture) entity.Modification {
+				return entity.Modification{RoomTypeID: &f.suite}
+			},
+			wantRoom: 201, wantTotal: "450.00", wantCharged: "450.00", wantFields: []string{"room_id", "room_type_id", "total_price"},
+		},
+		{
+			name: "room of the same type changes only the room",
+			modification: func(f *modificationFixture) entity.Modification {
+				return entity.Modification{RoomID: &f.rooms[102].ID}
+			},
+			wantRoom: 102, wantTotal: "300.00", wantCharged: "300.00", wantFields: []string{"room_id"},
+		},
+		{
+			name: "no free room of the new type",
+			setup: func(f *modificationFixture) {
+				other := &entity.Reservation{
+					ID: uuid.New(), UserID: uuid.New(), Status: entity.StatusConfirmed, RoomTypeID: f.suite, RoomID: &f.rooms[201].ID,
+					CheckInDate: f.reservation.CheckInDate.AddDate(0, 0, 2), CheckOutDate: f.reservation.CheckOutDate.AddDate(0, 0, 2),
+				}
+				f.repo.reservations[other.ID] = other
+			},
+			modification: func(f *modificationFixture) entity.Modification {
+				return entity.Modification{RoomTypeID: &f.suite}
+			},
+			wantErr: entity.ErrRoomUnavailable, wantRoom: 101, wantTotal: "300.00", wantCharged: "300.00",
+		},
+		{
+			name: "too many guests",
+			modification: func(f *modificationFixture) entity.Modification {
+				return entity.Modification{NumGuests: ptr(3)}
+			},
+			wantErr: pricingEntity.ErrOccupancyExceeded, wantRoom: 101, wantTotal: "300.00", wantCharged: "300.00",
+		},
+		{
+			name: "check-out before check-in",
+			modification: func(f *modificationFixture) entity.Modification {
+				checkOut := f.reservation.CheckInDate
+				return entity.Modification{CheckOut: &checkOut}
+			},
+			wantErr: pricingEntity.ErrInvalidStay, wantRoom: 101, wantTotal: "300.00", wantCharged: "300.00",
+		},
+		{
+			name: "same stay again",
+			modification: func(f *modificationFixture) entity.Modification {
+				return entity.Modification{NumGuests: ptr(2), RoomTypeID: &f.standard}
+			},
+			wantErr: entity.ErrNoChanges, wantRoom: 101, wantTotal: "300.00", wantCharged: "300.00",
+		},
+		{
+			name:  "stay already started",
+			setup: func(f *modificationFixture) { f.reservation.Status = entity.StatusInProgress },
+			modification: func(f *modificationFixture) entity.Modification {
+				return entity.Modification{NumGuests: ptr(1)}
+			},
+			wantErr: entity.ErrNotModifiable, wantRoom: 101, wantTotal: "300.00", wantCharged: "300.00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newModificationFixture(t)
+			if tt.setup != nil {
+				tt.setup(f)
+			}
+
+			result, err := f.service.ModifyReservation(context.Background(), f.guest, f.reservation.ID, tt.modification(f))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ModifyReservation error = %v, want %v", err, tt.wantErr)
+			}
+
+			stored := f.repo.reservations[f.reservation.ID]
+			if stored.RoomID == nil || *stored.RoomID != f.rooms[tt.wantRoom].ID || stored.TotalPrice.Decimal() != tt.wantTotal {
+				t.Errorf("reservation in room %v for %s, want room %d for %s", stored.RoomID, stored.TotalPrice.Decimal(), tt.wantRoom, tt.wantTotal)
+			}
+			if charged := f.charged(); charged.Decimal() != tt.wantCharged {
+				t.Errorf("guest charged %s, want %s", charged.Decimal(), tt.wantCharged)
+			}
+			if err != nil {
+				if len(f.repo.changes) != 0 {
+					t.Errorf("changes = %+v, want none recorded", f.repo.changes)
+				}
+				return
+			}
+
+			if result.Reservation.ID != f.reservation.ID || result.PriceDifference.Decimal() != money.MustParse(tt.wantTotal, "USD").Sub(money.MustParse("300.00", "USD")).Decimal() {
+				t.Errorf("result = %+v, want the modified reservation & its price difference", result)
+			}
+			if len(f.repo.changes) != 1 || f.repo.changes[0].Action != entity.ActionModified || f.repo.changes[0].ChangedBy != f.guest.UserID {
+				t.Fatalf("changes = %+v, want one modification by the guest", f.repo.changes)
+			}
+			var fields map[string]entity.FieldChange
+			if err := json.Unmarshal(f.repo.changes[0].Fields, &fields); err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for name := range fields {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			if !reflect.DeepEqual(names, tt.wantFields) {
+				t.Errorf("changed fields = %v, want %v", names, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestFindFreeRoom(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(f *modificationFixture)
+		want  int // room number, 0 when none is free
+	}{
+		{name: "the reservation's own room is free for it", want: 101},
+		{
+			name: "rooms of other stays are skipped",
+			setup: func(f *modificationFixture) {
+				other := &entity.Reservation{
+					ID: uuid.New(), Status: entity.StatusConfirmed, RoomTypeID: f.standard, RoomID: &f.rooms[101].ID,
+					CheckInDate: f.reservation.CheckInDate, CheckOutDate: f.reservation.CheckOutDate,
+				}
+				f.repo.reservations[other.ID] = other
+				f.reservation.RoomID = nil
+			},
+			want: 102,
+		},
+		{
+			name: "a cancelled stay frees its room",
+			setup: func(f *modificationFixture) {
+				other := &entity.Reservation{
+					ID: uuid.New(), Status: entity.StatusCancelled, RoomTypeID: f.standard, RoomID: &f.rooms[101].ID,
+					CheckInDate: f.reservation.CheckInDate, CheckOutDate: f.reservation.CheckOutDate,
+				}
+				f.repo.reservations[other.ID] = other
+				f.reservation.RoomID = nil
+			},
+			want: 101,
+		},
+		{
+			name: "rooms under maintenance are skipped",
+			setup: func(f *modificationFixture) {
+				f.rooms[101].Status = roomEntity.UnderMaintenance
+			},
+			want: 102,
+		},
+		{
+			name: "nothing free",
+			setup: func(f *modificationFixture) {
+				f.rooms[101].Status = roomEntity.UnderMaintenance
+				other := &entity.Reservation{
+					ID: uuid.New(), Status: entity.StatusPending, RoomTypeID: f.standard, RoomID: &f.rooms[102].ID,
+					CheckInDate: f.reservation.CheckOutDate.AddDate(0, 0, -1), CheckOutDate: f.reservation.CheckOutDate.AddDate(0, 0, 3),
+				}
+				f.repo.reservations[other.ID] = other
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newModificationFixture(t)
+			if tt.setup != nil {
+				tt.setup(f)
+			}
+
+			room, err := f.service.findFreeRoom(context.Background(), f.standard, f.reservation)
+			if tt.want == 0 {
+				if !errors.Is(err, entity.ErrRoomUnavailable) {
+					t.Fatalf("findFreeRoom = %v, %v, want %v", room, err, entity.ErrRoomUnavailable)
+				}
+				return
+			}
+			if err != nil || room.RoomNumber != tt.want {
+				t.Fatalf("findFreeRoom = %v, %v, want room %d", room, err, tt.want)
+			}
+		})
+	}
+}
+
+// racingReservations : a memoryReservations on which someone else checks the guest in right before every transition
+type racingReservations struct {
+	*memoryReservations
+}
+
+func (m *racingReservations) Transition(ctx context.Context, id uuid.UUID, from, to entity.Status, change *entity.Change) error {
+	m.reservations[id].Status = entity.StatusInProgress
+	return m.memoryReservations.Transition(ctx, id, from, to, change)
+}
+
+func TestCancelReservationRefundsOnlyOnceCancelled(t *testing.T) {
+	f := newModificationFixture(t)
+	f.service.reservationRepo = &racingReservations{f.repo}
+
+	if _, err := f.service.CancelReservation(context.Background(), f.guest, f.reservation.ID); !isInvalidTransition(err) {
+		t.Fatalf("CancelReservation error = %v, want an invalid transition", err)
+	}
+	if charged := f.charged(); charged.Decimal() != "300.00" {
+		t.Errorf("guest charged %s, want nothing refunded for a cancellation that did not happen", charged.Decimal())
+	}
+}
+
+func TestCancelReservationRecordsFailedRefund(t *testing.T) {
+	f := newModificationFixture(t)
+	f.gateway.Script(payment.FakeTimeout)
+
+	cancellation, err := f.service.CancelReservation(context.Background(), f.guest, f.reservation.ID)
+	if err != nil {
+		t.Fatalf("CancelReservation unexpected error: %v", err)
+	}
+	if status := f.repo.reservations[f.reservation.ID].Status; status != entity.StatusCancelled {
+		t.Errorf("reservation status = %s, want it cancelled all the same", status)
+	}
+	if !cancellation.Refunded.IsZero() || cancellation.RefundDue.Decimal() != "200.00" {
+		t.Errorf("refunded %s with %s due, want nothing refunded & 200.00 due", cancellation.Refunded.Decimal(), cancellation.RefundDue.Decimal())
+	}
+	if len(f.repo.changes) != 2 || f.repo.changes[1].Action != entity.ActionRefundFailed {
+		t.Fatalf("changes = %+v, want the cancellation & the failed refund", f.repo.changes)
+	}
+	var fields map[string]struct{ To money.Money }
+	if err := json.Unmarshal(f.repo.changes[1].Fields, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if due := fields["refund_due"].To; due.Decimal() != "200.00" {
+		t.Errorf("recorded refund due = %s, want 200.00", due.Decimal())
+	}
+}
\ No newline at end of file
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/repository"
+	roomRepository "github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/repository"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils"
+	"github.com/google/uuid"
+	"time"
+)
+
+type WaitlistService interface {
+	Join(ctx context.Context, entry *entity.WaitlistEntry) (*entity.WaitlistEntry, error)
+	GetUserEntries(ctx context.Context, userID uuid.UUID) ([]*entity.WaitlistEntry, error)
+	Leave(ctx context.Context, actor entity.Actor, id uuid.UUID) error
+
+	OfferFreedRooms(ctx context.Context) (int, error)
+}
+
+type WaitlistServiceImpl struct {
+	waitlistRepo    repository.WaitlistRepository
+	reservationRepo repository.ReservationRepository
+	roomTypeRepo    roomRepository.RoomTypeRepository
+	offerTTL        time.Duration
+}
+
+// NewWaitlistService : offerTTL is how long a room offered to a waitlisted guest is held for them
+func NewWaitlistService(waitlistRepo repository.WaitlistRepository, reservationRepo repository.ReservationRepository, roomTypeRepo roomRepository.RoomTypeRepository, offerTTL time.Duration) *WaitlistServiceImpl {
+	return &WaitlistServiceImpl{
+		waitlistRepo:    waitlistRepo,
+		reservationRepo: reservationRepo,
+		roomTypeRepo:    roomTypeRepo,
+		offerTTL:        offerTTL,
+	}
+}
+
+// Join puts the guest on the waitlist of a room type for their dates.
+// It is only possible while the type is sold out for them on at least one night, and once per type & stay
+func (w *WaitlistServiceImpl) Join(ctx context.Context, entry *entity.WaitlistEntry) (*entity.WaitlistEntry, error) {
+	roomType, err := w.roomTypeRepo.GetByID(ctx, entry.RoomTypeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room type: %w", err)
+	}
+	if roomType == nil {
+		return nil, fmt.Errorf("room type not found")
+	}
+
+	free, err := w.reservationRepo.CountFreeRooms(ctx, entry.RoomTypeID, entry.CheckInDate, entry.CheckOutDate, uuid.Nil, entry.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check room availability: %w", err)
+	}
+	if free > 0 {
+		return nil, entity.ErrRoomsAvailable
+	}
+
+	existing, err := w.waitlistRepo.FindActive(ctx, entry.UserID, entry.RoomTypeID, entry.CheckInDate, entry.CheckOutDate)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, entity.ErrAlreadyWaitlisted
+	}
+
+	entry.Status = entity.WaitlistWaiting
+	if err := w.waitlistRepo.Create(ctx, entry); err != nil {
+		return nil, err
+	}
+	entry.RoomType = roomType
+	return entry, nil
+}
+
+// GetUserEntries returns the guest's waitlist entries, latest first
+func (w *WaitlistServiceImpl) GetUserEntries(ctx context.Context, userID uuid.UUID) ([]*entity.WaitlistEntry, error) {
+	return w.waitlistRepo.GetByUserID(ctx, userID)
+}
+
+// Leave takes an entry the actor may access off the waitlist.
+// Other guests' entries are reported as entity.ErrWaitlistEntryNotFound, exactly like missing ones
+func (w *WaitlistServiceImpl) Leave(ctx context.Context, actor entity.Actor, id uuid.UUID) error {
+	entry, err := w.waitlistRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !actor.CanAccessWaitlistEntry(entry) {
+		return entity.ErrWaitlistEntryNotFound
+	}
+	return w.waitlistRepo.Leave(ctx, id)
+}
+
+// OfferFreedRooms expires the offers that ran out, then goes through the waiting guests in the order they joined
+// and offers each a room held for offerTTL when one of their type has freed up for their whole stay,
+// e.g. after a cancellation or an expired hold. It emails every guest offered a room and reports how many there were
+func (w *WaitlistServiceImpl) OfferFreedRooms(ctx context.Context) (int, error) {
+	now := time.Now()
+	if _, err := w.waitlistRepo.ExpireOffers(ctx, now); err != nil {
+		return 0, err
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	entries, err := w.waitlistRepo.GetWaiting(ctx, today)
+	if err != nil {
+		return 0, err
+	}
+
+	offered := 0
+	for _, entry := range entries {
+		// an offer holds the room, so a guest further down the list only gets one if another room is free
+		err := w.waitlistRepo.Offer(ctx, entry, now.Add(w.offerTTL))
+		if errors.Is(err, entity.ErrRoomUnavailable) || errors.Is(err, entity.ErrWaitlistEntryNotActive) {
+			continue
+		}
+		if err != nil {
+			return offered, err
+		}
+		offered++
+
+		go sendWaitlistOffer(entry)
+	}
+	return offered, nil
+}
+
+// sendWaitlistOffer emails a waitlisted guest the room offered to them
+func sendWaitlistOffer(entry *entity.WaitlistEntry) {
+	if entry.User == nil {
+		return
+	}
+
+	roomType := ""
+	if entry.RoomType != nil {
+		roomType = entry.RoomType.Name
+	}
+	err := utils.SendWaitlistOfferEmail(entry.User.Email, utils.WaitlistOfferEmailData{
+		GuestName:      fmt.Sprintf("%s %s", entry.User.FirstName, entry.User.LastName),
+		RoomType:       roomType,
+		CheckInDate:    entry.CheckInDate,
+		CheckOutDate:   entry.CheckOutDate,
+		OfferExpiresAt: *entry.OfferExpiresAt,
+	})
+	if err != nil {
+		fmt.Println("Failed to send waitlist offer email:", err)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gatimugabriel/hotel-reservation-system/internal/constants"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/repository"
+	roomEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/entity"
+	roomRepository "github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/repository"
+	"github.com/google/uuid"
+)
+
+// fixedInventory : a ReservationRepository whose room types always have the same number of free rooms
+type fixedInventory struct {
+	repository.ReservationRepository
+	free int
+}
+
+func (f *fixedInventory) CountFreeRooms(ctx context.Context, roomTypeID uuid.UUID, checkIn, checkOut time.Time, exclude, guest uuid.UUID) (int, error) {
+	return f.free, nil
+}
+
+// memoryRoomTypes : in-memory RoomTypeRepository knowing a single room type
+type memoryRoomTypes struct {
+	roomRepository.RoomTypeRepository
+	roomType *roomEntity.RoomType
+}
+
+func (m *memoryRoomTypes) GetByID(ctx context.Context, id uuid.UUID) (*roomEntity.RoomType, error) {
+	if m.roomType == nil || m.roomType.ID != id {
+		return nil, nil
+	}
+	return m.roomType, nil
+}
+
+// memoryWaitlist : in-memory WaitlistRepository. Offer holds one of free rooms per room type, like the real one counts them
+type memoryWaitlist struct {
+	repository.WaitlistRepository
+	entries map[uuid.UUID]*entity.WaitlistEntry
+	free    map[uuid.UUID]int
+}
+
+func newMemoryWaitlist(entries ...*entity.WaitlistEntry) *memoryWaitlist {
+	m := &memoryWaitlist{entries: make(map[uuid.UUID]*entity.WaitlistEntry), free: make(map[uuid.UUID]int)}
+	for _, entry := range entries {
+		m.entries[entry.ID] = entry
+	}
+	return m
+}
+
+func (m *memoryWaitlist) Create(ctx context.Context, entry *entity.WaitlistEntry) error {
+	entry.ID = uuid.New()
+	entry.CreatedAt = time.Now()
+	m.entries[entry.ID] = entry
+	return nil
+}
+
+func (m *memoryWaitlist) GetByID(ctx context.Context, id uuid.UUID) (*entity.WaitlistEntry, error) {
+	entry, ok := m.entries[id]
+	if !ok {
+		return nil, entity.ErrWaitlistEntryNotFound
+	}
+	copied := *entry
+	return &copied, nil
+}
+
+func (m *memoryWaitlist) FindActive(ctx context.Context, userID, roomTypeID uuid.UUID, checkIn, checkOut time.Time) (*entity.WaitlistEntry, error) {
+	for _, entry := range m.entries {
+		if entry.UserID == userID && entry.RoomTypeID == roomTypeID && entry.IsActive() &&
+			entry.CheckInDate.Equal(checkIn) && entry.CheckOutDate.Equal(checkOut) {
+			return entry, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryWaitlist) Leave(ctx context.Context, id uuid.UUID) error {
+	entry := m.entries[id]
+	if !entry.IsActive() {
+		return entity.ErrWaitlistEntryNotActive
+	}
+	entry.Status = entity.WaitlistLeft
+	return nil
+}
+
+func (m *memoryWaitlist) ExpireOffers(ctx context.Context, now time.Time) (int, error) {
+	return 0, nil
+}
+
+func (m *memoryWaitlist) GetWaiting(ctx context.Context, from time.Time) ([]*entity.WaitlistEntry, error) {
+	var waiting []*entity.WaitlistEntry
+	for _, entry := range m.entries {
+		if entry.Status == entity.WaitlistWaiting && !entry.CheckInDate.Before(from) {
+			waiting = append(waiting, entry)
+		}
+	}
+	sort.Slice(waiting, func(i, j int) bool { return waiting[i].CreatedAt.Before(waiting[j].CreatedAt) })
+	return waiting, nil
+}
+
+func (m *memoryWaitlist) Offer(ctx context.Context, entry *entity.WaitlistEntry, expiresAt time.Time) error {
+	if m.free[entry.RoomTypeID] == 0 {
+		return entity.ErrRoomUnavailable
+	}
+	m.free[entry.RoomTypeID]--
+	entry.Status = entity.WaitlistOffered
+	entry.OfferExpiresAt = &expiresAt
+	return nil
+}
+
+func TestJoinWaitlist(t *testing.T) {
+	roomType := &roomEntity.RoomType{ID: uuid.New(), Name: "suite"}
+	guest := uuid.New()
+	checkIn := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 7)
+	join := func() *entity.WaitlistEntry {
+		return &entity.WaitlistEntry{UserID: guest, RoomTypeID: roomType.ID, CheckInDate: checkIn, CheckOutDate: checkIn.AddDate(0, 0, 2), NumGuests: 2}
+	}
+
+	waitlist := newMemoryWaitlist()
+	service := NewWaitlistService(waitlist, &fixedInventory{free: 1}, &memoryRoomTypes{roomType: roomType}, time.Hour)
+	if _, err := service.Join(context.Background(), join()); !errors.Is(err, entity.ErrRoomsAvailable) {
+		t.Fatalf("Join while rooms are free error = %v, want %v", err, entity.ErrRoomsAvailable)
+	}
+
+	service = NewWaitlistService(waitlist, &fixedInventory{free: 0}, &memoryRoomTypes{roomType: roomType}, time.Hour)
+	entry, err := service.Join(context.Background(), join())
+	if err != nil {
+		t.Fatalf("Join unexpected error: %v", err)
+	}
+	if entry.Status != entity.WaitlistWaiting || entry.RoomType != roomType {
+		t.Errorf("entry = %+v, want a waiting entry for the suite", entry)
+	}
+
+	if _, err := service.Join(context.Background(), join()); !errors.Is(err, entity.ErrAlreadyWaitlisted) {
+		t.Errorf("second Join error = %v, want %v", err, entity.ErrAlreadyWaitlisted)
+	}
+
+	// once left, the guest may join again
+	if err := service.Leave(context.Background(), entity.Actor{UserID: guest, Role: constants.GUEST}, entry.ID); err != nil {
+		t.Fatalf("Leave unexpected error: %v", err)
+	}
+	if _, err := service.Join(context.Background(), join()); err != nil {
+		t.Errorf("Join after leaving unexpected error: %v", err)
+	}
+}
+
+func TestLeaveWaitlist(t *testing.T) {
+	guest := uuid.New()
+	entry := &entity.WaitlistEntry{ID: uuid.New(), UserID: guest, Status: entity.WaitlistOffered}
+	waitlist := newMemoryWaitlist(entry)
+	service := NewWaitlistService(waitlist, nil, nil, time.Hour)
+
+	intruder := entity.Actor{UserID: uuid.New(), Role: constants.GUEST}
+	if err := service.Leave(context.Background(), intruder, entry.ID); !errors.Is(err, entity.ErrWaitlistEntryNotFound) {
+		t.Fatalf("Leave by another guest error = %v, want %v", err, entity.ErrWaitlistEntryNotFound)
+	}
+
+	owner := entity.Actor{UserID: guest, Role: constants.GUEST}
+	if err := service.Leave(context.Background(), owner, entry.ID); err != nil {
+		t.Fatalf("Leave unexpected error: %v", err)
+	}
+	if entry.Status != entity.WaitlistLeft {
+		t.Errorf("status = %s, want %s", entry.Status, entity.WaitlistLeft)
+	}
+	if err := service.Leave(context.Background(), owner, entry.ID); !errors.Is(err, entity.ErrWaitlistEntryNotActive) {
+		t.Errorf("Leave twice error = %v, want %v", err, entity.ErrWaitlistEntryNotActive)
+	}
+}
+
+func TestOfferFreedRoomsInJoiningOrder(t *testing.T) {
+	suite, single := uuid.New(), uuid.New()
+	checkIn := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 3)
+	joined := time.Now().Add(-time.Hour)
+	waiting := func(roomType uuid.UUID, minutes int) *entity.WaitlistEntry {
+		return &entity.WaitlistEntry{
+			ID:           uuid.New(),
+			UserID:       uuid.New(),
+			RoomTypeID:   roomType,
+			Status:       entity.WaitlistWaiting,
+			CheckInDate:  checkIn,
+			CheckOutDate: checkIn.AddDate(0, 0, 1),
+			CreatedAt:    joined.Add(time.Duration(minutes) * time.Minute),
+		}
+	}
+
+	second, first, third := waiting(suite, 2), waiting(suite, 1), waiting(suite, 3)
+	other := waiting(single, 0)
+	waitlist := newMemoryWaitlist(second, first, third, other)
+	waitlist.free[suite] = 2 // a cancellation freed two suites, no single room is free
+
+	service := NewWaitlistService(waitlist, nil, nil, 30*time.Minute)
+	offered, err := service.OfferFreedRooms(context.Background())
+	if err != nil {
+		t.Fatalf("OfferFreedRooms unexpected error: %v", err)
+	}
+	if offered != 2 {
+		t.Errorf("offered = %d, want 2", offered)
+	}
+
+	for entry, want := range map[*entity.WaitlistEntry]entity.WaitlistStatus{
+		first:  entity.WaitlistOffered,
+		second: entity.WaitlistOffered,
+		third:  entity.WaitlistWaiting,
+		other:  entity.WaitlistWaiting,
+	} {
+		if entry.Status != want {
+			t.Errorf("entry joined at %s status = %s, want %s", entry.CreatedAt.Format(time.TimeOnly), entry.Status, want)
+		}
+	}
+	if first.OfferExpiresAt == nil || time.Until(*first.OfferExpiresAt) > 30*time.Minute {
+		t.Errorf("offer expires at %v, want within 30 minutes", first.OfferExpiresAt)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,15 @@
+package entity
+
+import (
+	pricingEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/entity"
+)
+
+// Availability : what is left of one room type for a stay, with the price of that stay.
+// FreeCount is the number of rooms of the type that can still be booked for every night;
+// Rooms lists the rooms that can be picked individually, which may be fewer once rooms are allocated
+type Availability struct {
+	RoomType  RoomType             `json:"room_type"`
+	FreeCount int                  `json:"free_count"`
+	Rooms     []*Room              `json:"rooms"`
+	Quote     *pricingEntity.Quote `json:"quote"`
+}
\ No newline at end of file
@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// MaintenanceWindow : a planned period [StartDate, EndDate) during which a room is out of service.
+// Unlike the under_maintenance status it lies in the future, so rooms are not allocated to stays it overlaps
+type MaintenanceWindow struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	RoomID    uuid.UUID `gorm:"type:uuid;not null;index" json:"room_id" validate:"required"`
+	StartDate time.Time `gorm:"not null" json:"start_date" validate:"required"`
+	EndDate   time.Time `gorm:"not null" json:"end_date" validate:"required,gtfield=StartDate"`
+	Reason    string    `gorm:"type:text" json:"reason" validate:"max=500"`
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+func (MaintenanceWindow) TableName() string {
+	return "room_maintenance_windows"
+}
+
+// Overlaps reports whether the window takes the room out of service during any night of [checkIn, checkOut)
+func (w *MaintenanceWindow) Overlaps(checkIn, checkOut time.Time) bool {
+	return w.StartDate.Before(checkOut) && w.EndDate.After(checkIn)
+}
+
+// MaintenanceWindowRequest represents the data object used to schedule a maintenance window
+type MaintenanceWindowRequest struct {
+	RoomID    string `json:"room_id" validate:"required,uuid"`
+	StartDate string `json:"start_date" validate:"required"`
+	EndDate   string `json:"end_date" validate:"required"`
+	Reason    string `json:"reason" validate:"max=500"`
+}
\ No newline at end of file
@@ -10,6 +10,7 @@ type Room struct {
 	RoomNumber  int        `gorm:"type:int;not null;unique" json:"room_number" validate:"required,min=1"`
 	FloorNumber int        `gorm:"type:int;default=0" json:"floor_number" validate:"required,min=0"`
 	Status      RoomStatus `gorm:"type:varchar(20);not null;default:'available'" json:"status" validate:"required,oneof=available unavailable under_maintenance"`
+	Accessible  bool       `gorm:"not null;default:false" json:"accessible"` // step-free access, for guests who ask for it
 
 	RoomTypeID uuid.UUID `gorm:"type:uuid;not null;index" json:"room_type_id" validate:"required"`
 	RoomType   RoomType  `gorm:"foreignKey:RoomTypeID" json:"room_type"`
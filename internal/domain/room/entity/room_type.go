@@ -1,20 +1,28 @@
 package entity
 
 import (
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/money"
 	"github.com/google/uuid"
 	"time"
 )
 
 // RoomType : represents a category of rooms with similar characteristics
 type RoomType struct {
-	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
-	Name         string    `gorm:"type:citext;not null;unique" json:"name" validate:"required,min=2,max=100"`
-	Description  string    `gorm:"type:citext" json:"description" validate:"max=500"`
-	BasePrice    float64   `gorm:"type:decimal(10,2);not null" json:"base_price" validate:"required,min=0"`
-	MaxOccupancy int       `gorm:"not null" json:"max_occupancy" validate:"required,min=1,max=10"`
-	NumBeds      int       `gorm:"not null" json:"num_beds" validate:"required,min=1,max=5"`
-	SquareMeters float64   `gorm:"type:decimal(6,2);not null" json:"square_meters" validate:"required,min=1"`
-	Status       string    `gorm:"type:citext;not null;default:'ACTIVE'" json:"status" validate:"oneof=ACTIVE INACTIVE"`
+	ID           uuid.UUID   `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Name         string      `gorm:"type:citext;not null;unique" json:"name" validate:"required,min=2,max=100"`
+	Description  string      `gorm:"type:citext" json:"description" validate:"max=500"`
+	BasePrice    money.Money `gorm:"type:decimal(10,2);not null" json:"base_price" validate:"required,min=0"`
+	MaxOccupancy int         `gorm:"not null" json:"max_occupancy" validate:"required,min=1,max=10"`
+	NumBeds      int         `gorm:"not null" json:"num_beds" validate:"required,min=1,max=5"`
+	SquareMeters float64     `gorm:"type:decimal(6,2);not null" json:"square_meters" validate:"required,min=1"`
+	Status       string      `gorm:"type:citext;not null;default:'ACTIVE'" json:"status" validate:"oneof=ACTIVE INACTIVE"`
+
+	// guests above IncludedOccupancy are charged ExtraGuestPrice per night each
+	IncludedOccupancy int         `gorm:"not null;default:2" json:"included_occupancy" validate:"min=0,max=10"`
+	ExtraGuestPrice   money.Money `gorm:"type:decimal(10,2);not null;default:0" json:"extra_guest_price" validate:"min=0"`
+
+	// cancellation fees for this type; nil falls back to the default policy
+	CancellationPolicyID *uuid.UUID `gorm:"type:uuid;index" json:"cancellation_policy_id,omitempty"`
 
 	BedTypeID uuid.UUID `gorm:"type:uuid;not null;index" json:"bed_type_id" validate:"required"`
 	Bed       BedType   `gorm:"foreignKey:BedTypeID" json:"bed_type"`
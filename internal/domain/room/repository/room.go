@@ -3,10 +3,12 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/entity"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/infrastructure/database"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"time"
 )
 
 // RoomRepository : data persistence database interaction interface
@@ -16,6 +18,9 @@ type RoomRepository interface {
 	Create(ctx context.Context, room *entity.Room) error
 	Update(ctx context.Context, room *entity.Room) error
 	Delete(ctx context.Context, id uuid.UUID) error
+
+	GetMaintenanceWindows(ctx context.Context, from, to time.Time) ([]*entity.MaintenanceWindow, error)
+	CreateMaintenanceWindow(ctx context.Context, window *entity.MaintenanceWindow) error
 }
 
 // RoomRepositoryImpl implements the RoomRepository interface
@@ -78,4 +83,22 @@ func (repo *RoomRepositoryImpl) Delete(ctx context.Context, id uuid.UUID) error
 		}
 		return nil
 	})
+}
+
+// GetMaintenanceWindows returns the maintenance windows that overlap [from, to), earliest first
+func (repo *RoomRepositoryImpl) GetMaintenanceWindows(ctx context.Context, from, to time.Time) ([]*entity.MaintenanceWindow, error) {
+	var windows []*entity.MaintenanceWindow
+	if err := repo.db.WithContext(ctx).
+		Where("start_date < ? AND end_date > ?", to, from).
+		Order("start_date, room_id").Find(&windows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get maintenance windows: %w", err)
+	}
+	return windows, nil
+}
+
+func (repo *RoomRepositoryImpl) CreateMaintenanceWindow(ctx context.Context, window *entity.MaintenanceWindow) error {
+	if err := repo.db.WithContext(ctx).Create(window).Error; err != nil {
+		return fmt.Errorf("failed to create maintenance window: %w", err)
+	}
+	return nil
 }
\ No newline at end of file
@@ -2,7 +2,10 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	pricingEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/entity"
+	pricingServices "github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/services"
 	reservationRepository "github.com/gatimugabriel/hotel-reservation-system/internal/domain/reservation/repository"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/entity"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/repository"
@@ -15,26 +18,39 @@ type RoomService interface {
 	UpdateRoom(ctx context.Context, id uuid.UUID, room *entity.Room) (*entity.Room, error)
 	DeleteRoom(ctx context.Context, id uuid.UUID) error
 
-	CheckAvailability(ctx context.Context, checkIn, checkOut time.Time) (map[string][]*entity.Room, error)
+	CheckAvailability(ctx context.Context, checkIn, checkOut time.Time, guests int) (map[string]*entity.Availability, error)
 	GetRooms(ctx context.Context, filters map[string]interface{}) ([]*entity.Room, error)
 	GetRoom(ctx context.Context, id string) (*entity.Room, error)
+
+	ScheduleMaintenance(ctx context.Context, window *entity.MaintenanceWindow) (*entity.MaintenanceWindow, error)
+	GetMaintenanceWindows(ctx context.Context, from, to time.Time) ([]*entity.MaintenanceWindow, error)
 }
 
 type RoomServiceImpl struct {
 	roomRepo        repository.RoomRepository
 	roomTypeRepo    repository.RoomTypeRepository
 	reservationRepo reservationRepository.ReservationRepository
+	pricingService  pricingServices.PricingService
 }
 
-func NewRoomService(roomRepo repository.RoomRepository, roomTypeRepo repository.RoomTypeRepository, reservationRepo reservationRepository.ReservationRepository) *RoomServiceImpl {
+func NewRoomService(roomRepo repository.RoomRepository, roomTypeRepo repository.RoomTypeRepository, reservationRepo reservationRepository.ReservationRepository, pricingService pricingServices.PricingService) *RoomServiceImpl {
 	return &RoomServiceImpl{
 		roomRepo:        roomRepo,
 		roomTypeRepo:    roomTypeRepo,
 		reservationRepo: reservationRepo,
+		pricingService:  pricingService,
 	}
 }
 
-func (r *RoomServiceImpl) CheckAvailability(ctx context.Context, checkIn, checkOut time.Time) (map[string][]*entity.Room, error) {
+// CheckAvailability reports, by room type name, how many rooms of each type are free on every night of the stay,
+// the individual rooms that are free throughout, and the price of the stay for the number of guests.
+// Room types with nothing left, or that cannot host that many guests, are left out
+func (r *RoomServiceImpl) CheckAvailability(ctx context.Context, checkIn, checkOut time.Time, guests int) (map[string]*entity.Availability, error) {
+	roomTypes, err := r.roomTypeRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room types: %w", err)
+	}
+
 	rooms, err := r.roomRepo.GetRooms(ctx, map[string]interface{}{"status": "available"})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get rooms: %w", err)
@@ -49,22 +65,45 @@ func (r *RoomServiceImpl) CheckAvailability(ctx context.Context, checkIn, checkO
 	// Create a map of room IDs that are already reserved
 	reservedRoomIDs := make(map[uuid.UUID]bool)
 	for _, reservation := range reservations {
-		reservedRoomIDs[reservation.RoomID] = true
+		if reservation.IsAssigned() && reservation.Overlaps(checkIn, checkOut) {
+			reservedRoomIDs[*reservation.RoomID] = true
+		}
 	}
 
-	// Filter out rooms that are already reserved
-	var availableRooms []*entity.Room
+	// Group the rooms free for the whole stay by their type
+	freeRoomsByType := make(map[uuid.UUID][]*entity.Room)
 	for _, room := range rooms {
 		if !reservedRoomIDs[room.ID] {
-			availableRooms = append(availableRooms, room)
+			freeRoomsByType[room.RoomTypeID] = append(freeRoomsByType[room.RoomTypeID], room)
 		}
 	}
 
-	// Categorize rooms by their type
-	categorizedRooms := make(map[string][]*entity.Room)
-	for _, room := range availableRooms {
-		roomType := room.RoomType.Name
-		categorizedRooms[roomType] = append(categorizedRooms[roomType], room)
+	categorizedRooms := make(map[string]*entity.Availability)
+	for _, roomType := range roomTypes {
+		// Count night by night, so bookings without a room assigned yet are taken into account
+		free, err := r.reservationRepo.CountFreeRooms(ctx, roomType.ID, checkIn, checkOut, uuid.Nil, uuid.Nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count free rooms of type %s: %w", roomType.Name, err)
+		}
+		if free == 0 {
+			continue
+		}
+
+		// Price the stay, resolving the effective nightly rate from the rate plans
+		quote, err := r.pricingService.Quote(ctx, roomType.ID, checkIn, checkOut, guests)
+		if err != nil {
+			if errors.Is(err, pricingEntity.ErrOccupancyExceeded) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to price room type %s: %w", roomType.Name, err)
+		}
+
+		categorizedRooms[roomType.Name] = &entity.Availability{
+			RoomType:  *roomType,
+			FreeCount: free,
+			Rooms:     freeRoomsByType[roomType.ID],
+			Quote:     quote,
+		}
 	}
 
 	return categorizedRooms, nil
@@ -144,4 +183,25 @@ func (r *RoomServiceImpl) DeleteRoom(ctx context.Context, id uuid.UUID) error {
 		return fmt.Errorf("failed to delete room: %w", err)
 	}
 	return nil
+}
+
+// ScheduleMaintenance plans a maintenance window for an existing room
+func (r *RoomServiceImpl) ScheduleMaintenance(ctx context.Context, window *entity.MaintenanceWindow) (*entity.MaintenanceWindow, error) {
+	rooms, err := r.roomRepo.GetRooms(ctx, map[string]interface{}{"id": window.RoomID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room: %w", err)
+	}
+	if len(rooms) == 0 {
+		return nil, fmt.Errorf("room not found")
+	}
+
+	if err := r.roomRepo.CreateMaintenanceWindow(ctx, window); err != nil {
+		return nil, err
+	}
+	return window, nil
+}
+
+// GetMaintenanceWindows returns the maintenance windows that overlap [from, to)
+func (r *RoomServiceImpl) GetMaintenanceWindows(ctx context.Context, from, to time.Time) ([]*entity.MaintenanceWindow, error) {
+	return r.roomRepo.GetMaintenanceWindows(ctx, from, to)
 }
\ No newline at end of file
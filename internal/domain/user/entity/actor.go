@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"github.com/gatimugabriel/hotel-reservation-system/internal/constants"
+	"github.com/google/uuid"
+	"strings"
+)
+
+// Actor : the authenticated user managing other users' accounts
+type Actor struct {
+	UserID uuid.UUID
+	Role   constants.Role
+}
+
+func NewActor(userID uuid.UUID, role string) Actor {
+	return Actor{UserID: userID, Role: constants.Role(strings.ToUpper(role))}
+}
+
+// roleRanks orders the roles by authority. A role missing from it has none
+var roleRanks = map[constants.Role]int{
+	constants.GUEST:         1,
+	constants.STAFF:         2,
+	constants.MANAGER:       3,
+	constants.ADMIN:         4,
+	constants.PROPERTYOWNER: 5,
+}
+
+// CanAssign reports whether the actor may give users the role: only roles below their own,
+// so a MANAGER may make STAFF but not ADMINs, and no one can make PROPERTYOWNERs
+func (a Actor) CanAssign(role constants.Role) bool {
+	rank, ok := roleRanks[role]
+	return ok && rank < roleRanks[a.Role]
+}
+
+// CanManage reports whether the actor may change the user's account (role, status, profile, sessions):
+// only the accounts of users below their own role, and never their own
+func (a Actor) CanManage(user *User) bool {
+	return user.ID != a.UserID && a.CanAssign(user.Role)
+}
\ No newline at end of file
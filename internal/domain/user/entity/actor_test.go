@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/gatimugabriel/hotel-reservation-system/internal/constants"
+	"github.com/google/uuid"
+)
+
+func TestActorCanAssign(t *testing.T) {
+	tests := []struct {
+		actor constants.Role
+		role  constants.Role
+		want  bool
+	}{
+		{constants.MANAGER, constants.STAFF, true},
+		{constants.MANAGER, constants.GUEST, true},
+		{constants.MANAGER, constants.MANAGER, false},
+		{constants.MANAGER, constants.ADMIN, false},
+		{constants.ADMIN, constants.MANAGER, true},
+		{constants.ADMIN, constants.ADMIN, false},
+		{constants.PROPERTYOWNER, constants.ADMIN, true},
+		{constants.PROPERTYOWNER, constants.PROPERTYOWNER, false},
+		{constants.STAFF, constants.GUEST, true},
+		{constants.GUEST, constants.GUEST, false},
+		{constants.ADMIN, "ROOT", false},
+		{"ROOT", constants.GUEST, false},
+	}
+	for _, tt := range tests {
+		actor := NewActor(uuid.New(), string(tt.actor))
+		if got := actor.CanAssign(tt.role); got != tt.want {
+			t.Errorf("%s assigning %s = %t, want %t", tt.actor, tt.role, got, tt.want)
+		}
+	}
+}
+
+func TestActorCanManage(t *testing.T) {
+	admin := NewActor(uuid.New(), "admin")
+	if !admin.CanManage(&User{ID: uuid.New(), Role: constants.MANAGER}) {
+		t.Error("admin cannot manage a manager, want allowed")
+	}
+	if admin.CanManage(&User{ID: uuid.New(), Role: constants.PROPERTYOWNER}) {
+		t.Error("admin can manage a property owner, want refused")
+	}
+	if admin.CanManage(&User{ID: admin.UserID, Role: constants.GUEST}) {
+		t.Error("admin can manage their own account, want refused")
+	}
+}
\ No newline at end of file
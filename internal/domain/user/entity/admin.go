@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"github.com/gatimugabriel/hotel-reservation-system/internal/constants"
+)
+
+// UserFilter : which users to list, and which page of them
+type UserFilter struct {
+	Query  string // matched against names & email, ignoring case
+	Role   constants.Role
+	Status constants.UserStatus
+	Page   int // from 1
+	Limit  int
+}
+
+// InviteUserRequest : the account to create for someone joining the hotel, who chooses their password from the emailed invitation
+type InviteUserRequest struct {
+	FirstName string         `json:"first_name" validate:"required,min=3,max=20"`
+	LastName  string         `json:"last_name" validate:"required,min=3,max=20"`
+	Email     string         `json:"email" validate:"required,email"`
+	Phone     string         `json:"phone" validate:"omitempty,e164"`
+	Role      constants.Role `json:"role" validate:"required,oneof=GUEST STAFF MANAGER ADMIN PROPERTYOWNER"`
+}
+
+type ChangeRoleRequest struct {
+	Role constants.Role `json:"role" validate:"required,oneof=GUEST STAFF MANAGER ADMIN PROPERTYOWNER"`
+}
+
+// ChangeStatusRequest : accounts are closed (DELETED) with DELETE /user/{userID} instead
+type ChangeStatusRequest struct {
+	Status constants.UserStatus `json:"status" validate:"required,oneof=ACTIVE INACTIVE"`
+}
\ No newline at end of file
@@ -0,0 +1,37 @@
+package entity
+
+import "errors"
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+	// ErrWrongPassword is returned when changing a password with the wrong current password
+	ErrWrongPassword = errors.New("current password is incorrect")
+
+	// ErrInvalidRefreshToken is returned for refresh tokens that are malformed, expired, revoked or unknown
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused is returned when a refresh token that was already rotated is presented again.
+	// The token may have been stolen, so its session is revoked
+	ErrRefreshTokenReused = errors.New("refresh token has already been used")
+
+	// ErrSessionNotFound is also returned for sessions of other users
+	ErrSessionNotFound = errors.New("session not found")
+
+	// ErrGoogleSignInFailed is returned when Google does not confirm who the user is
+	ErrGoogleSignInFailed = errors.New("google sign in failed")
+	// ErrEmailNotVerified is returned for Google accounts whose email Google has not verified:
+	// they are not trusted to sign in to, or create, the account with that email
+	ErrEmailNotVerified = errors.New("google account email is not verified")
+	ErrAccountInactive  = errors.New("account is inactive")
+
+	// ErrRoleNotAllowed is returned when a user manages an account, or grants a role, not below their own role
+	ErrRoleNotAllowed = errors.New("you can only manage users, and grant roles, below your own role")
+
+	// ErrInvalidVerificationToken is returned for email verification tokens that are malformed, expired, used or unknown
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification link")
+	ErrAlreadyVerified          = errors.New("email is already verified")
+	// ErrVerificationThrottled is returned when a verification email is asked for again too soon after the last one
+	ErrVerificationThrottled = errors.New("a verification email was sent recently. Please wait before asking for another")
+
+	// ErrInvalidResetToken is returned for password reset tokens that are malformed, expired, used or unknown
+	ErrInvalidResetToken = errors.New("invalid or expired password reset link")
+)
\ No newline at end of file
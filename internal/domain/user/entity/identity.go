@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// IdentityProvider : an outside service users can sign in with, besides their password
+type IdentityProvider string
+
+const (
+	ProviderGoogle IdentityProvider = "GOOGLE"
+)
+
+// UserIdentity links a user to their account with an identity provider, so that signing in there signs them in here.
+// One user may have several identities, and also a password
+type UserIdentity struct {
+	ID       uuid.UUID        `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID   uuid.UUID        `gorm:"type:uuid;not null;index" json:"user_id"`
+	Provider IdentityProvider `gorm:"type:varchar(20);not null;uniqueIndex:idx_identity_subject" json:"provider"`
+	Subject  string           `gorm:"type:varchar(255);not null;uniqueIndex:idx_identity_subject" json:"-"` // the user's ID at the provider
+	Email    string           `gorm:"type:varchar(100);not null" json:"email"`
+
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// GoogleProfile : the part of a Google user's profile used to sign them in
+type GoogleProfile struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+}
\ No newline at end of file
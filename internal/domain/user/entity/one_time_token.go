@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// TokenPurpose : what a one-time token lets its holder do
+type TokenPurpose string
+
+const (
+	PurposeEmailVerification TokenPurpose = "EMAIL_VERIFICATION"
+	PurposePasswordReset     TokenPurpose = "PASSWORD_RESET"
+)
+
+// OneTimeToken : the server-side record of a signed token emailed to a user, whose ID the token carries as its "jti" claim.
+// The record makes the token single use
+type OneTimeToken struct {
+	ID        uuid.UUID    `gorm:"type:uuid;primary_key" json:"id"`
+	UserID    uuid.UUID    `gorm:"type:uuid;not null;index:idx_one_time_token_user" json:"user_id"`
+	Purpose   TokenPurpose `gorm:"type:varchar(30);not null;index:idx_one_time_token_user" json:"purpose"`
+	ExpiresAt time.Time    `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time   `json:"used_at,omitempty"`
+
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// Usable reports whether the token can still be used at now
+func (t *OneTimeToken) Usable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
\ No newline at end of file
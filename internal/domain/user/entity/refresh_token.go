@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// RefreshToken : the server-side record of an issued refresh token, whose ID the token carries as its "jti" claim.
+// Every token obtained by rotating another one belongs to the family started at sign in: the family is the session
+type RefreshToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	FamilyID  uuid.UUID  `gorm:"type:uuid;not null;index" json:"family_id"` // the ID of the Session
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	RotatedAt *time.Time `json:"rotated_at,omitempty"` // when the token was exchanged for the next one of its family
+	RevokedAt *time.Time `json:"revoked_at,omitempty"` // when the session was revoked, on sign out, on request or on reuse of a rotated token
+
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// Usable reports whether the token can still be exchanged at now
+func (t *RefreshToken) Usable(now time.Time) bool {
+	return t.RotatedAt == nil && t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// RefreshTokenRequest represents the data object used to refresh tokens or sign out when the refreshToken cookie is not sent
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
\ No newline at end of file
@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Session : a sign in of a user on some device. It lasts as long as its refresh tokens keep being rotated,
+// and ends when revoked. The tokens of a session carry its ID as their "sid" claim
+type Session struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"-"`
+	UserAgent  string     `gorm:"type:varchar(512);not null;default:''" json:"user_agent"`
+	IPAddress  string     `gorm:"type:varchar(45);not null;default:''" json:"ip_address"`
+	LastSeenAt time.Time  `gorm:"not null" json:"last_seen_at"`
+	RevokedAt  *time.Time `gorm:"index" json:"revoked_at,omitempty"`
+
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+
+	// Current marks the session of the request listing sessions
+	Current bool `gorm:"-" json:"current"`
+}
+
+// Device : the client a session is used from
+type Device struct {
+	UserAgent string
+	IPAddress string
+}
\ No newline at end of file
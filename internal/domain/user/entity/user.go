@@ -11,17 +11,91 @@ type User struct {
 	FirstName    string               `gorm:"type:varchar(255);not null" json:"first_name" validate:"required,min=3,max=20"`
 	LastName     string               `gorm:"type:varchar(255);not null" json:"last_name" validate:"required,min=3,max=20"`
 	Email        string               `gorm:"type:varchar(100);not null;unique" json:"email" validate:"required,email"`
-	Phone        string               `gorm:"type:varchar(20);not null;unique" json:"phone" validate:"required,e164"`
-	PasswordHash string               `gorm:"type:varchar(100);not null" json:"password" validate:"required,min=8,passwd"`
+	Phone        string               `gorm:"type:varchar(20);not null;default:'';uniqueIndex:idx_users_phone,where:phone <> ''" json:"phone" validate:"required,e164"` // empty for accounts created by signing in with Google
+	PasswordHash string               `gorm:"type:varchar(100);not null" json:"-"`
 	Role         constants.Role       `gorm:"type:varchar(20);not null" json:"role" validate:"oneof=GUEST STAFF MANAGER ADMIN PROPERTYOWNER"`
 	IsVerified   bool                 `gorm:"default:false" json:"is_verified"`
-	Status       constants.UserStatus `gorm:"type:varchar(20);default:ACTIVE" json:"status" validate:"oneof=ACTIVE INACTIVE"`
+	Status       constants.UserStatus `gorm:"type:varchar(20);default:ACTIVE" json:"status" validate:"oneof=ACTIVE INACTIVE DELETED"`
 
 	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
 }
 
+// SignUpRequest : what a user signs up with. It has no role: everyone signing up is a GUEST,
+// other accounts are invited by the users managing them
+type SignUpRequest struct {
+	FirstName string `json:"first_name" validate:"required,min=3,max=20"`
+	LastName  string `json:"last_name" validate:"required,min=3,max=20"`
+	Email     string `json:"email" validate:"required,email"`
+	Phone     string `json:"phone" validate:"required,e164"`
+	Password  string `json:"password" validate:"required,min=8,passwd"`
+}
+
+// User returns the GUEST account to create for the request, with its password still in plain text
+func (req *SignUpRequest) User() *User {
+	return &User{
+		FirstName:    req.FirstName,
+		LastName:     req.LastName,
+		Email:        req.Email,
+		Phone:        req.Phone,
+		PasswordHash: req.Password,
+		Role:         constants.GUEST,
+		Status:       constants.ACTIVE,
+	}
+}
+
+// UserProfile : what API responses show of a user
+type UserProfile struct {
+	ID         uuid.UUID            `json:"id"`
+	FirstName  string               `json:"first_name"`
+	LastName   string               `json:"last_name"`
+	Email      string               `json:"email"`
+	Phone      string               `json:"phone"`
+	Role       constants.Role       `json:"role"`
+	IsVerified bool                 `json:"is_verified"`
+	Status     constants.UserStatus `json:"status"`
+	CreatedAt  time.Time            `json:"created_at"`
+	UpdatedAt  time.Time            `json:"updated_at"`
+}
+
+// Profile returns the user as shown in API responses
+func (u *User) Profile() *UserProfile {
+	return &UserProfile{
+		ID:         u.ID,
+		FirstName:  u.FirstName,
+		LastName:   u.LastName,
+		Email:      u.Email,
+		Phone:      u.Phone,
+		Role:       u.Role,
+		IsVerified: u.IsVerified,
+		Status:     u.Status,
+		CreatedAt:  u.CreatedAt,
+		UpdatedAt:  u.UpdatedAt,
+	}
+}
+
+// UpdateProfileRequest : the profile fields to change. Fields left out stay as they are
+type UpdateProfileRequest struct {
+	FirstName *string `json:"first_name" validate:"omitempty,min=3,max=20"`
+	LastName  *string `json:"last_name" validate:"omitempty,min=3,max=20"`
+	Phone     *string `json:"phone" validate:"omitempty,e164"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8,passwd"`
+}
+
 type UserLoginRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8,passwd"`
 }
\ No newline at end of file
@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gatimugabriel/hotel-reservation-system/internal/constants"
+)
+
+func TestSignUpRequest(t *testing.T) {
+	var req SignUpRequest
+	if err := json.Unmarshal([]byte(`{"email":"ann@example.com","password":"Secret123","role":"ADMIN"}`), &req); err != nil {
+		t.Fatal(err)
+	}
+	user := req.User()
+	if user.PasswordHash != "Secret123" || user.Role != constants.GUEST {
+		t.Fatalf("user = %+v, want a GUEST with the password signed up with", user)
+	}
+
+	user.PasswordHash = "$2a$10$hash"
+	for name, value := range map[string]any{"user": user, "profile": user.Profile()} {
+		body, err := json.Marshal(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(body), "password") || strings.Contains(string(body), "$2a$") {
+			t.Errorf("%s serialised as %s, want no password", name, body)
+		}
+	}
+}
\ No newline at end of file
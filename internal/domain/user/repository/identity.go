@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/infrastructure/database"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// IdentityRepository : interface for the links between users & their accounts with identity providers
+type IdentityRepository interface {
+	GetByProvider(ctx context.Context, provider entity.IdentityProvider, subject string) (*entity.UserIdentity, error)
+	Create(ctx context.Context, identity *entity.UserIdentity) error
+	CreateForUnverified(ctx context.Context, identity *entity.UserIdentity) error
+}
+
+// IdentityRepositoryImpl implements the IdentityRepository interface
+type IdentityRepositoryImpl struct {
+	db *database.Service
+}
+
+func NewIdentityRepository(db *database.Service) *IdentityRepositoryImpl {
+	return &IdentityRepositoryImpl{db: db}
+}
+
+// GetByProvider returns the identity of the provider's user subject, or nil when nobody signed in as them yet
+func (repo *IdentityRepositoryImpl) GetByProvider(ctx context.Context, provider entity.IdentityProvider, subject string) (*entity.UserIdentity, error) {
+	var identity entity.UserIdentity
+	if err := repo.db.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+	return &identity, nil
+}
+
+func (repo *IdentityRepositoryImpl) Create(ctx context.Context, identity *entity.UserIdentity) error {
+	if err := repo.db.WithContext(ctx).Omit(clause.Associations).Create(identity).Error; err != nil {
+		return fmt.Errorf("failed to create identity: %w", err)
+	}
+	return nil
+}
+
+// CreateForUnverified links the identity to a user who never verified their email, handing the account over to the
+// identity's owner: whoever set the password may not own the email, so it is cleared & every session is revoked
+func (repo *IdentityRepositoryImpl) CreateForUnverified(ctx context.Context, identity *entity.UserIdentity) error {
+	return repo.db.Transaction(ctx, func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&entity.User{}).Where("id = ?", identity.UserID).
+			Updates(map[string]interface{}{"password_hash": "", "is_verified": true, "updated_at": now})
+		if result.Error != nil {
+			return fmt.Errorf("failed to claim user: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return entity.ErrUserNotFound
+		}
+		if err := revokeUserSessions(tx, identity.UserID, uuid.Nil, now); err != nil {
+			return err
+		}
+		if err := tx.Omit(clause.Associations).Create(identity).Error; err != nil {
+			return fmt.Errorf("failed to create identity: %w", err)
+		}
+		return nil
+	})
+}
\ No newline at end of file
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/infrastructure/database"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// errTokenUnusable is returned by consume for tokens that are unknown, used, expired or meant for something else
+var errTokenUnusable = errors.New("one-time token cannot be used")
+
+// OneTimeTokenRepository : interface for the server-side records of one-time tokens & what using them does
+type OneTimeTokenRepository interface {
+	Create(ctx context.Context, token *entity.OneTimeToken) error
+	// LastCreatedAt returns when the user was last given a token for purpose, or nil if never
+	LastCreatedAt(ctx context.Context, userID uuid.UUID, purpose entity.TokenPurpose) (*time.Time, error)
+	VerifyEmail(ctx context.Context, tokenID uuid.UUID) error
+	ResetPassword(ctx context.Context, tokenID uuid.UUID, passwordHash string) error
+}
+
+// OneTimeTokenRepositoryImpl implements the OneTimeTokenRepository interface
+type OneTimeTokenRepositoryImpl struct {
+	db *database.Service
+}
+
+func NewOneTimeTokenRepository(db *database.Service) *OneTimeTokenRepositoryImpl {
+	return &OneTimeTokenRepositoryImpl{db: db}
+}
+
+func (repo *OneTimeTokenRepositoryImpl) Create(ctx context.Context, token *entity.OneTimeToken) error {
+	if err := repo.db.WithContext(ctx).Omit(clause.Associations).Create(token).Error; err != nil {
+		return fmt.Errorf("failed to create token: %w", err)
+	}
+	return nil
+}
+
+func (repo *OneTimeTokenRepositoryImpl) LastCreatedAt(ctx context.Context, userID uuid.UUID, purpose entity.TokenPurpose) (*time.Time, error) {
+	var token entity.OneTimeToken
+	err := repo.db.WithContext(ctx).Where("user_id = ? AND purpose = ?", userID, purpose).
+		Order("created_at DESC").First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+	return &token.CreatedAt, nil
+}
+
+// VerifyEmail uses an email verification token and marks its user's email as verified.
+// It returns entity.ErrInvalidVerificationToken when the token cannot be used
+func (repo *OneTimeTokenRepositoryImpl) VerifyEmail(ctx context.Context, tokenID uuid.UUID) error {
+	err := repo.db.Transaction(ctx, func(tx *gorm.DB) error {
+		token, err := consume(tx, tokenID, entity.PurposeEmailVerification)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&entity.User{}).Where("id = ?", token.UserID).
+			Updates(map[string]interface{}{"is_verified": true, "updated_at": time.Now()}).Error; err != nil {
+			return fmt.Errorf("failed to verify user: %w", err)
+		}
+		return nil
+	})
+	if errors.Is(err, errTokenUnusable) {
+		return entity.ErrInvalidVerificationToken
+	}
+	return err
+}
+
+// ResetPassword uses a password reset token: it sets its user's password and signs them out everywhere.
+// Getting the emailed token also proves the user's email, which is marked verified.
+// Every other reset token of the user stops working too, as they were issued for the password being replaced.
+// It returns entity.ErrInvalidResetToken when the token cannot be used
+func (repo *OneTimeTokenRepositoryImpl) ResetPassword(ctx context.Context, tokenID uuid.UUID, passwordHash string) error {
+	err := repo.db.Transaction(ctx, func(tx *gorm.DB) error {
+		token, err := consume(tx, tokenID, entity.PurposePasswordReset)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&entity.User{}).Where("id = ?", token.UserID).
+			Updates(map[string]interface{}{"password_hash": passwordHash, "is_verified": true, "updated_at": now}).Error; err != nil {
+			return fmt.Errorf("failed to reset password: %w", err)
+		}
+		if err := tx.Model(&entity.OneTimeToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", token.UserID, entity.PurposePasswordReset).
+			Update("used_at", now).Error; err != nil {
+			return fmt.Errorf("failed to invalidate reset tokens: %w", err)
+		}
+		return revokeUserSessions(tx, token.UserID, uuid.Nil, now)
+	})
+	if errors.Is(err, errTokenUnusable) {
+		return entity.ErrInvalidResetToken
+	}
+	return err
+}
+
+// consume locks a token and marks it used, or returns errTokenUnusable
+func consume(tx *gorm.DB, id uuid.UUID, purpose entity.TokenPurpose) (*entity.OneTimeToken, error) {
+	var token entity.OneTimeToken
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND purpose = ?", id, purpose).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errTokenUnusable
+		}
+		return nil, fmt.Errorf("failed to lock token: %w", err)
+	}
+
+	now := time.Now()
+	if !token.Usable(now) {
+		return nil, errTokenUnusable
+	}
+	if err := tx.Model(&token).Update("used_at", now).Error; err != nil {
+		return nil, fmt.Errorf("failed to use token: %w", err)
+	}
+	return &token, nil
+}
\ No newline at end of file
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/infrastructure/database"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// SessionRepository : interface for sessions & the server-side records of their refresh tokens
+type SessionRepository interface {
+	Create(ctx context.Context, session *entity.Session, token *entity.RefreshToken) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Session, error)
+	GetActiveByUserID(ctx context.Context, userID uuid.UUID, since time.Time) ([]*entity.Session, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+	RevokeAllByUserID(ctx context.Context, userID uuid.UUID) error
+	RevokedSince(ctx context.Context, since time.Time) ([]uuid.UUID, error)
+	Touch(ctx context.Context, lastSeen map[uuid.UUID]time.Time) error
+
+	GetRefreshToken(ctx context.Context, id uuid.UUID) (*entity.RefreshToken, error)
+	Rotate(ctx context.Context, id uuid.UUID, next *entity.RefreshToken, device entity.Device) error
+}
+
+// SessionRepositoryImpl implements the SessionRepository interface
+type SessionRepositoryImpl struct {
+	db *database.Service
+}
+
+func NewSessionRepository(db *database.Service) *SessionRepositoryImpl {
+	return &SessionRepositoryImpl{db: db}
+}
+
+// Create records a new session with its first refresh token
+func (repo *SessionRepositoryImpl) Create(ctx context.Context, session *entity.Session, token *entity.RefreshToken) error {
+	return repo.db.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(session).Error; err != nil {
+			return fmt.Errorf("failed to create session: %w", err)
+		}
+		token.FamilyID = session.ID
+		token.UserID = session.UserID
+		if err := tx.Omit(clause.Associations).Create(token).Error; err != nil {
+			return fmt.Errorf("failed to create refresh token: %w", err)
+		}
+		return nil
+	})
+}
+
+func (repo *SessionRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*entity.Session, error) {
+	var session entity.Session
+	if err := repo.db.WithContext(ctx).Where("id = ?", id).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return &session, nil
+}
+
+// GetActiveByUserID returns the user's sessions that are not revoked and were seen at or after since, most recently seen first
+func (repo *SessionRepositoryImpl) GetActiveByUserID(ctx context.Context, userID uuid.UUID, since time.Time) ([]*entity.Session, error) {
+	var sessions []*entity.Session
+	if err := repo.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND last_seen_at >= ?", userID, since).
+		Order("last_seen_at DESC").Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// Revoke ends a session and revokes its refresh tokens. Revoking a revoked session changes nothing
+func (repo *SessionRepositoryImpl) Revoke(ctx context.Context, id uuid.UUID) error {
+	now := time.Now()
+	return repo.db.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.Session{}).Where("id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", now).Error; err != nil {
+			return fmt.Errorf("failed to revoke session: %w", err)
+		}
+		if err := tx.Model(&entity.RefreshToken{}).Where("family_id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", now).Error; err != nil {
+			return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+		}
+		return nil
+	})
+}
+
+// RevokeAllByUserID ends every session of the user and revokes their refresh tokens
+func (repo *SessionRepositoryImpl) RevokeAllByUserID(ctx context.Context, userID uuid.UUID) error {
+	return repo.db.Transaction(ctx, func(tx *gorm.DB) error {
+		return revokeUserSessions(tx, userID, uuid.Nil, time.Now())
+	})
+}
+
+// revokeUserSessions ends every session of the user but except (none when uuid.Nil) and revokes their refresh tokens, as part of tx
+func revokeUserSessions(tx *gorm.DB, userID, except uuid.UUID, now time.Time) error {
+	if err := tx.Model(&entity.Session{}).Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, except).
+		Update("revoked_at", now).Error; err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if err := tx.Model(&entity.RefreshToken{}).Where("user_id = ? AND family_id <> ? AND revoked_at IS NULL", userID, except).
+		Update("revoked_at", now).Error; err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return nil
+}
+
+// RevokedSince returns the IDs of the sessions revoked at or after since
+func (repo *SessionRepositoryImpl) RevokedSince(ctx context.Context, since time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := repo.db.WithContext(ctx).Model(&entity.Session{}).
+		Where("revoked_at >= ?", since).Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to get revoked sessions: %w", err)
+	}
+	return ids, nil
+}
+
+// Touch records when sessions were last seen. Times older than the recorded ones are ignored
+func (repo *SessionRepositoryImpl) Touch(ctx context.Context, lastSeen map[uuid.UUID]time.Time) error {
+	for id, seenAt := range lastSeen {
+		if err := repo.db.WithContext(ctx).Model(&entity.Session{}).
+			Where("id = ? AND last_seen_at < ?", id, seenAt).
+			Update("last_seen_at", seenAt).Error; err != nil {
+			return fmt.Errorf("failed to update session: %w", err)
+		}
+	}
+	return nil
+}
+
+func (repo *SessionRepositoryImpl) GetRefreshToken(ctx context.Context, id uuid.UUID) (*entity.RefreshToken, error) {
+	var token entity.RefreshToken
+	if err := repo.db.WithContext(ctx).Where("id = ?", id).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	return &token, nil
+}
+
+// errRotated rolls back the rotation of a token that was rotated before
+var errRotated = errors.New("refresh token already rotated")
+
+// Rotate marks the token as used and records next, the token replacing it, in the same session,
+// which is seen from device now. Of two concurrent rotations of one token only the first succeeds.
+// Presenting a rotated token revokes its session and returns entity.ErrRefreshTokenReused
+func (repo *SessionRepositoryImpl) Rotate(ctx context.Context, id uuid.UUID, next *entity.RefreshToken, device entity.Device) error {
+	var current entity.RefreshToken
+	err := repo.db.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&current).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return entity.ErrInvalidRefreshToken
+			}
+			return fmt.Errorf("failed to lock refresh token: %w", err)
+		}
+
+		now := time.Now()
+		if err := rotatable(&current, now); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&current).Update("rotated_at", now).Error; err != nil {
+			return fmt.Errorf("failed to rotate refresh token: %w", err)
+		}
+		next.FamilyID = current.FamilyID
+		next.UserID = current.UserID
+		if err := tx.Omit(clause.Associations).Create(next).Error; err != nil {
+			return fmt.Errorf("failed to create refresh token: %w", err)
+		}
+		if err := tx.Model(&entity.Session{}).Where("id = ?", current.FamilyID).Updates(map[string]interface{}{
+			"user_agent":   device.UserAgent,
+			"ip_address":   device.IPAddress,
+			"last_seen_at": now,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update session: %w", err)
+		}
+		return nil
+	})
+
+	if errors.Is(err, errRotated) {
+		if err := repo.Revoke(ctx, current.FamilyID); err != nil {
+			return err
+		}
+		return entity.ErrRefreshTokenReused
+	}
+	return err
+}
+
+// rotatable tells whether token can be rotated at now: errRotated when it was rotated before, which is checked first
+// so that a reused token is caught even once its session is revoked, entity.ErrInvalidRefreshToken when it is revoked or expired
+func rotatable(token *entity.RefreshToken, now time.Time) error {
+	if token.RotatedAt != nil {
+		return errRotated
+	}
+	if !token.Usable(now) {
+		return entity.ErrInvalidRefreshToken
+	}
+	return nil
+}
\ No newline at end of file
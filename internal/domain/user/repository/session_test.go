@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/entity"
+	"github.com/google/uuid"
+)
+
+func TestRotatable(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Minute)
+
+	tests := []struct {
+		name    string
+		token   entity.RefreshToken
+		wantErr error
+	}{
+		{name: "fresh token", token: entity.RefreshToken{ExpiresAt: now.Add(time.Hour)}},
+		{name: "rotated token", token: entity.RefreshToken{ExpiresAt: now.Add(time.Hour), RotatedAt: &earlier}, wantErr: errRotated},
+		{name: "rotated token of a revoked session", token: entity.RefreshToken{ExpiresAt: now.Add(time.Hour), RotatedAt: &earlier, RevokedAt: &now}, wantErr: errRotated},
+		{name: "revoked token", token: entity.RefreshToken{ExpiresAt: now.Add(time.Hour), RevokedAt: &earlier}, wantErr: entity.ErrInvalidRefreshToken},
+		{name: "expired token", token: entity.RefreshToken{ExpiresAt: earlier}, wantErr: entity.ErrInvalidRefreshToken},
+		{name: "token expiring now", token: entity.RefreshToken{ExpiresAt: now}, wantErr: entity.ErrInvalidRefreshToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.token.ID, tt.token.FamilyID = uuid.New(), uuid.New()
+			if err := rotatable(&tt.token, now); !errors.Is(err, tt.wantErr) {
+				t.Errorf("rotatable = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
\ No newline at end of file
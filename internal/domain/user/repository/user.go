@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/constants"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/entity"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/infrastructure/database"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"strings"
 	"time"
 )
 
@@ -15,8 +17,13 @@ import (
 type UserRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error)
 	GetByEmail(ctx context.Context, email string) (*entity.User, error)
+	IsVerified(ctx context.Context, id uuid.UUID) (bool, error)
+	List(ctx context.Context, filter entity.UserFilter) ([]*entity.User, int64, error)
 	Create(ctx context.Context, user *entity.User) error
 	Update(ctx context.Context, user *entity.User) error
+	UpdateStatus(ctx context.Context, id uuid.UUID, status constants.UserStatus) error
+	UpdateRole(ctx context.Context, id uuid.UUID, role constants.Role) error
+	UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string, keepSessionID uuid.UUID) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
@@ -33,7 +40,7 @@ func (repo *UserRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*ent
 	var user entity.User
 	if err := repo.db.DB.WithContext(ctx).Where("id = ?", id).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, entity.ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -44,13 +51,58 @@ func (repo *UserRepositoryImpl) GetByEmail(ctx context.Context, email string) (*
 	var user entity.User
 	if err := repo.db.DB.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, entity.ErrUserNotFound
 		}
 		return nil, err
 	}
 	return &user, nil
 }
 
+// IsVerified reports whether the user has verified their email
+func (repo *UserRepositoryImpl) IsVerified(ctx context.Context, id uuid.UUID) (bool, error) {
+	var verified []bool
+	if err := repo.db.DB.WithContext(ctx).Model(&entity.User{}).Where("id = ?", id).Pluck("is_verified", &verified).Error; err != nil {
+		return false, fmt.Errorf("failed to get user: %w", err)
+	}
+	if len(verified) == 0 {
+		return false, entity.ErrUserNotFound
+	}
+	return verified[0], nil
+}
+
+// List returns a page of the users matching the filter, newest first, along with how many match in all
+func (repo *UserRepositoryImpl) List(ctx context.Context, filter entity.UserFilter) ([]*entity.User, int64, error) {
+	query := repo.db.DB.WithContext(ctx).Model(&entity.User{})
+	if filter.Query != "" {
+		pattern := "%" + escapeLike(filter.Query) + "%"
+		query = query.Where("first_name ILIKE ? OR last_name ILIKE ? OR email ILIKE ?", pattern, pattern, pattern)
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	var users []*entity.User
+	if err := query.Order("created_at DESC, id").
+		Offset((filter.Page - 1) * filter.Limit).Limit(filter.Limit).
+		Find(&users).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+	return users, total, nil
+}
+
+// escapeLike escapes the wildcards of LIKE patterns, so searches match them literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
 func (repo *UserRepositoryImpl) Create(ctx context.Context, user *entity.User) error {
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
@@ -72,6 +124,53 @@ func (repo *UserRepositoryImpl) Update(ctx context.Context, user *entity.User) e
 	return nil
 }
 
+// UpdateStatus sets the user's status. Any status but ACTIVE also signs the user out of every session
+func (repo *UserRepositoryImpl) UpdateStatus(ctx context.Context, id uuid.UUID, status constants.UserStatus) error {
+	return repo.db.Transaction(ctx, func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&entity.User{}).Where("id = ?", id).
+			Updates(map[string]interface{}{"status": status, "updated_at": now})
+		if result.Error != nil {
+			return fmt.Errorf("failed to update user status: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return entity.ErrUserNotFound
+		}
+		if status == constants.ACTIVE {
+			return nil
+		}
+		return revokeUserSessions(tx, id, uuid.Nil, now)
+	})
+}
+
+// UpdateRole gives the user a new role and signs them out of every session, so tokens carrying the old role stop working
+func (repo *UserRepositoryImpl) UpdateRole(ctx context.Context, id uuid.UUID, role constants.Role) error {
+	return repo.db.Transaction(ctx, func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&entity.User{}).Where("id = ?", id).
+			Updates(map[string]interface{}{"role": role, "updated_at": now})
+		if result.Error != nil {
+			return fmt.Errorf("failed to update user role: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return entity.ErrUserNotFound
+		}
+		return revokeUserSessions(tx, id, uuid.Nil, now)
+	})
+}
+
+// UpdatePassword sets the user's password hash and signs them out of every session but keepSessionID
+func (repo *UserRepositoryImpl) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string, keepSessionID uuid.UUID) error {
+	return repo.db.Transaction(ctx, func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&entity.User{}).Where("id = ?", id).
+			Updates(map[string]interface{}{"password_hash": passwordHash, "updated_at": now}).Error; err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+		return revokeUserSessions(tx, id, keepSessionID, now)
+	})
+}
+
 func (repo *UserRepositoryImpl) Delete(ctx context.Context, id uuid.UUID) error {
 	if err := repo.db.DB.WithContext(ctx).Delete(&entity.User{}, id).Error; err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
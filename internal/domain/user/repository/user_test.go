@@ -1 +1,18 @@
-package repository
\ No newline at end of file
+package repository
+
+import "testing"
+
+func TestEscapeLike(t *testing.T) {
+	tests := map[string]string{
+		"ann":        "ann",
+		"100%":       `100\%`,
+		"first_name": `first\_name`,
+		`back\slash`: `back\\slash`,
+		`%_\`:        `\%\_\\`,
+	}
+	for in, want := range tests {
+		if got := escapeLike(in); got != want {
+			t.Errorf("escapeLike(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
\ No newline at end of file
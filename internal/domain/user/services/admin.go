@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/constants"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// ManagedUser returns a user whose account the actor may change, or entity.ErrRoleNotAllowed
+func (u *UserServiceImpl) ManagedUser(ctx context.Context, actor entity.Actor, userID uuid.UUID) (*entity.User, error) {
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.CanManage(user) {
+		return nil, entity.ErrRoleNotAllowed
+	}
+	return user, nil
+}
+
+// ListUsers returns a page of the users matching the filter, and how many match in all
+func (u *UserServiceImpl) ListUsers(ctx context.Context, filter entity.UserFilter) ([]*entity.User, int64, error) {
+	return u.userRepo.List(ctx, filter)
+}
+
+// InviteUser creates an account with a role below the actor's, and emails its owner a link to choose their password,
+// which works for inviteTTL. Until then the account cannot be signed in to
+func (u *UserServiceImpl) InviteUser(ctx context.Context, actor entity.Actor, req *entity.InviteUserRequest) (*entity.User, error) {
+	if !actor.CanAssign(req.Role) {
+		return nil, entity.ErrRoleNotAllowed
+	}
+
+	// no password: it is chosen with the invitation, which is a password reset token
+	user := &entity.User{
+		ID:        uuid.New(),
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Email:     req.Email,
+		Phone:     req.Phone,
+		Role:      req.Role,
+		Status:    constants.ACTIVE,
+	}
+	if err := u.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	token, record, err := u.issueOneTimeToken(ctx, user.ID, entity.PurposePasswordReset, u.inviteTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		err := utils.SendInviteEmail(user.Email, utils.InviteEmailData{
+			Name:      user.FirstName,
+			Role:      string(user.Role),
+			Token:     token,
+			ExpiresAt: record.ExpiresAt,
+		})
+		if err != nil {
+			fmt.Println("Failed to send invite email:", err)
+		}
+	}()
+	return user, nil
+}
+
+// ChangeRole gives a user the actor manages a role below the actor's. The user is signed out everywhere,
+// so that no token keeps the old role
+func (u *UserServiceImpl) ChangeRole(ctx context.Context, actor entity.Actor, userID uuid.UUID, role constants.Role) (*entity.User, error) {
+	user, err := u.ManagedUser(ctx, actor, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.CanAssign(role) {
+		return nil, entity.ErrRoleNotAllowed
+	}
+	if user.Role == role {
+		return user, nil
+	}
+
+	if err := u.userRepo.UpdateRole(ctx, user.ID, role); err != nil {
+		return nil, err
+	}
+	return u.userRepo.GetByID(ctx, user.ID)
+}
+
+// ChangeStatus activates or deactivates the account of a user the actor manages. Deactivating signs the user out everywhere
+func (u *UserServiceImpl) ChangeStatus(ctx context.Context, actor entity.Actor, userID uuid.UUID, status constants.UserStatus) (*entity.User, error) {
+	user, err := u.ManagedUser(ctx, actor, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.userRepo.UpdateStatus(ctx, user.ID, status); err != nil {
+		return nil, err
+	}
+	return u.userRepo.GetByID(ctx, user.ID)
+}
+
+// SignOutUser signs a user the actor manages out of every session. Their access tokens stop working within middleware.RevocationCacheTTL
+func (u *UserServiceImpl) SignOutUser(ctx context.Context, actor entity.Actor, userID uuid.UUID) error {
+	user, err := u.ManagedUser(ctx, actor, userID)
+	if err != nil {
+		return err
+	}
+	return u.sessionRepo.RevokeAllByUserID(ctx, user.ID)
+}
\ No newline at end of file
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gatimugabriel/hotel-reservation-system/internal/constants"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/entity"
+	"github.com/google/uuid"
+)
+
+func TestManagersCannotEscalate(t *testing.T) {
+	manager := &entity.User{ID: uuid.New(), Role: constants.MANAGER}
+	admin := &entity.User{ID: uuid.New(), Role: constants.ADMIN}
+	staff := &entity.User{ID: uuid.New(), Role: constants.STAFF}
+	users := &memoryUsers{users: map[uuid.UUID]*entity.User{manager.ID: manager, admin.ID: admin, staff.ID: staff}}
+	service := &UserServiceImpl{userRepo: users}
+	actor := entity.NewActor(manager.ID, string(manager.Role))
+	ctx := context.Background()
+
+	if _, err := service.InviteUser(ctx, actor, &entity.InviteUserRequest{Email: "new@example.com", Role: constants.ADMIN}); !errors.Is(err, entity.ErrRoleNotAllowed) {
+		t.Errorf("manager inviting an admin = %v, want ErrRoleNotAllowed", err)
+	}
+	if len(users.users) != 3 {
+		t.Errorf("%d users, want no account created", len(users.users))
+	}
+
+	refusals := map[string]func() error{
+		"promote staff to admin": func() error {
+			_, err := service.ChangeRole(ctx, actor, staff.ID, constants.ADMIN)
+			return err
+		},
+		"promote staff to manager": func() error {
+			_, err := service.ChangeRole(ctx, actor, staff.ID, constants.MANAGER)
+			return err
+		},
+		"demote an admin": func() error {
+			_, err := service.ChangeRole(ctx, actor, admin.ID, constants.GUEST)
+			return err
+		},
+		"deactivate an admin": func() error {
+			_, err := service.ChangeStatus(ctx, actor, admin.ID, constants.INACTIVE)
+			return err
+		},
+		"change their own role": func() error {
+			_, err := service.ChangeRole(ctx, actor, manager.ID, constants.STAFF)
+			return err
+		},
+		"sign out an admin": func() error {
+			return service.SignOutUser(ctx, actor, admin.ID)
+		},
+	}
+	for name, attempt := range refusals {
+		if err := attempt(); !errors.Is(err, entity.ErrRoleNotAllowed) {
+			t.Errorf("manager trying to %s = %v, want ErrRoleNotAllowed", name, err)
+		}
+	}
+	if staff.Role != constants.STAFF || admin.Role != constants.ADMIN || manager.Role != constants.MANAGER {
+		t.Errorf("roles changed to %s, %s, %s", staff.Role, admin.Role, manager.Role)
+	}
+}
\ No newline at end of file
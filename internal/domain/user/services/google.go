@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/constants"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/entity"
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+	"net/http"
+)
+
+// SignInWithGoogle finishes a sign in started at Google: it exchanges the authorization code, with the PKCE verifier
+// the sign in started with, and signs in the Google user's account, creating it on their first sign in
+func (u *UserServiceImpl) SignInWithGoogle(ctx context.Context, code, verifier string, device entity.Device) (string, string, error) {
+	user, err := u.googleUser(ctx, code, verifier)
+	if err != nil {
+		return "", "", err
+	}
+	return u.IssueTokens(ctx, user, device)
+}
+
+// googleUser returns the account of the Google user who authorized code.
+// A Google user not seen before is linked to the account with their email, or given a new GUEST account
+func (u *UserServiceImpl) googleUser(ctx context.Context, code, verifier string) (*entity.User, error) {
+	profile, err := u.googleProfile(ctx, code, verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := u.identityRepo.GetByProvider(ctx, entity.ProviderGoogle, profile.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	var user *entity.User
+	if identity != nil {
+		user, err = u.userRepo.GetByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		user, err = u.linkGoogleUser(ctx, profile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if user.Status != constants.ACTIVE {
+		return nil, entity.ErrAccountInactive
+	}
+	return user, nil
+}
+
+// linkGoogleUser links a Google user signing in for the first time to the account with their email, creating it if needed.
+// An account whose email was never verified is taken over by the Google user
+func (u *UserServiceImpl) linkGoogleUser(ctx context.Context, profile *entity.GoogleProfile) (*entity.User, error) {
+	// anyone can put any address on a Google account: only a verified one proves the account's owner
+	if !profile.EmailVerified {
+		return nil, entity.ErrEmailNotVerified
+	}
+
+	user, err := u.userRepo.GetByEmail(ctx, profile.Email)
+	if err != nil && !errors.Is(err, entity.ErrUserNotFound) {
+		return nil, err
+	}
+	if user == nil {
+		// no password: the account can only be signed in to with Google, until one is set
+		user = &entity.User{
+			ID:         uuid.New(),
+			FirstName:  profile.GivenName,
+			LastName:   profile.FamilyName,
+			Email:      profile.Email,
+			Role:       constants.GUEST,
+			IsVerified: true,
+			Status:     constants.ACTIVE,
+		}
+		if err := u.userRepo.Create(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	identity := &entity.UserIdentity{
+		ID:       uuid.New(),
+		UserID:   user.ID,
+		Provider: entity.ProviderGoogle,
+		Subject:  profile.Subject,
+		Email:    profile.Email,
+	}
+	if user.IsVerified {
+		if err := u.identityRepo.Create(ctx, identity); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+
+	// anyone could have signed up with the email without owning it: the Google user takes the account over,
+	// and whoever set its password is locked out
+	if err := u.identityRepo.CreateForUnverified(ctx, identity); err != nil {
+		return nil, err
+	}
+	user.PasswordHash = ""
+	user.IsVerified = true
+	return user, nil
+}
+
+// googleProfile exchanges the authorization code for a token and fetches the profile of the user who granted it
+func (u *UserServiceImpl) googleProfile(ctx context.Context, code, verifier string) (*entity.GoogleProfile, error) {
+	token, err := u.googleOAuth.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", entity.ErrGoogleSignInFailed, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.googleUserInfoURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", entity.ErrGoogleSignInFailed, err)
+	}
+	res, err := u.googleOAuth.Client(ctx, token).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", entity.ErrGoogleSignInFailed, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: fetching profile returned %s", entity.ErrGoogleSignInFailed, res.Status)
+	}
+
+	var profile entity.GoogleProfile
+	if err := json.NewDecoder(res.Body).Decode(&profile); err != nil {
+		return nil, fmt.Errorf("%w: %v", entity.ErrGoogleSignInFailed, err)
+	}
+	if profile.Subject == "" || profile.Email == "" {
+		return nil, fmt.Errorf("%w: profile has no subject or email", entity.ErrGoogleSignInFailed)
+	}
+	return &profile, nil
+}
\ No newline at end of file
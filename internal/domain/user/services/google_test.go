@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gatimugabriel/hotel-reservation-system/internal/constants"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/repository"
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+)
+
+// memoryUsers : in-memory UserRepository. Methods the tests do not need panic through the nil embedded interface
+type memoryUsers struct {
+	repository.UserRepository
+	users map[uuid.UUID]*entity.User
+}
+
+func (m *memoryUsers) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	if user, ok := m.users[id]; ok {
+		return user, nil
+	}
+	return nil, entity.ErrUserNotFound
+}
+
+func (m *memoryUsers) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	for _, user := range m.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, entity.ErrUserNotFound
+}
+
+func (m *memoryUsers) Create(ctx context.Context, user *entity.User) error {
+	m.users[user.ID] = user
+	return nil
+}
+
+// memoryIdentities : in-memory IdentityRepository. Identities of users taken over are also recorded in takenOver
+type memoryIdentities struct {
+	identities []*entity.UserIdentity
+	takenOver  []*entity.UserIdentity
+}
+
+func (m *memoryIdentities) GetByProvider(ctx context.Context, provider entity.IdentityProvider, subject string) (*entity.UserIdentity, error) {
+	for _, identity := range m.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryIdentities) Create(ctx context.Context, identity *entity.UserIdentity) error {
+	m.identities = append(m.identities, identity)
+	return nil
+}
+
+func (m *memoryIdentities) CreateForUnverified(ctx context.Context, identity *entity.UserIdentity) error {
+	m.takenOver = append(m.takenOver, identity)
+	return m.Create(ctx, identity)
+}
+
+// googleStandIn serves Google's token & userinfo endpoints, granting a token for "good-code" with "good-verifier"
+// and returning profile to its bearer
+func googleStandIn(t *testing.T, profile *entity.GoogleProfile) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "good-code" || r.FormValue("code_verifier") != "good-verifier" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "google-token", "token_type": "Bearer", "expires_in": 3600})
+	})
+	mux.HandleFunc("GET /userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer google-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(profile)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newGoogleTestService(server *httptest.Server, users *memoryUsers, identities *memoryIdentities) *UserServiceImpl {
+	return &UserServiceImpl{
+		userRepo:     users,
+		identityRepo: identities,
+		googleOAuth: &oauth2.Config{
+			ClientID:     "client",
+			ClientSecret: "secret",
+			Endpoint:     oauth2.Endpoint{TokenURL: server.URL + "/token", AuthStyle: oauth2.AuthStyleInParams},
+		},
+		googleUserInfoURL: server.URL + "/userinfo",
+	}
+}
+
+func TestGoogleUser(t *testing.T) {
+	existing := &entity.User{ID: uuid.New(), Email: "james@example.com", Role: constants.MANAGER, Status: constants.ACTIVE, PasswordHash: "hash", IsVerified: true}
+	profile := &entity.GoogleProfile{Subject: "google-1", Email: "ann@example.com", EmailVerified: true, GivenName: "Ann", FamilyName: "Lee"}
+
+	tests := []struct {
+		name     string
+		profile  entity.GoogleProfile
+		code     string
+		wantErr  error
+		wantUser func(t *testing.T, user *entity.User, users *memoryUsers)
+	}{
+		{
+			name: "new google user gets a guest account", profile: *profile, code: "good-code",
+			wantUser: func(t *testing.T, user *entity.User, users *memoryUsers) {
+				if len(users.users) != 2 || user.Role != constants.GUEST || user.FirstName != "Ann" || !user.IsVerified {
+					t.Errorf("user = %+v among %d users, want a new verified GUEST", user, len(users.users))
+				}
+			},
+		},
+		{
+			name: "existing account is linked by email", profile: entity.GoogleProfile{Subject: "google-2", Email: existing.Email, EmailVerified: true}, code: "good-code",
+			wantUser: func(t *testing.T, user *entity.User, users *memoryUsers) {
+				if user.ID != existing.ID || len(users.users) != 1 {
+					t.Errorf("user = %v, want the existing account %v", user.ID, existing.ID)
+				}
+				if user.PasswordHash != "hash" {
+					t.Errorf("password hash = %q, want the verified account's kept", user.PasswordHash)
+				}
+			},
+		},
+		{
+			name: "unverified email is not trusted", profile: entity.GoogleProfile{Subject: "google-3", Email: existing.Email}, code: "good-code",
+			wantErr: entity.ErrEmailNotVerified,
+		},
+		{
+			name: "rejected code", profile: *profile, code: "bad-code",
+			wantErr: entity.ErrGoogleSignInFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &memoryUsers{users: map[uuid.UUID]*entity.User{existing.ID: existing}}
+			identities := &memoryIdentities{}
+			service := newGoogleTestService(googleStandIn(t, &tt.profile), users, identities)
+
+			user, err := service.googleUser(context.Background(), tt.code, "good-verifier")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if len(identities.takenOver) != 0 {
+				t.Errorf("taken over = %+v, want no account taken over", identities.takenOver)
+			}
+			if err != nil {
+				if len(identities.identities) != 0 {
+					t.Errorf("identities = %+v, want none linked", identities.identities)
+				}
+				return
+			}
+			tt.wantUser(t, user, users)
+			if len(identities.identities) != 1 || identities.identities[0].UserID != user.ID || identities.identities[0].Subject != tt.profile.Subject {
+				t.Errorf("identities = %+v, want one linking %s to the user", identities.identities, tt.profile.Subject)
+			}
+		})
+	}
+}
+
+func TestGoogleUserSignsInAgainByIdentity(t *testing.T) {
+	user := &entity.User{ID: uuid.New(), Email: "old@example.com", Status: constants.ACTIVE}
+	users := &memoryUsers{users: map[uuid.UUID]*entity.User{user.ID: user}}
+	// the Google account's email changed since it was linked: the link, not the email, decides
+	identities := &memoryIdentities{identities: []*entity.UserIdentity{{UserID: user.ID, Provider: entity.ProviderGoogle, Subject: "google-1"}}}
+	server := googleStandIn(t, &entity.GoogleProfile{Subject: "google-1", Email: "new@example.com", EmailVerified: true})
+
+	got, err := newGoogleTestService(server, users, identities).googleUser(context.Background(), "good-code", "good-verifier")
+	if err != nil || got.ID != user.ID {
+		t.Fatalf("googleUser = %v, %v, want the linked account", got, err)
+	}
+	if len(users.users) != 1 || len(identities.identities) != 1 {
+		t.Errorf("%d users & %d identities, want nothing created", len(users.users), len(identities.identities))
+	}
+}
+
+func TestGoogleUserTakesOverUnverifiedAccount(t *testing.T) {
+	// someone signed up with Ann's email & a password of their own, and never verified it
+	squatted := &entity.User{ID: uuid.New(), Email: "ann@example.com", Role: constants.GUEST, Status: constants.ACTIVE, PasswordHash: "squatter-hash"}
+	users := &memoryUsers{users: map[uuid.UUID]*entity.User{squatted.ID: squatted}}
+	identities := &memoryIdentities{}
+	server := googleStandIn(t, &entity.GoogleProfile{Subject: "google-1", Email: squatted.Email, EmailVerified: true})
+
+	user, err := newGoogleTestService(server, users, identities).googleUser(context.Background(), "good-code", "good-verifier")
+	if err != nil || user.ID != squatted.ID {
+		t.Fatalf("googleUser = %v, %v, want the unverified account", user, err)
+	}
+	if len(identities.takenOver) != 1 || identities.takenOver[0].UserID != squatted.ID || identities.takenOver[0].Subject != "google-1" {
+		t.Fatalf("taken over = %+v, want the account linked through CreateForUnverified", identities.takenOver)
+	}
+	if user.PasswordHash != "" || !user.IsVerified {
+		t.Errorf("user has password hash %q & verified %v, want the password cleared & the email verified", user.PasswordHash, user.IsVerified)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/constants"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"time"
+)
+
+// ForgotPassword emails the user with the given email a single use link to reset their password, which works for passwordResetTTL.
+// It does nothing, without telling, when there is no such active user or they were sent a link less than resendWait ago,
+// so the response gives away nothing about which emails have accounts
+func (u *UserServiceImpl) ForgotPassword(ctx context.Context, email string) error {
+	user, err := u.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, entity.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.Status != constants.ACTIVE {
+		return nil
+	}
+
+	lastSentAt, err := u.tokenRepo.LastCreatedAt(ctx, user.ID, entity.PurposePasswordReset)
+	if err != nil {
+		return err
+	}
+	if lastSentAt != nil && time.Since(*lastSentAt) < u.resendWait {
+		return nil
+	}
+
+	token, record, err := u.issueOneTimeToken(ctx, user.ID, entity.PurposePasswordReset, u.passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		err := utils.SendPasswordResetEmail(user.Email, utils.PasswordResetEmailData{
+			Name:      user.FirstName,
+			Token:     token,
+			ExpiresAt: record.ExpiresAt,
+		})
+		if err != nil {
+			fmt.Println("Failed to send password reset email:", err)
+		}
+	}()
+	return nil
+}
+
+// ResetPassword uses a password reset link's token to set a new password. The user is signed out of every session:
+// their refresh tokens stop working at once, their access tokens within middleware.RevocationCacheTTL
+func (u *UserServiceImpl) ResetPassword(ctx context.Context, token, password string) error {
+	payload, err := utils.ValidateToken(token, "PASSWORD_RESET")
+	if err != nil {
+		return entity.ErrInvalidResetToken
+	}
+	rawID, _ := payload["jti"].(string)
+	tokenID, err := uuid.Parse(rawID)
+	if err != nil {
+		return entity.ErrInvalidResetToken
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	return u.tokenRepo.ResetPassword(ctx, tokenID, string(hashedPassword))
+}
\ No newline at end of file
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gatimugabriel/hotel-reservation-system/internal/constants"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/entity"
+	"github.com/google/uuid"
+)
+
+func TestForgotPasswordSendsNothingSilently(t *testing.T) {
+	active := &entity.User{ID: uuid.New(), Email: "ann@example.com", Status: constants.ACTIVE}
+	inactive := &entity.User{ID: uuid.New(), Email: "james@example.com", Status: constants.INACTIVE}
+
+	tests := []struct {
+		name  string
+		email string
+		sent  []*entity.OneTimeToken
+	}{
+		{name: "unknown email", email: "nobody@example.com"},
+		{name: "inactive account", email: inactive.Email},
+		{name: "asked again too soon", email: active.Email, sent: []*entity.OneTimeToken{{
+			ID: uuid.New(), UserID: active.ID, Purpose: entity.PurposePasswordReset, CreatedAt: time.Now().Add(-30 * time.Second),
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := &memoryTokens{tokens: tt.sent}
+			service := &UserServiceImpl{
+				userRepo:   &memoryUsers{users: map[uuid.UUID]*entity.User{active.ID: active, inactive.ID: inactive}},
+				tokenRepo:  tokens,
+				resendWait: time.Minute,
+			}
+
+			if err := service.ForgotPassword(context.Background(), tt.email); err != nil {
+				t.Fatalf("error = %v, want none: the response must not tell", err)
+			}
+			if len(tokens.tokens) != len(tt.sent) {
+				t.Errorf("%d tokens, want no new one issued", len(tokens.tokens))
+			}
+		})
+	}
+}
+
+func TestResetPasswordRejectsOtherTokens(t *testing.T) {
+	service := &UserServiceImpl{tokenRepo: &memoryTokens{}}
+	for _, token := range []string{"", "not-a-token"} {
+		if err := service.ResetPassword(context.Background(), token, "N3w-password"); !errors.Is(err, entity.ErrInvalidResetToken) {
+			t.Errorf("ResetPassword(%q) = %v, want ErrInvalidResetToken", token, err)
+		}
+	}
+}
\ No newline at end of file
@@ -2,8 +2,10 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/config"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/constants"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/entity"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/repository"
 	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils"
@@ -15,24 +17,62 @@ import (
 
 // UserService : interface for user business logic
 type UserService interface {
-	Authenticate(ctx context.Context, req *entity.UserLoginRequest) (string, string, error)
+	Authenticate(ctx context.Context, req *entity.UserLoginRequest, device entity.Device) (string, string, error)
+	IssueTokens(ctx context.Context, user *entity.User, device entity.Device) (string, string, error)
+	RefreshTokens(ctx context.Context, refreshToken string, device entity.Device) (string, string, error)
+	SignOut(ctx context.Context, refreshToken string) error
+	SignInWithGoogle(ctx context.Context, code, verifier string, device entity.Device) (string, string, error)
+	GetSessions(ctx context.Context, userID, currentSessionID uuid.UUID) ([]*entity.Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error
+
+	SendVerificationEmail(ctx context.Context, user *entity.User) error
+	ResendVerificationEmail(ctx context.Context, userID uuid.UUID) error
+	VerifyEmail(ctx context.Context, token string) error
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, password string) error
 
 	Create(ctx context.Context, req *entity.User) (*entity.User, error)
-	GetUser(ctx context.Context, userID string) (*entity.User, error)
-	UpdateProfile(ctx context.Context, userID string, user *entity.User) (*entity.User, error)
-	DeactivateAccount(id uuid.UUID) error
-	DeleteUser(ctx context.Context, userID string) error
+	GetUser(ctx context.Context, userID uuid.UUID) (*entity.User, error)
+	UpdateProfile(ctx context.Context, userID uuid.UUID, req *entity.UpdateProfileRequest) (*entity.User, error)
+	ChangePassword(ctx context.Context, userID, currentSessionID uuid.UUID, req *entity.ChangePasswordRequest) error
+	DeactivateAccount(ctx context.Context, userID uuid.UUID) error
+	DeleteUser(ctx context.Context, userID uuid.UUID) error
+
+	ManagedUser(ctx context.Context, actor entity.Actor, userID uuid.UUID) (*entity.User, error)
+	ListUsers(ctx context.Context, filter entity.UserFilter) ([]*entity.User, int64, error)
+	InviteUser(ctx context.Context, actor entity.Actor, req *entity.InviteUserRequest) (*entity.User, error)
+	ChangeRole(ctx context.Context, actor entity.Actor, userID uuid.UUID, role constants.Role) (*entity.User, error)
+	ChangeStatus(ctx context.Context, actor entity.Actor, userID uuid.UUID, status constants.UserStatus) (*entity.User, error)
+	SignOutUser(ctx context.Context, actor entity.Actor, userID uuid.UUID) error
 }
 
 type UserServiceImpl struct {
-	userRepo    repository.UserRepository
-	googleOAuth *oauth2.Config
+	userRepo          repository.UserRepository
+	sessionRepo       repository.SessionRepository
+	identityRepo      repository.IdentityRepository
+	tokenRepo         repository.OneTimeTokenRepository
+	googleOAuth       *oauth2.Config
+	googleUserInfoURL string
+
+	verificationTTL  time.Duration // how long an emailed verification link works
+	passwordResetTTL time.Duration // how long an emailed password reset link works
+	inviteTTL        time.Duration // how long the link inviting a new user to choose their password works
+	resendWait       time.Duration // how long after emailing a user a link another may be sent
 }
 
-func NewUserService(userRepo repository.UserRepository) *UserServiceImpl {
+func NewUserService(userRepo repository.UserRepository, sessionRepo repository.SessionRepository, identityRepo repository.IdentityRepository,
+	tokenRepo repository.OneTimeTokenRepository, authConfig config.AuthConfig) *UserServiceImpl {
 	return &UserServiceImpl{
-		userRepo:    userRepo,
-		googleOAuth: config.GoogleOAuthConfig,
+		userRepo:          userRepo,
+		sessionRepo:       sessionRepo,
+		identityRepo:      identityRepo,
+		tokenRepo:         tokenRepo,
+		googleOAuth:       config.GoogleOAuthConfig,
+		googleUserInfoURL: config.GoogleUserInfoURL,
+		verificationTTL:   authConfig.EmailVerificationTTL,
+		passwordResetTTL:  authConfig.PasswordResetTTL,
+		inviteTTL:         authConfig.InviteTTL,
+		resendWait:        authConfig.VerificationResendWait,
 	}
 }
 
@@ -52,7 +92,7 @@ func (u *UserServiceImpl) Create(ctx context.Context, user *entity.User) (*entit
 	return user, nil
 }
 
-func (u *UserServiceImpl) Authenticate(ctx context.Context, req *entity.UserLoginRequest) (string, string, error) {
+func (u *UserServiceImpl) Authenticate(ctx context.Context, req *entity.UserLoginRequest, device entity.Device) (string, string, error) {
 	user, err := u.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
 		return "", "", fmt.Errorf("invalid credentials")
@@ -61,36 +101,164 @@ func (u *UserServiceImpl) Authenticate(ctx context.Context, req *entity.UserLogi
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
 		return "", "", fmt.Errorf("invalid credentials")
 	}
+	if user.Status != constants.ACTIVE {
+		return "", "", entity.ErrAccountInactive
+	}
 
-	accessToken, refreshToken, err := utils.GenerateTokens((user.ID).String(), user.Role)
+	return u.IssueTokens(ctx, user, device)
+}
+
+// IssueTokens signs the user in: it starts a new session on device and returns its access & refresh token
+func (u *UserServiceImpl) IssueTokens(ctx context.Context, user *entity.User, device entity.Device) (string, string, error) {
+	now := time.Now()
+	session := &entity.Session{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		UserAgent:  device.UserAgent,
+		IPAddress:  device.IPAddress,
+		LastSeenAt: now,
+	}
+	record := &entity.RefreshToken{ID: uuid.New(), ExpiresAt: now.Add(utils.RefreshTokenTTL)}
+	if err := u.sessionRepo.Create(ctx, session, record); err != nil {
+		return "", "", err
+	}
+	return utils.GenerateTokens(user.ID.String(), user.Role, session.ID.String(), record.ID.String())
+}
+
+// RefreshTokens exchanges a refresh token for a new access & refresh token of the same session. The refresh token is single use:
+// presenting it again revokes the session, returning entity.ErrRefreshTokenReused
+func (u *UserServiceImpl) RefreshTokens(ctx context.Context, refreshToken string, device entity.Device) (string, string, error) {
+	tokenID, err := refreshTokenID(refreshToken)
+	if err != nil {
+		return "", "", err
+	}
+	user, next, err := u.rotateRefreshToken(ctx, tokenID, device)
+	if err != nil {
+		return "", "", err
+	}
+	return utils.GenerateTokens(user.ID.String(), user.Role, next.FamilyID.String(), next.ID.String())
+}
+
+// rotateRefreshToken replaces the refresh token with the given ID by the next one of its session, returned with the token's user
+func (u *UserServiceImpl) rotateRefreshToken(ctx context.Context, tokenID uuid.UUID, device entity.Device) (*entity.User, *entity.RefreshToken, error) {
+	current, err := u.sessionRepo.GetRefreshToken(ctx, tokenID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// tokens carry the role, so read the user again in case it changed
+	user, err := u.userRepo.GetByID(ctx, current.UserID)
+	if err != nil {
+		return nil, nil, entity.ErrInvalidRefreshToken
+	}
+	if user.Status != constants.ACTIVE {
+		return nil, nil, entity.ErrInvalidRefreshToken
+	}
+
+	next := &entity.RefreshToken{ID: uuid.New(), ExpiresAt: time.Now().Add(utils.RefreshTokenTTL)}
+	if err := u.sessionRepo.Rotate(ctx, current.ID, next, device); err != nil {
+		return nil, nil, err
+	}
+	return user, next, nil
+}
+
+// SignOut revokes the session of the given refresh token. Signing out with an invalid token does nothing
+func (u *UserServiceImpl) SignOut(ctx context.Context, refreshToken string) error {
+	tokenID, err := refreshTokenID(refreshToken)
+	if err != nil {
+		return nil
+	}
+	current, err := u.sessionRepo.GetRefreshToken(ctx, tokenID)
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidRefreshToken) {
+			return nil
+		}
+		return err
+	}
+	return u.sessionRepo.Revoke(ctx, current.FamilyID)
+}
 
-	return accessToken, refreshToken, nil
+// GetSessions returns the sessions of the user that can still be used, marking currentSessionID as the current one
+func (u *UserServiceImpl) GetSessions(ctx context.Context, userID, currentSessionID uuid.UUID) ([]*entity.Session, error) {
+	// a session unseen for longer than a refresh token lives can no longer be refreshed
+	sessions, err := u.sessionRepo.GetActiveByUserID(ctx, userID, time.Now().Add(-utils.RefreshTokenTTL))
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		session.Current = session.ID == currentSessionID
+	}
+	return sessions, nil
 }
 
-func (u *UserServiceImpl) GetUser(ctx context.Context, userID string) (*entity.User, error) {
-	id, err := uuid.Parse(userID)
+// RevokeSession signs the user out of one of their sessions. Its access tokens stop working within middleware.RevocationCacheTTL
+func (u *UserServiceImpl) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	session, err := u.sessionRepo.GetByID(ctx, sessionID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid user ID: %w", err)
+		return err
 	}
+	if session.UserID != userID {
+		return entity.ErrSessionNotFound
+	}
+	return u.sessionRepo.Revoke(ctx, session.ID)
+}
 
-	return u.userRepo.GetByID(ctx, id)
+// refreshTokenID verifies a refresh token and returns the ID of its server-side record
+func refreshTokenID(refreshToken string) (uuid.UUID, error) {
+	payload, err := utils.ValidateToken(refreshToken, "REFRESH")
+	if err != nil {
+		return uuid.Nil, entity.ErrInvalidRefreshToken
+	}
+	// tokens issued before sessions carry no jti: their holders have to sign in again
+	rawID, _ := payload["jti"].(string)
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return uuid.Nil, entity.ErrInvalidRefreshToken
+	}
+	return id, nil
 }
 
-func (u *UserServiceImpl) UpdateProfile(ctx context.Context, userID string, updates *entity.User) (*entity.User, error) {
-	id, err := uuid.Parse(userID)
+// issueOneTimeToken records a one-time token for purpose, working for ttl, and returns it signed along with its record
+func (u *UserServiceImpl) issueOneTimeToken(ctx context.Context, userID uuid.UUID, purpose entity.TokenPurpose, ttl time.Duration) (string, *entity.OneTimeToken, error) {
+	now := time.Now()
+	record := &entity.OneTimeToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Purpose:   purpose,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	// tokens are signed with the secret of their purpose, so one kind cannot be used as another
+	token, err := utils.GenerateOneTimeToken(string(purpose), userID.String(), record.ID.String(), ttl)
 	if err != nil {
-		return nil, fmt.Errorf("invalid user ID: %w", err)
+		return "", nil, fmt.Errorf("failed to generate %s token: %w", purpose, err)
 	}
+	if err := u.tokenRepo.Create(ctx, record); err != nil {
+		return "", nil, err
+	}
+	return token, record, nil
+}
+
+func (u *UserServiceImpl) GetUser(ctx context.Context, userID uuid.UUID) (*entity.User, error) {
+	return u.userRepo.GetByID(ctx, userID)
+}
 
-	currentUser, err := u.userRepo.GetByID(ctx, id)
+func (u *UserServiceImpl) UpdateProfile(ctx context.Context, userID uuid.UUID, req *entity.UpdateProfileRequest) (*entity.User, error) {
+	currentUser, err := u.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Update allowed fields
-	currentUser.FirstName = updates.FirstName
-	currentUser.LastName = updates.LastName
-	currentUser.Phone = updates.Phone
+	if req.FirstName != nil {
+		currentUser.FirstName = *req.FirstName
+	}
+	if req.LastName != nil {
+		currentUser.LastName = *req.LastName
+	}
+	if req.Phone != nil {
+		currentUser.Phone = *req.Phone
+	}
 	currentUser.UpdatedAt = time.Now()
 
 	if err := u.userRepo.Update(ctx, currentUser); err != nil {
@@ -100,23 +268,31 @@ func (u *UserServiceImpl) UpdateProfile(ctx context.Context, userID string, upda
 	return currentUser, nil
 }
 
-func (u *UserServiceImpl) DeactivateAccount(id uuid.UUID) error {
-	user, err := u.userRepo.GetByID(context.Background(), id)
+// ChangePassword sets a new password after checking the current one, signing the user out of every session but the current one
+func (u *UserServiceImpl) ChangePassword(ctx context.Context, userID, currentSessionID uuid.UUID, req *entity.ChangePasswordRequest) error {
+	user, err := u.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return err
 	}
+	// accounts created by signing in with Google have no password: they set one with a password reset instead
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.CurrentPassword)); err != nil {
+		return entity.ErrWrongPassword
+	}
 
-	user.Status = "INACTIVE"
-	user.UpdatedAt = time.Now()
-
-	return u.userRepo.Update(context.Background(), user)
-}
-
-func (u *UserServiceImpl) DeleteUser(ctx context.Context, userID string) error {
-	id, err := uuid.Parse(userID)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
 	if err != nil {
-		return fmt.Errorf("invalid user ID: %w", err)
+		return fmt.Errorf("failed to hash password: %w", err)
 	}
+	return u.userRepo.UpdatePassword(ctx, user.ID, string(hashedPassword), currentSessionID)
+}
+
+// DeactivateAccount suspends the account and signs the user out everywhere. It can be signed in to again once reactivated
+func (u *UserServiceImpl) DeactivateAccount(ctx context.Context, userID uuid.UUID) error {
+	return u.userRepo.UpdateStatus(ctx, userID, constants.INACTIVE)
+}
 
-	return u.userRepo.Delete(ctx, id)
+// DeleteUser closes the account for good and signs the user out everywhere.
+// The user is kept, marked DELETED, as their reservations & payments still refer to them
+func (u *UserServiceImpl) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+	return u.userRepo.UpdateStatus(ctx, userID, constants.DELETED)
 }
\ No newline at end of file
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gatimugabriel/hotel-reservation-system/internal/constants"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/repository"
+	"github.com/google/uuid"
+)
+
+// memorySessions : in-memory SessionRepository keeping refresh tokens the way the database one does.
+// Methods the tests do not need panic through the nil embedded interface
+type memorySessions struct {
+	repository.SessionRepository
+	tokens map[uuid.UUID]*entity.RefreshToken
+}
+
+func (m *memorySessions) GetRefreshToken(ctx context.Context, id uuid.UUID) (*entity.RefreshToken, error) {
+	if token, ok := m.tokens[id]; ok {
+		return token, nil
+	}
+	return nil, entity.ErrInvalidRefreshToken
+}
+
+func (m *memorySessions) Rotate(ctx context.Context, id uuid.UUID, next *entity.RefreshToken, device entity.Device) error {
+	current, ok := m.tokens[id]
+	if !ok {
+		return entity.ErrInvalidRefreshToken
+	}
+	now := time.Now()
+	if current.RotatedAt != nil {
+		m.Revoke(ctx, current.FamilyID)
+		return entity.ErrRefreshTokenReused
+	}
+	if !current.Usable(now) {
+		return entity.ErrInvalidRefreshToken
+	}
+
+	current.RotatedAt = &now
+	next.FamilyID = current.FamilyID
+	next.UserID = current.UserID
+	m.tokens[next.ID] = next
+	return nil
+}
+
+func (m *memorySessions) Revoke(ctx context.Context, id uuid.UUID) error {
+	now := time.Now()
+	for _, token := range m.tokens {
+		if token.FamilyID == id && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	user := &entity.User{ID: uuid.New(), Role: constants.GUEST, Status: constants.ACTIVE}
+	first := &entity.RefreshToken{ID: uuid.New(), FamilyID: uuid.New(), UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour)}
+	sessions := &memorySessions{tokens: map[uuid.UUID]*entity.RefreshToken{first.ID: first}}
+	service := &UserServiceImpl{userRepo: &memoryUsers{users: map[uuid.UUID]*entity.User{user.ID: user}}, sessionRepo: sessions}
+	ctx := context.Background()
+
+	signedIn, second, err := service.rotateRefreshToken(ctx, first.ID, entity.Device{})
+	if err != nil {
+		t.Fatalf("first refresh: %v", err)
+	}
+	if signedIn.ID != user.ID || second.ID == first.ID || second.FamilyID != first.FamilyID {
+		t.Fatalf("refresh gave token %v of session %v for user %v, want a new token of session %v for %v",
+			second.ID, second.FamilyID, signedIn.ID, first.FamilyID, user.ID)
+	}
+
+	// refreshing with the same token again looks like a stolen token: the whole session is revoked
+	if _, _, err := service.rotateRefreshToken(ctx, first.ID, entity.Device{}); !errors.Is(err, entity.ErrRefreshTokenReused) {
+		t.Fatalf("second refresh with the first token = %v, want ErrRefreshTokenReused", err)
+	}
+	if second.RevokedAt == nil {
+		t.Error("token issued by the first refresh is not revoked")
+	}
+	if _, _, err := service.rotateRefreshToken(ctx, second.ID, entity.Device{}); !errors.Is(err, entity.ErrInvalidRefreshToken) {
+		t.Errorf("refresh with the token of the revoked session = %v, want ErrInvalidRefreshToken", err)
+	}
+	if _, _, err := service.rotateRefreshToken(ctx, first.ID, entity.Device{}); !errors.Is(err, entity.ErrRefreshTokenReused) {
+		t.Errorf("third refresh with the first token = %v, want ErrRefreshTokenReused", err)
+	}
+}
+
+func TestRefreshTokensRefusals(t *testing.T) {
+	active := &entity.User{ID: uuid.New(), Status: constants.ACTIVE}
+	inactive := &entity.User{ID: uuid.New(), Status: constants.INACTIVE}
+	earlier := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name  string
+		token *entity.RefreshToken // nil when the token has no record
+	}{
+		{name: "unknown token"},
+		{name: "expired session", token: &entity.RefreshToken{UserID: active.ID, ExpiresAt: earlier}},
+		{name: "revoked session", token: &entity.RefreshToken{UserID: active.ID, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &earlier}},
+		{name: "inactive user", token: &entity.RefreshToken{UserID: inactive.ID, ExpiresAt: time.Now().Add(time.Hour)}},
+		{name: "deleted user", token: &entity.RefreshToken{UserID: uuid.New(), ExpiresAt: time.Now().Add(time.Hour)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions := &memorySessions{tokens: make(map[uuid.UUID]*entity.RefreshToken)}
+			tokenID := uuid.New()
+			if tt.token != nil {
+				tt.token.ID, tt.token.FamilyID = tokenID, uuid.New()
+				sessions.tokens[tokenID] = tt.token
+			}
+			service := &UserServiceImpl{
+				userRepo:    &memoryUsers{users: map[uuid.UUID]*entity.User{active.ID: active, inactive.ID: inactive}},
+				sessionRepo: sessions,
+			}
+
+			if _, _, err := service.rotateRefreshToken(context.Background(), tokenID, entity.Device{}); !errors.Is(err, entity.ErrInvalidRefreshToken) {
+				t.Fatalf("error = %v, want ErrInvalidRefreshToken", err)
+			}
+			if len(sessions.tokens) > 1 || (tt.token != nil && tt.token.RotatedAt != nil) {
+				t.Error("token was rotated")
+			}
+		})
+	}
+
+	service := &UserServiceImpl{sessionRepo: &memorySessions{}}
+	for _, token := range []string{"", "not-a-token"} {
+		if _, _, err := service.RefreshTokens(context.Background(), token, entity.Device{}); !errors.Is(err, entity.ErrInvalidRefreshToken) {
+			t.Errorf("RefreshTokens(%q) = %v, want ErrInvalidRefreshToken", token, err)
+		}
+	}
+}
\ No newline at end of file
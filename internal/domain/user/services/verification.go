@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils"
+	"github.com/google/uuid"
+	"time"
+)
+
+// SendVerificationEmail emails the user a single use link verifying their email, which works for verificationTTL.
+// The email is sent in the background: only failing to issue the link is returned
+func (u *UserServiceImpl) SendVerificationEmail(ctx context.Context, user *entity.User) error {
+	if user.IsVerified {
+		return entity.ErrAlreadyVerified
+	}
+
+	token, record, err := u.issueOneTimeToken(ctx, user.ID, entity.PurposeEmailVerification, u.verificationTTL)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		err := utils.SendVerificationEmail(user.Email, utils.VerificationEmailData{
+			Name:      user.FirstName,
+			Token:     token,
+			ExpiresAt: record.ExpiresAt,
+		})
+		if err != nil {
+			fmt.Println("Failed to send verification email:", err)
+		}
+	}()
+	return nil
+}
+
+// ResendVerificationEmail sends the user another verification link, at most once every resendWait.
+// Links sent before keep working until they expire
+func (u *UserServiceImpl) ResendVerificationEmail(ctx context.Context, userID uuid.UUID) error {
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.IsVerified {
+		return entity.ErrAlreadyVerified
+	}
+
+	lastSentAt, err := u.tokenRepo.LastCreatedAt(ctx, user.ID, entity.PurposeEmailVerification)
+	if err != nil {
+		return err
+	}
+	if lastSentAt != nil && time.Since(*lastSentAt) < u.resendWait {
+		return entity.ErrVerificationThrottled
+	}
+
+	return u.SendVerificationEmail(ctx, user)
+}
+
+// VerifyEmail uses a verification link's token, marking the email of the user it was sent to as verified
+func (u *UserServiceImpl) VerifyEmail(ctx context.Context, token string) error {
+	payload, err := utils.ValidateToken(token, "EMAIL_VERIFICATION")
+	if err != nil {
+		return entity.ErrInvalidVerificationToken
+	}
+	rawID, _ := payload["jti"].(string)
+	tokenID, err := uuid.Parse(rawID)
+	if err != nil {
+		return entity.ErrInvalidVerificationToken
+	}
+	return u.tokenRepo.VerifyEmail(ctx, tokenID)
+}
\ No newline at end of file
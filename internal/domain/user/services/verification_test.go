@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/entity"
+	"github.com/google/uuid"
+)
+
+// memoryTokens : in-memory OneTimeTokenRepository
+type memoryTokens struct {
+	tokens []*entity.OneTimeToken
+}
+
+func (m *memoryTokens) Create(ctx context.Context, token *entity.OneTimeToken) error {
+	m.tokens = append(m.tokens, token)
+	return nil
+}
+
+func (m *memoryTokens) LastCreatedAt(ctx context.Context, userID uuid.UUID, purpose entity.TokenPurpose) (*time.Time, error) {
+	var last *time.Time
+	for _, token := range m.tokens {
+		if token.UserID == userID && token.Purpose == purpose && (last == nil || token.CreatedAt.After(*last)) {
+			last = &token.CreatedAt
+		}
+	}
+	return last, nil
+}
+
+func (m *memoryTokens) VerifyEmail(ctx context.Context, tokenID uuid.UUID) error {
+	return entity.ErrInvalidVerificationToken
+}
+
+func (m *memoryTokens) ResetPassword(ctx context.Context, tokenID uuid.UUID, passwordHash string) error {
+	return entity.ErrInvalidResetToken
+}
+
+func TestResendVerificationEmailRefusals(t *testing.T) {
+	unverified := &entity.User{ID: uuid.New(), Email: "ann@example.com"}
+	verified := &entity.User{ID: uuid.New(), Email: "james@example.com", IsVerified: true}
+
+	tests := []struct {
+		name       string
+		user       *entity.User
+		lastSentAt time.Duration // how long ago the last verification email was sent
+		wantErr    error
+	}{
+		{name: "verified user", user: verified, lastSentAt: time.Hour, wantErr: entity.ErrAlreadyVerified},
+		{name: "asked again too soon", user: unverified, lastSentAt: 30 * time.Second, wantErr: entity.ErrVerificationThrottled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := &memoryTokens{tokens: []*entity.OneTimeToken{{
+				ID: uuid.New(), UserID: tt.user.ID, Purpose: entity.PurposeEmailVerification, CreatedAt: time.Now().Add(-tt.lastSentAt),
+			}}}
+			service := &UserServiceImpl{
+				userRepo:   &memoryUsers{users: map[uuid.UUID]*entity.User{unverified.ID: unverified, verified.ID: verified}},
+				tokenRepo:  tokens,
+				resendWait: time.Minute,
+			}
+
+			if err := service.ResendVerificationEmail(context.Background(), tt.user.ID); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if len(tokens.tokens) != 1 {
+				t.Errorf("%d tokens, want no new one issued", len(tokens.tokens))
+			}
+		})
+	}
+}
+
+func TestVerifyEmailRejectsOtherTokens(t *testing.T) {
+	service := &UserServiceImpl{tokenRepo: &memoryTokens{}}
+	for _, token := range []string{"", "not-a-token"} {
+		if err := service.VerifyEmail(context.Background(), token); !errors.Is(err, entity.ErrInvalidVerificationToken) {
+			t.Errorf("VerifyEmail(%q) = %v, want ErrInvalidVerificationToken", token, err)
+		}
+	}
+}
\ No newline at end of file
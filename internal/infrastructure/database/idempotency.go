@@ -0,0 +1,134 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyRecord : a request made with an Idempotency-Key header and, once it has been handled, the response it got.
+// Keys are scoped to the user that sent them (or, for requests without a valid access token, to a hash of their credentials)
+type IdempotencyRecord struct {
+	Scope       string      `gorm:"type:varchar(64);primaryKey"`
+	Key         string      `gorm:"type:varchar(255);primaryKey"`
+	Fingerprint string      `gorm:"type:char(64);not null"`
+	StatusCode  int         `gorm:"not null;default:0"` // 0 while the first request is still being handled
+	Header      http.Header `gorm:"type:jsonb;serializer:json"`
+	Body        []byte      `gorm:"type:bytea"`
+	CreatedAt   time.Time   `gorm:"not null"`
+	ExpiresAt   time.Time   `gorm:"not null;index"` // the end of the claim's lease while the first request is still being handled
+}
+
+func (IdempotencyRecord) TableName() string {
+	return "idempotency_keys"
+}
+
+// Completed reports whether the response to the request has been stored
+func (r *IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
+
+// IdempotencyStore keeps idempotency records in the database
+type IdempotencyStore struct {
+	db *Service
+}
+
+func NewIdempotencyStore(db *Service) *IdempotencyStore {
+	return &IdempotencyStore{db: db}
+}
+
+// Claim records a new request under its key, unless a live record for the key exists already.
+// A claim whose lease (its ExpiresAt until it is completed) has run out is taken over.
+// It returns the record for the key and whether it is the one just created
+func (s *IdempotencyStore) Claim(ctx context.Context, record *IdempotencyRecord) (*IdempotencyRecord, bool, error) {
+	var existing IdempotencyRecord
+	claimed := false
+	// as postgres stores it, so that Complete & Release can tell the claim from one that took it over
+	record.CreatedAt = record.CreatedAt.Truncate(time.Microsecond)
+	err := s.db.Transaction(ctx, func(tx *gorm.DB) error {
+		// an expired record, or a claim whose lease ran out, no longer holds its key
+		if err := tx.Where("scope = ? AND key = ? AND expires_at <= ?", record.Scope, record.Key, time.Now()).
+			Delete(&IdempotencyRecord{}).Error; err != nil {
+			return fmt.Errorf("failed to delete expired idempotency key: %w", err)
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+		if result.Error != nil {
+			return fmt.Errorf("failed to record idempotency key: %w", result.Error)
+		}
+		if result.RowsAffected == 1 {
+			claimed = true
+			return nil
+		}
+
+		if err := tx.Where("scope = ? AND key = ?", record.Scope, record.Key).First(&existing).Error; err != nil {
+			return fmt.Errorf("failed to get idempotency key: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if claimed {
+		return record, true, nil
+	}
+	return &existing, false, nil
+}
+
+// Complete stores the response to a claimed request, kept until the record's ExpiresAt.
+// Nothing is stored if another request took the claim over
+func (s *IdempotencyStore) Complete(ctx context.Context, record *IdempotencyRecord) error {
+	// updating from the struct, not a map, so that Header goes through its serializer
+	result := s.db.WithContext(ctx).Model(record).Where("created_at = ? AND status_code = 0", record.CreatedAt).
+		Select("status_code", "header", "body", "expires_at").Updates(record)
+	if result.Error != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("failed to store idempotent response: key %q was taken over", record.Key)
+	}
+	return nil
+}
+
+// Release gives up a claimed key without storing a response, so the request can be retried.
+// A claim taken over by another request is left alone
+func (s *IdempotencyStore) Release(ctx context.Context, record *IdempotencyRecord) error {
+	err := s.db.WithContext(ctx).Where("scope = ? AND key = ? AND created_at = ? AND status_code = 0", record.Scope, record.Key, record.CreatedAt).
+		Delete(&IdempotencyRecord{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired removes the records that expired by now and reports how many there were
+func (s *IdempotencyStore) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&IdempotencyRecord{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// Run deletes expired records once immediately and then every interval, until ctx is cancelled
+func (s *IdempotencyStore) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.DeleteExpired(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			log.Printf("idempotency keys: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
\ No newline at end of file
@@ -1,6 +1,8 @@
 package database
 
 import (
+	paymentEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/payment/entity"
+	pricingEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/pricing/entity"
 	roomEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/room/entity"
 	"gorm.io/gorm"
 )
@@ -10,18 +12,104 @@ import (
 	userEntity "github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/entity"
 )
 
+// reservationOverlapConstraint makes overlapping stays on the same room impossible at the database level.
+// Stays are treated as half-open [check_in, check_out) ranges, and only reservations in a blocking
+// status (see reservationEntity.BlockingStatuses) take part in the check.
+const reservationOverlapConstraint = `
+DO $$
+BEGIN
+	IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'reservations_no_overlap') THEN
+		ALTER TABLE reservations
+			ADD CONSTRAINT reservations_no_overlap
+			EXCLUDE USING gist (
+				room_id WITH =,
+				tstzrange(check_in_date, check_out_date, '[)') WITH &&
+			) WHERE (status IN ('PENDING', 'CONFIRMED', 'IN_PROGRESS', 'COMPLETED'));
+	END IF;
+END $$;`
+
+// reservationRoomTypeBackfill gives reservations made before room type bookings the type of their room
+const reservationRoomTypeBackfill = `
+UPDATE reservations SET room_type_id = rooms.room_type_id
+FROM rooms
+WHERE reservations.room_id = rooms.id AND reservations.room_type_id IS NULL;`
+
+// reservationCodeBackfill gives reservations made before confirmation codes a random one, in the alphabet of utils.GenerateCode.
+// The subquery refers to the row so it is evaluated, and random, for each reservation
+const reservationCodeBackfill = `
+UPDATE reservations SET code = (
+	SELECT string_agg(substr('0123456789ABCDEFGHJKMNPQRSTVWXYZ', floor(random() * 32)::int + 1, 1), '')
+	FROM generate_series(1, 8)
+	WHERE reservations.id IS NOT NULL
+)
+WHERE code = '';`
+
+// sessionBackfill gives refresh token families started before sessions were recorded a session, so they can be listed & revoked
+const sessionBackfill = `
+INSERT INTO sessions (id, user_id, last_seen_at, revoked_at, created_at)
+SELECT family_id, user_id, max(created_at), CASE WHEN bool_and(revoked_at IS NOT NULL) THEN max(revoked_at) END, min(created_at)
+FROM refresh_tokens
+GROUP BY family_id, user_id
+ON CONFLICT (id) DO NOTHING;`
+
 // RunMigrations performs auto-migration for all models
 func RunMigrations(db *gorm.DB) error {
 	// Enable uuid-ossp extension for UUID support
 	db.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`)
-	db.Exec(`CREATE EXTENSION IF NOT EXISTS "citext";`) // allow case insensitive
+	db.Exec(`CREATE EXTENSION IF NOT EXISTS "citext";`)     // allow case insensitive
+	db.Exec(`CREATE EXTENSION IF NOT EXISTS "btree_gist";`) // allow uuid equality in exclusion constraints
+
+	// phone numbers only have to be unique when given: accounts created by signing in with Google have none
+	// (the constraint is named after the gorm version that created the table)
+	if err := db.Exec(`ALTER TABLE IF EXISTS users DROP CONSTRAINT IF EXISTS users_phone_key, DROP CONSTRAINT IF EXISTS uni_users_phone`).Error; err != nil {
+		return err
+	}
 
 	// Auto-migrate all models
-	return db.AutoMigrate(
+	if err := db.AutoMigrate(
 		&userEntity.User{},
+		&userEntity.Session{},
+		&userEntity.RefreshToken{},
+		&userEntity.UserIdentity{},
+		&userEntity.OneTimeToken{},
 		&roomEntity.BedType{},
 		&roomEntity.RoomType{},
 		&roomEntity.Room{},
+		&roomEntity.MaintenanceWindow{},
+		&pricingEntity.RatePlan{},
+		&pricingEntity.RatePlanRate{},
+		&pricingEntity.CancellationPolicy{},
+		&pricingEntity.CancellationRule{},
+		&paymentEntity.Payment{},
+		&paymentEntity.WebhookEvent{},
+		&paymentEntity.Adjustment{},
+		&reservationEntity.Booking{},
 		&reservationEntity.Reservation{},
-	)
+		&reservationEntity.LineItem{},
+		&reservationEntity.Change{},
+		&reservationEntity.WaitlistEntry{},
+		&IdempotencyRecord{},
+	); err != nil {
+		return err
+	}
+
+	// reservations booked by room type have no room until one is allocated
+	if err := db.Exec(`ALTER TABLE reservations ALTER COLUMN room_id DROP NOT NULL`).Error; err != nil {
+		return err
+	}
+	if err := db.Exec(reservationRoomTypeBackfill).Error; err != nil {
+		return err
+	}
+	if err := db.Exec(reservationCodeBackfill).Error; err != nil {
+		return err
+	}
+	if err := db.Exec(sessionBackfill).Error; err != nil {
+		return err
+	}
+
+	// the exact-match unique index is superseded by the overlap constraint
+	if err := db.Exec(`DROP INDEX IF EXISTS idx_room_dates`).Error; err != nil {
+		return err
+	}
+	return db.Exec(reservationOverlapConstraint).Error
 }
\ No newline at end of file
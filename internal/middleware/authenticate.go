@@ -3,27 +3,36 @@ package middleware
 import (
 	"context"
 	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils"
+	"github.com/google/uuid"
 	"net/http"
 	"strings"
+	"time"
 )
 
+// bearerToken -> the access token of the request, from the Authorization header or else the accessToken cookie
+func bearerToken(r *http.Request) (string, bool) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		if cookie, err := r.Cookie("accessToken"); err == nil {
+			authHeader = "Bearer " + cookie.Value
+		}
+	}
+
+	if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
+		return "", false
+	}
+	return strings.TrimPrefix(authHeader, "Bearer "), true
+}
+
 // Authenticate -> extracts userID from access token & adds it to context
 func Authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			if cookie, err := r.Cookie("accessToken"); err == nil {
-				authHeader = "Bearer " + cookie.Value
-			}
-		}
-
-		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
+		accessToken, ok := bearerToken(r)
+		if !ok {
 			utils.RespondError(w, http.StatusUnauthorized, "Missing Access Token")
 			return
 		}
 
-		accessToken := strings.TrimPrefix(authHeader, "Bearer ")
-
 		//	 Validate token
 		payload, err := utils.ValidateToken(accessToken, "ACCESS")
 		if err != nil {
@@ -31,12 +40,40 @@ func Authenticate(next http.Handler) http.Handler {
 			return
 		}
 
-		userID := payload["userID"].(string)
-		role := payload["role"].(string)
+		// other kinds of tokens (e.g. email verification) carry no role
+		userID, _ := payload["userID"].(string)
+		role, ok := payload["role"].(string)
+		if !ok || userID == "" {
+			utils.RespondError(w, http.StatusUnauthorized, "Invalid Token")
+			return
+		}
+
+		// Every access token belongs to a session: tokens without one (issued before sessions) could never be revoked
+		sessionID, _ := payload["sid"].(string)
+		id, err := uuid.Parse(sessionID)
+		if err != nil {
+			utils.RespondError(w, http.StatusUnauthorized, "Invalid Token")
+			return
+		}
+
+		// Refuse tokens of revoked sessions
+		if sessions != nil {
+			revoked, err := sessions.IsRevoked(r.Context(), id)
+			if err != nil {
+				utils.RespondError(w, http.StatusInternalServerError, "Failed to verify session")
+				return
+			}
+			if revoked {
+				utils.RespondError(w, http.StatusUnauthorized, "Session has been revoked")
+				return
+			}
+			sessions.Seen(id, time.Now())
+		}
 
 		// Set user values to content
 		ctx := context.WithValue(r.Context(), "userID", userID)
 		ctx = context.WithValue(ctx, "role", role)
+		ctx = context.WithValue(ctx, "sessionID", sessionID)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
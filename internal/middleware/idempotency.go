@@ -0,0 +1,189 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/infrastructure/database"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	// IdempotencyKeyTTL : how long the response to a request is kept to be replayed
+	IdempotencyKeyTTL = 24 * time.Hour
+	// IdempotencyClaimLease : how long a request being handled holds its key. A retry after that takes the key over,
+	// so that a request whose process died before answering does not block its retries for IdempotencyKeyTTL
+	IdempotencyClaimLease = 5 * time.Minute
+
+	maxIdempotencyKeyLength = 255
+	// maxIdempotentBody : the most of a request body that is read to fingerprint it
+	maxIdempotentBody = 1 << 20
+)
+
+// IdempotencyStore keeps the requests made with an Idempotency-Key and their responses
+type IdempotencyStore interface {
+	// Claim records a new request under its key unless a record that has not expired holds it, and returns the record holding the key
+	Claim(ctx context.Context, record *database.IdempotencyRecord) (*database.IdempotencyRecord, bool, error)
+	Complete(ctx context.Context, record *database.IdempotencyRecord) error
+	Release(ctx context.Context, record *database.IdempotencyRecord) error
+}
+
+// idempotentResponse captures the response of a request while writing it through
+type idempotentResponse struct {
+	http.ResponseWriter
+	StatusCode int
+	header     http.Header
+	body       bytes.Buffer
+}
+
+func (rw *idempotentResponse) WriteHeader(code int) {
+	if rw.header == nil {
+		rw.StatusCode = code
+		rw.header = rw.ResponseWriter.Header().Clone()
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *idempotentResponse) Write(b []byte) (int, error) {
+	if rw.header == nil {
+		rw.WriteHeader(http.StatusOK)
+	}
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
+
+// Idempotency makes POST requests that carry an Idempotency-Key header safe to retry.
+// The first request with a key is handled and its response stored; a retry with the same key and body gets that response again
+// (marked with an Idempotent-Replayed header, and without its cookies) without being handled. Reusing a key for a different request
+// is refused with 422, and a retry while the first request is still being handled with 409, for at most IdempotencyClaimLease.
+// Server errors and panics are not stored, so the request can be retried
+func Idempotency(store IdempotencyStore) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get("Idempotency-Key")
+			if r.Method != http.MethodPost || key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				utils.RespondError(w, http.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBody))
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					utils.RespondError(w, http.StatusRequestEntityTooLarge, "Request body too large")
+					return
+				}
+				utils.RespondError(w, http.StatusBadRequest, "Invalid request payload")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			now := time.Now()
+			record := &database.IdempotencyRecord{
+				Scope:       idempotencyScope(r),
+				Key:         key,
+				Fingerprint: requestFingerprint(r, body),
+				CreatedAt:   now,
+				ExpiresAt:   now.Add(IdempotencyClaimLease),
+			}
+			stored, claimed, err := store.Claim(r.Context(), record)
+			if err != nil {
+				utils.RespondError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			if !claimed {
+				switch {
+				case stored.Fingerprint != record.Fingerprint:
+					utils.RespondError(w, http.StatusUnprocessableEntity, "Idempotency-Key has already been used for a different request")
+				case !stored.Completed():
+					utils.RespondError(w, http.StatusConflict, "A request with this Idempotency-Key is still being processed")
+				default:
+					replay(w, stored)
+				}
+				return
+			}
+
+			// the response is already sent: store it, or give up the key, even if the client has gone away
+			ctx := context.WithoutCancel(r.Context())
+			release := func() {
+				if err := store.Release(ctx, record); err != nil {
+					log.Printf("idempotency: %v", err)
+				}
+			}
+			defer func() {
+				if p := recover(); p != nil {
+					release()
+					panic(p)
+				}
+			}()
+
+			ww := &idempotentResponse{ResponseWriter: w, StatusCode: http.StatusOK}
+			next.ServeHTTP(ww, r)
+
+			if ww.StatusCode >= http.StatusInternalServerError {
+				release()
+				return
+			}
+
+			record.StatusCode = ww.StatusCode
+			record.Header = ww.header
+			// cookies set by the response (tokens, for one) are for the client that made it: never stored nor replayed
+			record.Header.Del("Set-Cookie")
+			record.Body = ww.body.Bytes()
+			record.ExpiresAt = time.Now().Add(IdempotencyKeyTTL)
+			if err := store.Complete(ctx, record); err != nil {
+				log.Printf("idempotency: %v", err)
+			}
+		})
+	}
+}
+
+// replay writes a stored response again
+func replay(w http.ResponseWriter, record *database.IdempotencyRecord) {
+	for name, values := range record.Header {
+		if name == "Set-Cookie" {
+			continue
+		}
+		w.Header()[name] = values
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(record.StatusCode)
+	w.Write(record.Body)
+}
+
+// idempotencyScope -> the user a key belongs to: keys of different users never clash.
+// Requests without a valid access token are scoped to a hash of the credentials they carry, so anonymous clients
+// (signing in or refreshing with their cookies) never get each other's responses
+func idempotencyScope(r *http.Request) string {
+	if accessToken, ok := bearerToken(r); ok {
+		if payload, err := utils.ValidateToken(accessToken, "ACCESS"); err == nil {
+			if userID, _ := payload["userID"].(string); userID != "" {
+				return userID
+			}
+		}
+	}
+	hash := sha256.New()
+	hash.Write([]byte(r.Header.Get("Authorization") + "\n"))
+	for _, cookie := range r.Header.Values("Cookie") {
+		hash.Write([]byte(cookie + "\n"))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// requestFingerprint identifies what a request asks for: its method, path and body
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
\ No newline at end of file
@@ -0,0 +1,222 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gatimugabriel/hotel-reservation-system/internal/infrastructure/database"
+)
+
+// memoryIdempotencyStore : in-memory IdempotencyStore
+type memoryIdempotencyStore struct {
+	records map[string]*database.IdempotencyRecord
+}
+
+func (m *memoryIdempotencyStore) Claim(ctx context.Context, record *database.IdempotencyRecord) (*database.IdempotencyRecord, bool, error) {
+	if stored, ok := m.records[record.Scope+"/"+record.Key]; ok && stored.ExpiresAt.After(time.Now()) {
+		copied := *stored
+		return &copied, false, nil
+	}
+	copied := *record
+	m.records[record.Scope+"/"+record.Key] = &copied
+	return record, true, nil
+}
+
+func (m *memoryIdempotencyStore) Complete(ctx context.Context, record *database.IdempotencyRecord) error {
+	if stored, ok := m.records[record.Scope+"/"+record.Key]; !ok || !stored.CreatedAt.Equal(record.CreatedAt) || stored.Completed() {
+		return fmt.Errorf("key %q was taken over", record.Key)
+	}
+	copied := *record
+	m.records[record.Scope+"/"+record.Key] = &copied
+	return nil
+}
+
+func (m *memoryIdempotencyStore) Release(ctx context.Context, record *database.IdempotencyRecord) error {
+	if stored, ok := m.records[record.Scope+"/"+record.Key]; ok && stored.CreatedAt.Equal(record.CreatedAt) && !stored.Completed() {
+		delete(m.records, record.Scope+"/"+record.Key)
+	}
+	return nil
+}
+
+func TestIdempotency(t *testing.T) {
+	store := &memoryIdempotencyStore{records: make(map[string]*database.IdempotencyRecord)}
+	calls := 0
+	status := http.StatusCreated
+	handler := Idempotency(store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"call":%d}`, calls)
+	}))
+
+	send := func(key, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/reservation/create-reservation", strings.NewReader(body))
+		if key != "" {
+			r.Header.Set("Idempotency-Key", key)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	first := send("key-1", `{"room_number":101}`)
+	if first.Code != http.StatusCreated || first.Body.String() != `{"call":1}` {
+		t.Fatalf("first request = %d %s, want 201 from the handler", first.Code, first.Body)
+	}
+
+	retry := send("key-1", `{"room_number":101}`)
+	if calls != 1 {
+		t.Fatalf("handler called %d times, want the retry replayed", calls)
+	}
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() ||
+		retry.Header().Get("Content-Type") != "application/json" || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("retry = %d %v %s, want the first response replayed", retry.Code, retry.Header(), retry.Body)
+	}
+
+	if reused := send("key-1", `{"room_number":102}`); reused.Code != http.StatusUnprocessableEntity {
+		t.Errorf("key reused for another body = %d, want 422", reused.Code)
+	}
+
+	send("", `{"room_number":101}`)
+	send("", `{"room_number":101}`)
+	if calls != 3 {
+		t.Errorf("handler called %d times, want requests without a key always handled", calls)
+	}
+
+	status = http.StatusInternalServerError
+	send("key-2", `{}`)
+	status = http.StatusCreated
+	if again := send("key-2", `{}`); again.Code != http.StatusCreated || calls != 5 {
+		t.Errorf("retry after a server error = %d after %d calls, want it handled again", again.Code, calls)
+	}
+}
+
+func TestIdempotencyWhileInProgress(t *testing.T) {
+	store := &memoryIdempotencyStore{records: make(map[string]*database.IdempotencyRecord)}
+	handler := Idempotency(store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// a retry arrives before the first request is answered
+		retry := httptest.NewRequest(http.MethodPost, "/api/v1/payment/", strings.NewReader(`{}`))
+		retry.Header.Set("Idempotency-Key", "key-1")
+		rw := httptest.NewRecorder()
+		Idempotency(store)(http.NotFoundHandler()).ServeHTTP(rw, retry)
+		if rw.Code != http.StatusConflict {
+			t.Errorf("retry in progress = %d, want 409", rw.Code)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/payment/", strings.NewReader(`{}`))
+	r.Header.Set("Idempotency-Key", "key-1")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+}
+
+func TestIdempotencyKeepsAnonymousClientsApart(t *testing.T) {
+	store := &memoryIdempotencyStore{records: make(map[string]*database.IdempotencyRecord)}
+	calls := 0
+	handler := Idempotency(store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		cookie, _ := r.Cookie("refreshToken")
+		http.SetCookie(w, &http.Cookie{Name: "accessToken", Value: "token-for-" + cookie.Value})
+		fmt.Fprintf(w, `{"call":%d}`, calls)
+	}))
+
+	send := func(refreshToken string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", nil)
+		r.Header.Set("Idempotency-Key", "key-1")
+		r.AddCookie(&http.Cookie{Name: "refreshToken", Value: refreshToken})
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	ann := send("ann")
+	james := send("james")
+	if calls != 2 || james.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("handler called %d times, want the second client's request handled, not given the first's response", calls)
+	}
+	if cookie := james.Header().Get("Set-Cookie"); !strings.Contains(cookie, "token-for-james") {
+		t.Errorf("second client got cookie %q, want its own", cookie)
+	}
+
+	retry := send("ann")
+	if calls != 2 || retry.Body.String() != ann.Body.String() {
+		t.Fatalf("retry = %s after %d calls, want the first response replayed", retry.Body, calls)
+	}
+	if cookie := retry.Header().Get("Set-Cookie"); cookie != "" {
+		t.Errorf("replay set cookie %q, want none", cookie)
+	}
+}
+
+func TestIdempotencyReleasesKeyWhenHandlerPanics(t *testing.T) {
+	store := &memoryIdempotencyStore{records: make(map[string]*database.IdempotencyRecord)}
+	calls := 0
+	handler := Idempotency(store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			panic("handler failed")
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	send := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/reservation/create-reservation", strings.NewReader(`{}`))
+		r.Header.Set("Idempotency-Key", "key-1")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	func() {
+		defer func() {
+			if p := recover(); p != "handler failed" {
+				t.Errorf("recovered %v, want the handler's panic passed on", p)
+			}
+		}()
+		send()
+	}()
+
+	if retry := send(); retry.Code != http.StatusCreated || calls != 2 {
+		t.Errorf("retry after a panic = %d after %d calls, want it handled again", retry.Code, calls)
+	}
+}
+
+func TestIdempotencyTakesOverLapsedClaim(t *testing.T) {
+	store := &memoryIdempotencyStore{records: make(map[string]*database.IdempotencyRecord)}
+	handler := Idempotency(store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	newRequest := func() *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/reservation/create-reservation", strings.NewReader(`{}`))
+		r.Header.Set("Idempotency-Key", "key-1")
+		return r
+	}
+
+	// the process handling the first request died before answering it
+	r := newRequest()
+	claimedAt := time.Now().Add(-IdempotencyClaimLease - time.Minute)
+	scope := idempotencyScope(r)
+	store.records[scope+"/key-1"] = &database.IdempotencyRecord{
+		Scope: scope, Key: "key-1", Fingerprint: requestFingerprint(r, []byte(`{}`)),
+		CreatedAt: claimedAt, ExpiresAt: claimedAt.Add(IdempotencyClaimLease),
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("retry after the claim lapsed = %d, want it handled", w.Code)
+	}
+	if stored := store.records[scope+"/key-1"]; !stored.Completed() || stored.ExpiresAt.Before(time.Now().Add(IdempotencyKeyTTL-time.Minute)) {
+		t.Errorf("stored record = %d until %v, want the response kept for IdempotencyKeyTTL", stored.StatusCode, stored.ExpiresAt)
+	}
+
+	again := httptest.NewRecorder()
+	handler.ServeHTTP(again, newRequest())
+	if again.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("retry of the completed request = %d, want the response replayed", again.Code)
+	}
+}
\ No newline at end of file
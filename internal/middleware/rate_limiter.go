@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"net"
 	"net/http"
 	"sync"
 	"time"
@@ -63,7 +64,11 @@ func (i *IPRateLimiter) GetLimiter(ip string) *rate.Limiter {
 func Limit(limiter *IPRateLimiter) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ip := r.RemoteAddr
+			// key on the host alone: the port changes with every connection a client opens
+			ip, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				ip = r.RemoteAddr
+			}
 			if !limiter.GetLimiter(ip).Allow() {
 				http.Error(w, "Too many requests", http.StatusTooManyRequests)
 				return
@@ -10,10 +10,8 @@ import (
 func RoleCheck(allowedRoles []constants.Role, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Get user role from context (set by auth middleware)
-		userRole := r.Context().Value("role").(string)
-
-		roleInterface := r.Context().Value("role")
-		if roleInterface == nil {
+		userRole, ok := r.Context().Value("role").(string)
+		if !ok {
 			http.Error(w, "Unauthorized - No role found", http.StatusUnauthorized)
 			return
 		}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils"
+	"github.com/google/uuid"
+	"sync"
+	"time"
+)
+
+// RevocationCacheTTL : how long the list of revoked sessions is used before it is loaded again,
+// so how long the access tokens of a revoked session may keep working on other server instances
+const RevocationCacheTTL = 30 * time.Second
+
+// SessionStore : the sessions as seen by Authenticate
+type SessionStore interface {
+	RevokedSince(ctx context.Context, since time.Time) ([]uuid.UUID, error)
+	Touch(ctx context.Context, lastSeen map[uuid.UUID]time.Time) error
+}
+
+// SessionCache keeps in memory the sessions revoked while their access tokens may still be valid,
+// and when sessions were last seen. Both are synced with the store at most once every ttl
+type SessionCache struct {
+	store SessionStore
+	ttl   time.Duration
+
+	mu       sync.Mutex
+	revoked  map[uuid.UUID]struct{}
+	loadedAt time.Time
+	lastSeen map[uuid.UUID]time.Time
+}
+
+func NewSessionCache(store SessionStore, ttl time.Duration) *SessionCache {
+	return &SessionCache{
+		store:    store,
+		ttl:      ttl,
+		lastSeen: make(map[uuid.UUID]time.Time),
+	}
+}
+
+// sessions : the cache Authenticate checks tokens against. Tokens are not checked until one is set with CheckSessions
+var sessions *SessionCache
+
+// CheckSessions makes Authenticate refuse the access tokens of revoked sessions, and record when sessions are seen
+func CheckSessions(cache *SessionCache) {
+	sessions = cache
+}
+
+// IsRevoked reports whether the session has been revoked, loading the revoked sessions again once the list is older than ttl
+func (c *SessionCache) IsRevoked(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if c.revoked == nil || now.Sub(c.loadedAt) >= c.ttl {
+		if err := c.sync(ctx, now); err != nil {
+			return false, err
+		}
+	}
+	_, revoked := c.revoked[sessionID]
+	return revoked, nil
+}
+
+// Seen records that the session was used at the given time. It is saved with the next sync
+func (c *SessionCache) Seen(sessionID uuid.UUID, at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastSeen[sessionID] = at
+}
+
+// sync saves when sessions were last seen and loads the sessions revoked within an access token's lifetime. c.mu must be held
+func (c *SessionCache) sync(ctx context.Context, now time.Time) error {
+	if len(c.lastSeen) > 0 {
+		if err := c.store.Touch(ctx, c.lastSeen); err != nil {
+			return fmt.Errorf("failed to record session activity: %w", err)
+		}
+		c.lastSeen = make(map[uuid.UUID]time.Time)
+	}
+
+	ids, err := c.store.RevokedSince(ctx, now.Add(-utils.AccessTokenTTL))
+	if err != nil {
+		return err
+	}
+	c.revoked = make(map[uuid.UUID]struct{}, len(ids))
+	for _, id := range ids {
+		c.revoked[id] = struct{}{}
+	}
+	c.loadedAt = now
+	return nil
+}
\ No newline at end of file
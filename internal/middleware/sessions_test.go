@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// memorySessions : in-memory SessionStore
+type memorySessions struct {
+	revoked  []uuid.UUID
+	lastSeen map[uuid.UUID]time.Time
+	loads    int
+}
+
+func (m *memorySessions) RevokedSince(ctx context.Context, since time.Time) ([]uuid.UUID, error) {
+	m.loads++
+	return append([]uuid.UUID(nil), m.revoked...), nil
+}
+
+func (m *memorySessions) Touch(ctx context.Context, lastSeen map[uuid.UUID]time.Time) error {
+	for id, at := range lastSeen {
+		m.lastSeen[id] = at
+	}
+	return nil
+}
+
+func TestSessionCache(t *testing.T) {
+	store := &memorySessions{lastSeen: make(map[uuid.UUID]time.Time)}
+	cache := NewSessionCache(store, time.Hour)
+	session := uuid.New()
+
+	if revoked, err := cache.IsRevoked(context.Background(), session); err != nil || revoked {
+		t.Fatalf("IsRevoked = %v, %v, want a live session", revoked, err)
+	}
+	seenAt := time.Now()
+	cache.Seen(session, seenAt)
+
+	// revoked elsewhere: the cached list is used until it is older than the ttl
+	store.revoked = append(store.revoked, session)
+	if revoked, _ := cache.IsRevoked(context.Background(), session); revoked || store.loads != 1 {
+		t.Fatalf("IsRevoked = %v after %d loads, want the cached answer", revoked, store.loads)
+	}
+
+	cache.loadedAt = cache.loadedAt.Add(-time.Hour)
+	if revoked, _ := cache.IsRevoked(context.Background(), session); !revoked {
+		t.Fatal("IsRevoked = false once the list expired, want the session revoked")
+	}
+	if !store.lastSeen[session].Equal(seenAt) {
+		t.Errorf("last seen = %v, want %v saved with the reload", store.lastSeen[session], seenAt)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"github.com/gatimugabriel/hotel-reservation-system/internal/domain/user/entity"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/utils"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+// VerificationChecker : tells whether users have verified their email
+type VerificationChecker interface {
+	IsVerified(ctx context.Context, id uuid.UUID) (bool, error)
+}
+
+// RequireVerifiedEmail -> refuses users who have not verified their email. Must run after Authenticate
+func RequireVerifiedEmail(checker VerificationChecker) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userIDValue, _ := r.Context().Value("userID").(string)
+			userID, err := uuid.Parse(userIDValue)
+			if err != nil {
+				utils.RespondError(w, http.StatusUnauthorized, "Unauthorized")
+				return
+			}
+
+			verified, err := checker.IsVerified(r.Context(), userID)
+			if err != nil {
+				if errors.Is(err, entity.ErrUserNotFound) {
+					utils.RespondError(w, http.StatusUnauthorized, "Unauthorized")
+					return
+				}
+				utils.RespondError(w, http.StatusInternalServerError, "Failed to check email verification")
+				return
+			}
+			if !verified {
+				utils.RespondError(w, http.StatusForbidden, "Please verify your email before making reservations")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
\ No newline at end of file
@@ -1,15 +1,22 @@
 package httpServer
 
 import (
+	"context"
+	"errors"
 	"github.com/gatimugabriel/hotel-reservation-system/internal/config"
 	middleware2 "github.com/gatimugabriel/hotel-reservation-system/internal/middleware"
 	_ "github.com/joho/godotenv/autoload"
 	"github.com/rs/cors"
 	"log"
 	"net/http"
+	"time"
 )
 
-func StartServer(configurations *config.Config, router *http.ServeMux) {
+// shutdownTimeout : how long in-flight requests get to complete once the server is asked to stop
+const shutdownTimeout = 10 * time.Second
+
+// StartServer serves the router until ctx is cancelled, then shuts down gracefully
+func StartServer(ctx context.Context, configurations *config.Config, router http.Handler) {
 	address := ":" + configurations.Server.Port
 
 	//____ apply global middlewares ____//
@@ -23,14 +30,30 @@ func StartServer(configurations *config.Config, router *http.ServeMux) {
 	//____ CORS Setup ____//
 	corsHandler := cors.New(cors.Options{
 		AllowCredentials: true,
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "Idempotency-Key"},
+		ExposedHeaders:   []string{"Idempotent-Replayed"},
 		AllowedMethods:   []string{"POST", "GET", "PUT", "PATCH", "DELETE", "OPTIONS", "HEAD", "CONNECT", "TRACE"},
 		AllowedOrigins:   configurations.Server.AllowedOrigins,
 	}).Handler(wrappedRouter)
 
 	//___ start server ____//
+	server := &http.Server{Addr: address, Handler: corsHandler}
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-ctx.Done()
+		log.Println("Shutting down server")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Error shutting down server %v", err)
+		}
+	}()
+
 	log.Println("Server listening on port", address)
-	if err := http.ListenAndServe(address, corsHandler); err != nil {
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatalf("Error starting server %v", err)
 	}
+	// ListenAndServe returns as soon as Shutdown starts; wait for in-flight requests
+	<-shutdownDone
 }
\ No newline at end of file
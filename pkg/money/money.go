@@ -0,0 +1,295 @@
+package money
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the hotel's operating currency.
+// Amounts read back from a numeric database column are assumed to be in it
+const DefaultCurrency = "USD"
+
+// scale : number of minor units in one major unit. Every supported currency uses two decimal places
+const scale = 100
+
+// Money : an exact amount of a currency, held as integer minor units (cents).
+//
+// Arithmetic never goes through floating point. Operations that can produce fractions of a cent
+// (Percent, MulRate) round half away from zero, the usual commercial rounding.
+// The zero value is zero in no particular currency and adopts the currency of whatever it is combined with
+type Money struct {
+	minor    int64
+	currency string
+}
+
+// New creates an amount from minor units, e.g. New(1999, "USD") is $19.99
+func New(minor int64, currency string) Money {
+	return Money{minor: minor, currency: strings.ToUpper(currency)}
+}
+
+// Zero returns a zero amount in the given currency
+func Zero(currency string) Money {
+	return New(0, currency)
+}
+
+// Parse reads a decimal string such as "199.99" or "-5". Digits beyond the second decimal place are rounded
+func Parse(amount string, currency string) (Money, error) {
+	amount = strings.TrimSpace(amount)
+	rat, ok := new(big.Rat).SetString(amount)
+	if !ok || strings.ContainsAny(amount, "/eE") {
+		return Money{}, fmt.Errorf("invalid money amount %q", amount)
+	}
+
+	minor := rat.Mul(rat, big.NewRat(scale, 1))
+	rounded, err := roundRat(minor)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid money amount %q: %w", amount, err)
+	}
+	return New(rounded, currency), nil
+}
+
+// MustParse is like Parse but panics on malformed input. Intended for constants & tests
+func MustParse(amount string, currency string) Money {
+	m, err := Parse(amount, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Minor returns the amount in minor units
+func (m Money) Minor() int64 {
+	return m.minor
+}
+
+// Currency returns the ISO 4217 currency code
+func (m Money) Currency() string {
+	return m.currency
+}
+
+// WithCurrency returns the same amount labelled with another currency. No conversion is done
+func (m Money) WithCurrency(currency string) Money {
+	return New(m.minor, currency)
+}
+
+func (m Money) IsZero() bool {
+	return m.minor == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.minor < 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.minor > 0
+}
+
+// Add returns m + o. It panics if both amounts carry different currencies
+func (m Money) Add(o Money) Money {
+	currency := m.commonCurrency(o)
+	return Money{minor: m.minor + o.minor, currency: currency}
+}
+
+// Sub returns m - o. It panics if both amounts carry different currencies
+func (m Money) Sub(o Money) Money {
+	currency := m.commonCurrency(o)
+	return Money{minor: m.minor - o.minor, currency: currency}
+}
+
+// Mul multiplies the amount by a whole quantity, e.g. a nightly rate by a number of nights
+func (m Money) Mul(quantity int64) Money {
+	return Money{minor: m.minor * quantity, currency: m.currency}
+}
+
+// Neg returns -m
+func (m Money) Neg() Money {
+	return Money{minor: -m.minor, currency: m.currency}
+}
+
+// Percent returns the given share of the amount in basis points (1% = 100), rounded half away from zero.
+// Percent(10000) is the amount itself, Percent(12000) adds 20%
+func (m Money) Percent(basisPoints int64) Money {
+	return Money{minor: divRound(m.minor*basisPoints, 10000), currency: m.currency}
+}
+
+// MulRate scales the amount by a percentage given as a float, as stored for rate adjustments (e.g. 12.5 = 12.5%).
+// The percentage is first rounded to whole basis points so results stay reproducible
+func (m Money) MulRate(percent float64) Money {
+	return m.Percent(int64(math.Round(percent * 100)))
+}
+
+// Cmp compares two amounts of the same currency, returning -1, 0 or +1
+func (m Money) Cmp(o Money) int {
+	m.commonCurrency(o)
+	switch {
+	case m.minor < o.minor:
+		return -1
+	case m.minor > o.minor:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Min returns the smaller of two amounts
+func Min(a, b Money) Money {
+	if a.Cmp(b) <= 0 {
+		return a
+	}
+	return b
+}
+
+// Decimal formats the amount without currency, e.g. "1234.50"
+func (m Money) Decimal() string {
+	sign := ""
+	minor := m.minor
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, minor/scale, minor%scale)
+}
+
+// String formats the amount with its currency, e.g. "USD 1234.50"
+func (m Money) String() string {
+	if m.currency == "" {
+		return m.Decimal()
+	}
+	return m.currency + " " + m.Decimal()
+}
+
+// commonCurrency returns the currency shared by both amounts, letting an unlabelled zero value adopt the other's
+func (m Money) commonCurrency(o Money) string {
+	switch {
+	case m.currency == "":
+		return o.currency
+	case o.currency == "" || m.currency == o.currency:
+		return m.currency
+	default:
+		panic(fmt.Sprintf("money: currency mismatch %s vs %s", m.currency, o.currency))
+	}
+}
+
+// divRound divides rounding half away from zero
+func divRound(numerator, denominator int64) int64 {
+	quotient := numerator / denominator
+	remainder := numerator % denominator
+	if 2*abs(remainder) >= abs(denominator) {
+		if (numerator < 0) != (denominator < 0) {
+			quotient--
+		} else {
+			quotient++
+		}
+	}
+	return quotient
+}
+
+func roundRat(r *big.Rat) (int64, error) {
+	num, den := r.Num(), r.Denom()
+	quotient, remainder := new(big.Int).QuoRem(num, den, new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(den) >= 0 {
+		if num.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	if !quotient.IsInt64() {
+		return 0, fmt.Errorf("amount out of range")
+	}
+	return quotient.Int64(), nil
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// ___ database ___//
+
+// Value stores the amount in a numeric (decimal(10,2)) column
+func (m Money) Value() (driver.Value, error) {
+	return m.Decimal(), nil
+}
+
+// Scan reads a numeric column. The currency is DefaultCurrency; entities that keep a currency column relabel it after loading
+func (m *Money) Scan(value interface{}) error {
+	var parsed Money
+	var err error
+
+	switch v := value.(type) {
+	case nil:
+		parsed = Zero(DefaultCurrency)
+	case string:
+		parsed, err = Parse(v, DefaultCurrency)
+	case []byte:
+		parsed, err = Parse(string(v), DefaultCurrency)
+	case int64:
+		parsed = New(v*scale, DefaultCurrency)
+	case float64:
+		parsed, err = Parse(strconv.FormatFloat(v, 'f', -1, 64), DefaultCurrency)
+	default:
+		return fmt.Errorf("money: cannot scan %T", value)
+	}
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
+
+// ___ JSON ___//
+
+type jsonMoney struct {
+	Amount   json.RawMessage `json:"amount"`
+	Currency string          `json:"currency"`
+}
+
+// MarshalJSON encodes the amount as {"amount": "199.99", "currency": "USD"}.
+// The amount is a string so clients never round-trip it through a float
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{m.Decimal(), m.currency})
+}
+
+// UnmarshalJSON accepts the object form written by MarshalJSON, with the amount as a string or number,
+// or a bare amount such as 199.99 or "199.99" in DefaultCurrency
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	currency := DefaultCurrency
+	amount := data
+	if len(data) > 0 && data[0] == '{' {
+		var obj jsonMoney
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return err
+		}
+		if obj.Currency != "" {
+			currency = obj.Currency
+		}
+		amount = obj.Amount
+	}
+
+	literal := strings.Trim(string(amount), `"`)
+	parsed, err := Parse(literal, currency)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
\ No newline at end of file
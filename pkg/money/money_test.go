@@ -0,0 +1,199 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"199.99", 19999, false},
+		{"0", 0, false},
+		{"5", 500, false},
+		{"-5.5", -550, false},
+		{"0.005", 1, false},   // half rounds away from zero
+		{"0.0049", 0, false},  // below half rounds down
+		{"-0.005", -1, false}, // negative half rounds away from zero
+		{"12.345", 1235, false},
+		{" 7.10 ", 710, false},
+		{"", 0, true},
+		{"abc", 0, true},
+		{"1/2", 0, true},
+		{"1e3", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Parse(tt.input, "usd")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse(%q) expected an error, got %v", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) unexpected error: %v", tt.input, err)
+			}
+			if got.Minor() != tt.want || got.Currency() != "USD" {
+				t.Errorf("Parse(%q) = %d %s, want %d USD", tt.input, got.Minor(), got.Currency(), tt.want)
+			}
+		})
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	a := MustParse("10.10", "USD")
+	b := MustParse("0.20", "USD")
+
+	if got := a.Add(b); got.Minor() != 1030 {
+		t.Errorf("Add = %s, want 10.30", got)
+	}
+	if got := a.Sub(b); got.Minor() != 990 {
+		t.Errorf("Sub = %s, want 9.90", got)
+	}
+	if got := b.Sub(a); !got.IsNegative() || got.Minor() != -990 {
+		t.Errorf("Sub = %s, want -9.90", got)
+	}
+	if got := a.Mul(3); got.Minor() != 3030 {
+		t.Errorf("Mul = %s, want 30.30", got)
+	}
+	if got := a.Neg(); got.Minor() != -1010 {
+		t.Errorf("Neg = %s, want -10.10", got)
+	}
+
+	// summing cents never drifts the way 0.1 + 0.2 does with floats
+	total := Money{}
+	for i := 0; i < 10; i++ {
+		total = total.Add(MustParse("0.10", "USD"))
+	}
+	if total.Minor() != 100 || total.Currency() != "USD" {
+		t.Errorf("sum of ten 0.10 = %s, want USD 1.00", total)
+	}
+}
+
+func TestPercentRounding(t *testing.T) {
+	tests := []struct {
+		name        string
+		amount      string
+		basisPoints int64
+		want        int64
+	}{
+		{"identity", "199.99", 10000, 19999},
+		{"plus twenty percent", "100.00", 12000, 12000},
+		{"half a cent rounds up", "0.05", 5000, 3},         // 2.5 cents
+		{"below half a cent rounds down", "0.01", 4000, 0}, // 0.4 cents
+		{"third", "10.00", 3333, 333},
+		{"negative half rounds away from zero", "-0.05", 5000, -3},
+		{"zero percent", "50.00", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MustParse(tt.amount, "USD").Percent(tt.basisPoints)
+			if got.Minor() != tt.want {
+				t.Errorf("%s.Percent(%d) = %d, want %d", tt.amount, tt.basisPoints, got.Minor(), tt.want)
+			}
+		})
+	}
+
+	if got := MustParse("80.00", "USD").MulRate(112.5); got.Minor() != 9000 {
+		t.Errorf("MulRate(112.5) = %s, want 90.00", got)
+	}
+}
+
+func TestCurrencyMismatchPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected Add of different currencies to panic")
+		}
+	}()
+	MustParse("1", "USD").Add(MustParse("1", "EUR"))
+}
+
+func TestFormatting(t *testing.T) {
+	tests := []struct {
+		money   Money
+		decimal string
+		str     string
+	}{
+		{New(123450, "USD"), "1234.50", "USD 1234.50"},
+		{New(5, "USD"), "0.05", "USD 0.05"},
+		{New(-5, "USD"), "-0.05", "USD -0.05"},
+		{Money{}, "0.00", "0.00"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.Decimal(); got != tt.decimal {
+			t.Errorf("Decimal() = %q, want %q", got, tt.decimal)
+		}
+		if got := tt.money.String(); got != tt.str {
+			t.Errorf("String() = %q, want %q", got, tt.str)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	encoded, err := json.Marshal(New(19999, "USD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != `{"amount":"199.99","currency":"USD"}` {
+		t.Errorf("Marshal = %s", encoded)
+	}
+
+	inputs := map[string]Money{
+		`{"amount":"199.99","currency":"EUR"}`: New(19999, "EUR"),
+		`{"amount":199.99,"currency":"EUR"}`:   New(19999, "EUR"),
+		`{"amount":"10"}`:                      New(1000, DefaultCurrency),
+		`199.99`:                               New(19999, DefaultCurrency),
+		`"0.1"`:                                New(10, DefaultCurrency),
+	}
+	for input, want := range inputs {
+		var got Money
+		if err := json.Unmarshal([]byte(input), &got); err != nil {
+			t.Errorf("Unmarshal(%s) unexpected error: %v", input, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("Unmarshal(%s) = %s, want %s", input, got, want)
+		}
+	}
+
+	var invalid Money
+	if err := json.Unmarshal([]byte(`"ten"`), &invalid); err == nil {
+		t.Error("expected Unmarshal of a non-numeric amount to fail")
+	}
+}
+
+func TestDatabaseRoundTrip(t *testing.T) {
+	original := New(-12345, "USD")
+	value, err := original.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != "-123.45" {
+		t.Errorf("Value() = %v, want -123.45", value)
+	}
+
+	for _, column := range []interface{}{value, []byte("-123.45"), -123.45} {
+		var scanned Money
+		if err := scanned.Scan(column); err != nil {
+			t.Fatalf("Scan(%v) unexpected error: %v", column, err)
+		}
+		if scanned != original {
+			t.Errorf("Scan(%v) = %s, want %s", column, scanned, original)
+		}
+	}
+
+	var fromInt Money
+	if err := fromInt.Scan(int64(42)); err != nil || fromInt.Minor() != 4200 {
+		t.Errorf("Scan(int64) = %s, %v, want 42.00", fromInt, err)
+	}
+	if err := fromInt.Scan(true); err == nil {
+		t.Error("expected Scan of a bool to fail")
+	}
+}
\ No newline at end of file
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// crockfordAlphabet : Crockford's base32 digits. I, L, O and U are left out so codes read out loud are not mistaken
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// GenerateCode returns a random code of the given length in Crockford base32, e.g. "7K3QX9TB"
+func GenerateCode(length int) (string, error) {
+	code := make([]byte, length)
+	max := big.NewInt(int64(len(crockfordAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate code: %w", err)
+		}
+		code[i] = crockfordAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// codeReplacer undoes the usual slips when a code is read out or typed: lower case, separators,
+// and the letters Crockford base32 leaves out because they look like digits
+var codeReplacer = strings.NewReplacer("-", "", " ", "", "O", "0", "I", "1", "L", "1")
+
+// NormalizeCode turns a code as typed by a guest, e.g. "7k3q-x9tb", into the form GenerateCode returns
+func NormalizeCode(code string) string {
+	return codeReplacer.Replace(strings.ToUpper(strings.TrimSpace(code)))
+}
\ No newline at end of file
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateCode(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		code, err := GenerateCode(8)
+		if err != nil {
+			t.Fatalf("GenerateCode unexpected error: %v", err)
+		}
+		if len(code) != 8 {
+			t.Fatalf("GenerateCode = %q, want 8 characters", code)
+		}
+		if strings.ContainsAny(code, "ILOU") || strings.Trim(code, crockfordAlphabet) != "" {
+			t.Fatalf("GenerateCode = %q, want only Crockford base32 characters", code)
+		}
+		seen[code] = true
+	}
+	if len(seen) < 990 {
+		t.Errorf("GenerateCode gave %d distinct codes out of 1000", len(seen))
+	}
+}
+
+func TestNormalizeCode(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"7K3QX9TB", "7K3QX9TB"},
+		{"7k3q-x9tb", "7K3QX9TB"},
+		{" 7K3Q X9TB ", "7K3QX9TB"},
+		{"O1LI", "0111"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeCode(tt.in); got != tt.want {
+			t.Errorf("NormalizeCode(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
\ No newline at end of file
@@ -2,20 +2,23 @@ package utils
 
 import (
 	"fmt"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/money"
 	"net/smtp"
+	"net/url"
 	"os"
 	"time"
 )
 
 type ReservationEmailData struct {
-	ID            string    `json:"id"`
-	CheckInDate   time.Time `json:"check_in_date"`
-	CheckOutDate  time.Time `json:"check_out_date"`
-	RoomNumber    int       `json:"room_number"`
-	RoomType      string    `json:"room_type"`
-	GuestName     string    `json:"guest_name"`
-	TotalPrice    float64   `json:"total_price"`
-	PaymentStatus string    `json:"payment_status"`
+	ID            string      `json:"id"`
+	Code          string      `json:"code"`
+	CheckInDate   time.Time   `json:"check_in_date"`
+	CheckOutDate  time.Time   `json:"check_out_date"`
+	RoomNumber    string      `json:"room_number"`
+	RoomType      string      `json:"room_type"`
+	GuestName     string      `json:"guest_name"`
+	TotalPrice    money.Money `json:"total_price"`
+	PaymentStatus string      `json:"payment_status"`
 
 	GuestEmail     string `json:"guest_email"`
 	SpecialRequest string `json:"special_request,omitempty"`
@@ -74,11 +77,11 @@ func SendEmailNotification(email string, reservationData ReservationEmailData) e
                         </tr>
                         <tr>
                             <td style="padding: 8px 0; color: #666;">Room Number:</td>
-                            <td style="padding: 8px 0; color: #333;">%d</td>
+                            <td style="padding: 8px 0; color: #333;">%s</td>
                         </tr>
                         <tr>
                             <td style="padding: 8px 0; color: #666;">Total Price:</td>
-                            <td style="padding: 8px 0; color: #333;">$%.2f</td>
+                            <td style="padding: 8px 0; color: #333;">%s</td>
                         </tr>
                         <tr>
                             <td style="padding: 8px 0; color: #666;">Payment Status:</td>
@@ -128,12 +131,12 @@ func SendEmailNotification(email string, reservationData ReservationEmailData) e
 		//reservationData.HotelName,
 		reservationData.GuestName,
 		//reservationData.HotelName,
-		reservationData.ID,
+		reservationData.Code,
 		formatDate(reservationData.CheckInDate),
 		formatDate(reservationData.CheckOutDate),
 		reservationData.RoomType,
 		reservationData.RoomNumber,
-		reservationData.TotalPrice,
+		reservationData.TotalPrice.String(),
 		paymentStatusColor,
 		reservationData.PaymentStatus,
 		reservationDetailsUrl,
@@ -153,5 +156,415 @@ func SendEmailNotification(email string, reservationData ReservationEmailData) e
 		return fmt.Errorf("smtp error: %s", err)
 	}
 
+	return nil
+}
+
+type CancellationEmailData struct {
+	ID            string      `json:"id"`
+	CheckInDate   time.Time   `json:"check_in_date"`
+	CheckOutDate  time.Time   `json:"check_out_date"`
+	RoomType      string      `json:"room_type"`
+	GuestName     string      `json:"guest_name"`
+	PolicyName    string      `json:"policy_name"`
+	TotalPrice    money.Money `json:"total_price"`
+	Fee           money.Money `json:"fee"`
+	Refunded      money.Money `json:"refunded"`
+	PaymentStatus string      `json:"payment_status"`
+}
+
+// SendCancellationEmail tells the guest their reservation is cancelled and what the cancellation cost
+func SendCancellationEmail(email string, data CancellationEmailData) error {
+	from := os.Getenv("EMAIL_SENDER")
+	pass := os.Getenv("EMAIL_PASSWORD")
+
+	htmlTemplate := `
+    <!DOCTYPE html>
+    <html>
+    <head>
+        <meta charset="UTF-8">
+        <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    </head>
+    <body style="margin: 0; padding: 0; font-family: Arial, sans-serif; background-color: #f4f4f4;">
+        <div style="max-width: 600px; margin: 0 auto; background-color: #ffffff; padding: 20px; border-radius: 8px; box-shadow: 0 2px 4px rgba(0,0,0,0.1);">
+            <div style="text-align: center; padding: 20px 0; border-bottom: 2px solid #f0f0f0;">
+                <h1 style="color: #2e6c80; margin: 0;">Booking Cancelled</h1>
+            </div>
+
+            <div style="padding: 20px 0;">
+                <p style="font-size: 16px; color: #333;">Dear %s,</p>
+                <p style="font-size: 16px; color: #333;">Your reservation %s for %s, %s to %s, has been cancelled.</p>
+
+                <div style="background-color: #f8f9fa; padding: 20px; border-radius: 4px; margin: 20px 0;">
+                    <h2 style="color: #2e6c80; font-size: 18px; margin-top: 0;">Cancellation Summary</h2>
+                    <table style="width: 100%%; border-collapse: collapse;">
+                        <tr>
+                            <td style="padding: 8px 0; color: #666;">Cancellation Policy:</td>
+                            <td style="padding: 8px 0; color: #333;">%s</td>
+                        </tr>
+                        <tr>
+                            <td style="padding: 8px 0; color: #666;">Total Price:</td>
+                            <td style="padding: 8px 0; color: #333;">%s</td>
+                        </tr>
+                        <tr>
+                            <td style="padding: 8px 0; color: #666;">Cancellation Fee:</td>
+                            <td style="padding: 8px 0; color: #333;">%s</td>
+                        </tr>
+                        <tr>
+                            <td style="padding: 8px 0; color: #666;">Refunded:</td>
+                            <td style="padding: 8px 0; color: #333; font-weight: bold;">%s</td>
+                        </tr>
+                        <tr>
+                            <td style="padding: 8px 0; color: #666;">Payment Status:</td>
+                            <td style="padding: 8px 0; color: #333;">%s</td>
+                        </tr>
+                    </table>
+                </div>
+            </div>
+
+            <div style="text-align: center; margin-top: 30px; padding-top: 20px; border-top: 1px solid #f0f0f0;">
+                <p style="color: #999; font-size: 12px;">If you have any questions, please contact us at:</p>
+                <p style="color: #666; font-size: 14px;">📞 Contact: <a href="tel:%s" style="color: #2e6c80; text-decoration: none;">%s</a></p>
+                <p style="color: #666; font-size: 14px;">✉️ Email: <a href="mailto:%s" style="color: #2e6c80; text-decoration: none;">%s</a></p>
+            </div>
+        </div>
+    </body>
+    </html>
+    `
+
+	msg := fmt.Sprintf("From: %s\n"+
+		"To: %s\n"+
+		"Subject: Booking Cancelled - %s\n"+
+		"MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"+
+		htmlTemplate,
+		from,
+		email,
+		data.ID,
+		data.GuestName,
+		data.ID,
+		data.RoomType,
+		formatDate(data.CheckInDate),
+		formatDate(data.CheckOutDate),
+		data.PolicyName,
+		data.TotalPrice.String(),
+		data.Fee.String(),
+		data.Refunded.String(),
+		data.PaymentStatus,
+		os.Getenv("HOTEL_CONTACT"),
+		os.Getenv("HOTEL_CONTACT"),
+		os.Getenv("HOTEL_EMAIL"),
+		os.Getenv("HOTEL_EMAIL"))
+
+	err := smtp.SendMail("smtp.gmail.com:587",
+		smtp.PlainAuth("", from, pass, "smtp.gmail.com"),
+		from,
+		[]string{email},
+		[]byte(msg))
+
+	if err != nil {
+		return fmt.Errorf("smtp error: %s", err)
+	}
+
+	return nil
+}
+
+type WaitlistOfferEmailData struct {
+	GuestName      string    `json:"guest_name"`
+	RoomType       string    `json:"room_type"`
+	CheckInDate    time.Time `json:"check_in_date"`
+	CheckOutDate   time.Time `json:"check_out_date"`
+	OfferExpiresAt time.Time `json:"offer_expires_at"`
+}
+
+// SendWaitlistOfferEmail tells a waitlisted guest a room has freed up for them and until when it is held
+func SendWaitlistOfferEmail(email string, data WaitlistOfferEmailData) error {
+	from := os.Getenv("EMAIL_SENDER")
+	pass := os.Getenv("EMAIL_PASSWORD")
+
+	htmlTemplate := `
+    <!DOCTYPE html>
+    <html>
+    <head>
+        <meta charset="UTF-8">
+        <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    </head>
+    <body style="margin: 0; padding: 0; font-family: Arial, sans-serif; background-color: #f4f4f4;">
+        <div style="max-width: 600px; margin: 0 auto; background-color: #ffffff; padding: 20px; border-radius: 8px; box-shadow: 0 2px 4px rgba(0,0,0,0.1);">
+            <div style="text-align: center; padding: 20px 0; border-bottom: 2px solid #f0f0f0;">
+                <h1 style="color: #2e6c80; margin: 0;">A Room Is Available</h1>
+            </div>
+
+            <div style="padding: 20px 0;">
+                <p style="font-size: 16px; color: #333;">Dear %s,</p>
+                <p style="font-size: 16px; color: #333;">A %s room has become available for your stay from %s to %s.</p>
+
+                <div style="background-color: #fff3cd; padding: 15px; border-radius: 4px; margin: 20px 0;">
+                    <p style="color: #856404; margin: 0;">
+                        <strong>Important:</strong> The room is held for you until %s. Book it before then to secure it.
+                    </p>
+                </div>
+            </div>
+
+            <div style="text-align: center; margin-top: 30px; padding-top: 20px; border-top: 1px solid #f0f0f0;">
+                <p style="color: #999; font-size: 12px;">If you have any questions, please contact us at:</p>
+                <p style="color: #666; font-size: 14px;">📞 Contact: <a href="tel:%s" style="color: #2e6c80; text-decoration: none;">%s</a></p>
+                <p style="color: #666; font-size: 14px;">✉️ Email: <a href="mailto:%s" style="color: #2e6c80; text-decoration: none;">%s</a></p>
+            </div>
+        </div>
+    </body>
+    </html>
+    `
+
+	msg := fmt.Sprintf("From: %s\n"+
+		"To: %s\n"+
+		"Subject: A Room Is Available - %s\n"+
+		"MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"+
+		htmlTemplate,
+		from,
+		email,
+		data.RoomType,
+		data.GuestName,
+		data.RoomType,
+		formatDate(data.CheckInDate),
+		formatDate(data.CheckOutDate),
+		data.OfferExpiresAt.Format("Monday, January 2, 2006 15:04 MST"),
+		os.Getenv("HOTEL_CONTACT"),
+		os.Getenv("HOTEL_CONTACT"),
+		os.Getenv("HOTEL_EMAIL"),
+		os.Getenv("HOTEL_EMAIL"))
+
+	err := smtp.SendMail("smtp.gmail.com:587",
+		smtp.PlainAuth("", from, pass, "smtp.gmail.com"),
+		from,
+		[]string{email},
+		[]byte(msg))
+
+	if err != nil {
+		return fmt.Errorf("smtp error: %s", err)
+	}
+
+	return nil
+}
+
+type VerificationEmailData struct {
+	Name      string    `json:"name"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SendVerificationEmail sends a new user the link that verifies their email
+func SendVerificationEmail(email string, data VerificationEmailData) error {
+	from := os.Getenv("EMAIL_SENDER")
+	pass := os.Getenv("EMAIL_PASSWORD")
+	verificationUrl := fmt.Sprintf("%s/auth/verify-email?token=%s", os.Getenv("SERVER_BASE_URL"), url.QueryEscape(data.Token))
+
+	htmlTemplate := `
+    <!DOCTYPE html>
+    <html>
+    <head>
+        <meta charset="UTF-8">
+        <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    </head>
+    <body style="margin: 0; padding: 0; font-family: Arial, sans-serif; background-color: #f4f4f4;">
+        <div style="max-width: 600px; margin: 0 auto; background-color: #ffffff; padding: 20px; border-radius: 8px; box-shadow: 0 2px 4px rgba(0,0,0,0.1);">
+            <div style="text-align: center; padding: 20px 0; border-bottom: 2px solid #f0f0f0;">
+                <h1 style="color: #2e6c80; margin: 0;">Verify Your Email</h1>
+            </div>
+
+            <div style="padding: 20px 0;">
+                <p style="font-size: 16px; color: #333;">Dear %s,</p>
+                <p style="font-size: 16px; color: #333;">Please confirm this is your email address to finish setting up your account.</p>
+
+                <div style="text-align: center; margin: 30px 0;">
+                    <a href="%s" style="background-color: #2e6c80; color: #ffffff; padding: 12px 24px; text-decoration: none; border-radius: 4px; font-weight: bold;">Verify Email</a>
+                </div>
+
+                <p style="color: #666; font-size: 14px;">The link works once, until %s. If you did not sign up, you can ignore this email.</p>
+            </div>
+
+            <div style="text-align: center; margin-top: 30px; padding-top: 20px; border-top: 1px solid #f0f0f0;">
+                <p style="color: #999; font-size: 12px;">If you have any questions, please contact us at:</p>
+                <p style="color: #666; font-size: 14px;">📞 Contact: <a href="tel:%s" style="color: #2e6c80; text-decoration: none;">%s</a></p>
+                <p style="color: #666; font-size: 14px;">✉️ Email: <a href="mailto:%s" style="color: #2e6c80; text-decoration: none;">%s</a></p>
+            </div>
+        </div>
+    </body>
+    </html>
+    `
+
+	msg := fmt.Sprintf("From: %s\n"+
+		"To: %s\n"+
+		"Subject: Verify Your Email\n"+
+		"MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"+
+		htmlTemplate,
+		from,
+		email,
+		data.Name,
+		verificationUrl,
+		data.ExpiresAt.Format("Monday, January 2, 2006 15:04 MST"),
+		os.Getenv("HOTEL_CONTACT"),
+		os.Getenv("HOTEL_CONTACT"),
+		os.Getenv("HOTEL_EMAIL"),
+		os.Getenv("HOTEL_EMAIL"))
+
+	err := smtp.SendMail("smtp.gmail.com:587",
+		smtp.PlainAuth("", from, pass, "smtp.gmail.com"),
+		from,
+		[]string{email},
+		[]byte(msg))
+
+	if err != nil {
+		return fmt.Errorf("smtp error: %s", err)
+	}
+
+	return nil
+}
+
+type PasswordResetEmailData struct {
+	Name      string    `json:"name"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SendPasswordResetEmail sends a user the link to the page where they can choose a new password
+func SendPasswordResetEmail(email string, data PasswordResetEmailData) error {
+	from := os.Getenv("EMAIL_SENDER")
+	pass := os.Getenv("EMAIL_PASSWORD")
+	resetUrl := fmt.Sprintf("%s/reset-password?token=%s", os.Getenv("WEBSITE_CLIENT_ORIGIN"), url.QueryEscape(data.Token))
+
+	htmlTemplate := `
+    <!DOCTYPE html>
+    <html>
+    <head>
+        <meta charset="UTF-8">
+        <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    </head>
+    <body style="margin: 0; padding: 0; font-family: Arial, sans-serif; background-color: #f4f4f4;">
+        <div style="max-width: 600px; margin: 0 auto; background-color: #ffffff; padding: 20px; border-radius: 8px; box-shadow: 0 2px 4px rgba(0,0,0,0.1);">
+            <div style="text-align: center; padding: 20px 0; border-bottom: 2px solid #f0f0f0;">
+                <h1 style="color: #2e6c80; margin: 0;">Reset Your Password</h1>
+            </div>
+
+            <div style="padding: 20px 0;">
+                <p style="font-size: 16px; color: #333;">Dear %s,</p>
+                <p style="font-size: 16px; color: #333;">We received a request to reset the password of your account. Choose a new password below.</p>
+
+                <div style="text-align: center; margin: 30px 0;">
+                    <a href="%s" style="background-color: #2e6c80; color: #ffffff; padding: 12px 24px; text-decoration: none; border-radius: 4px; font-weight: bold;">Reset Password</a>
+                </div>
+
+                <p style="color: #666; font-size: 14px;">The link works once, until %s. Resetting your password signs you out on all your devices. If you did not ask for this, you can ignore this email: your password stays the same.</p>
+            </div>
+
+            <div style="text-align: center; margin-top: 30px; padding-top: 20px; border-top: 1px solid #f0f0f0;">
+                <p style="color: #999; font-size: 12px;">If you have any questions, please contact us at:</p>
+                <p style="color: #666; font-size: 14px;">📞 Contact: <a href="tel:%s" style="color: #2e6c80; text-decoration: none;">%s</a></p>
+                <p style="color: #666; font-size: 14px;">✉️ Email: <a href="mailto:%s" style="color: #2e6c80; text-decoration: none;">%s</a></p>
+            </div>
+        </div>
+    </body>
+    </html>
+    `
+
+	msg := fmt.Sprintf("From: %s\n"+
+		"To: %s\n"+
+		"Subject: Reset Your Password\n"+
+		"MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"+
+		htmlTemplate,
+		from,
+		email,
+		data.Name,
+		resetUrl,
+		data.ExpiresAt.Format("Monday, January 2, 2006 15:04 MST"),
+		os.Getenv("HOTEL_CONTACT"),
+		os.Getenv("HOTEL_CONTACT"),
+		os.Getenv("HOTEL_EMAIL"),
+		os.Getenv("HOTEL_EMAIL"))
+
+	err := smtp.SendMail("smtp.gmail.com:587",
+		smtp.PlainAuth("", from, pass, "smtp.gmail.com"),
+		from,
+		[]string{email},
+		[]byte(msg))
+
+	if err != nil {
+		return fmt.Errorf("smtp error: %s", err)
+	}
+
+	return nil
+}
+
+type InviteEmailData struct {
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SendInviteEmail sends someone given an account the link to the page where they choose its password
+func SendInviteEmail(email string, data InviteEmailData) error {
+	from := os.Getenv("EMAIL_SENDER")
+	pass := os.Getenv("EMAIL_PASSWORD")
+	inviteUrl := fmt.Sprintf("%s/accept-invite?token=%s", os.Getenv("WEBSITE_CLIENT_ORIGIN"), url.QueryEscape(data.Token))
+
+	htmlTemplate := `
+    <!DOCTYPE html>
+    <html>
+    <head>
+        <meta charset="UTF-8">
+        <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    </head>
+    <body style="margin: 0; padding: 0; font-family: Arial, sans-serif; background-color: #f4f4f4;">
+        <div style="max-width: 600px; margin: 0 auto; background-color: #ffffff; padding: 20px; border-radius: 8px; box-shadow: 0 2px 4px rgba(0,0,0,0.1);">
+            <div style="text-align: center; padding: 20px 0; border-bottom: 2px solid #f0f0f0;">
+                <h1 style="color: #2e6c80; margin: 0;">You Have Been Invited</h1>
+            </div>
+
+            <div style="padding: 20px 0;">
+                <p style="font-size: 16px; color: #333;">Dear %s,</p>
+                <p style="font-size: 16px; color: #333;">An account with the role %s has been created for you. Choose your password below to start using it.</p>
+
+                <div style="text-align: center; margin: 30px 0;">
+                    <a href="%s" style="background-color: #2e6c80; color: #ffffff; padding: 12px 24px; text-decoration: none; border-radius: 4px; font-weight: bold;">Choose Password</a>
+                </div>
+
+                <p style="color: #666; font-size: 14px;">The link works once, until %s. If it expires, ask for a new one from the password reset page.</p>
+            </div>
+
+            <div style="text-align: center; margin-top: 30px; padding-top: 20px; border-top: 1px solid #f0f0f0;">
+                <p style="color: #999; font-size: 12px;">If you have any questions, please contact us at:</p>
+                <p style="color: #666; font-size: 14px;">📞 Contact: <a href="tel:%s" style="color: #2e6c80; text-decoration: none;">%s</a></p>
+                <p style="color: #666; font-size: 14px;">✉️ Email: <a href="mailto:%s" style="color: #2e6c80; text-decoration: none;">%s</a></p>
+            </div>
+        </div>
+    </body>
+    </html>
+    `
+
+	msg := fmt.Sprintf("From: %s\n"+
+		"To: %s\n"+
+		"Subject: Your Hotel Account\n"+
+		"MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"+
+		htmlTemplate,
+		from,
+		email,
+		data.Name,
+		data.Role,
+		inviteUrl,
+		data.ExpiresAt.Format("Monday, January 2, 2006 15:04 MST"),
+		os.Getenv("HOTEL_CONTACT"),
+		os.Getenv("HOTEL_CONTACT"),
+		os.Getenv("HOTEL_EMAIL"),
+		os.Getenv("HOTEL_EMAIL"))
+
+	err := smtp.SendMail("smtp.gmail.com:587",
+		smtp.PlainAuth("", from, pass, "smtp.gmail.com"),
+		from,
+		[]string{email},
+		[]byte(msg))
+
+	if err != nil {
+		return fmt.Errorf("smtp error: %s", err)
+	}
+
 	return nil
 }
\ No newline at end of file
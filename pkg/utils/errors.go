@@ -21,4 +21,10 @@ func HandleUniqueConstraintError(err error) (int, string, bool) {
 		return http.StatusConflict, fmt.Sprintf("%s already exists", pgErr.ConstraintName), true
 	}
 	return 0, "", false
+}
+
+// IsExclusionViolation reports whether err was raised by a postgres exclusion constraint
+func IsExclusionViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23P01"
 }
\ No newline at end of file
@@ -2,7 +2,9 @@ package input
 
 import (
 	"fmt"
+	"github.com/gatimugabriel/hotel-reservation-system/pkg/money"
 	"github.com/go-playground/validator/v10"
+	"reflect"
 	"regexp"
 	"strings"
 )
@@ -53,6 +55,14 @@ func ValidateStruct(data interface{}) *ValidationResponse {
 		return len(password) >= 8 && hasUpper && hasLower && hasNumber
 	})
 
+	// money amounts are validated on their minor units, so tags like "required,min=0" keep their numeric meaning
+	validate.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		if amount, ok := field.Interface().(money.Money); ok {
+			return amount.Minor()
+		}
+		return nil
+	}, money.Money{})
+
 	err := validate.Struct(data)
 	if err != nil {
 		var errors []ValidationError
@@ -3,31 +3,41 @@ package utils
 import (
 	"fmt"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"os"
 	"time"
 )
 
 var (
-	accessTokenSecret        = os.Getenv("ACCESS_TOKEN_SECRET")
-	refreshTokenSecret       = os.Getenv("REFRESH_TOKEN_SECRET")
-	passwordResetTokenSecret = os.Getenv("PASSWORD_RESET_TOKEN_SECRET")
+	accessTokenSecret            = os.Getenv("ACCESS_TOKEN_SECRET")
+	refreshTokenSecret           = os.Getenv("REFRESH_TOKEN_SECRET")
+	passwordResetTokenSecret     = os.Getenv("PASSWORD_RESET_TOKEN_SECRET")
+	emailVerificationTokenSecret = os.Getenv("EMAIL_VERIFICATION_TOKEN_SECRET")
+	oauthStateSecret             = os.Getenv("OAUTH_STATE_SECRET")
 )
 
-// GenerateTokens : generates access & refresh token
-func GenerateTokens(userID string, role any) (string, string, error) {
+// token lifetimes, also used for the lifetime of the cookies carrying them
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// GenerateTokens : generates access & refresh token of a session.
+// Both carry the session's ID as their "sid" claim; the refresh token's "jti" is refreshTokenID, the ID of its server-side record
+func GenerateTokens(userID string, role any, sessionID, refreshTokenID string) (string, string, error) {
 	userPayload := map[string]interface{}{
 		"userID": userID,
 		"role":   role,
 	}
 
 	// Generate access token
-	accessToken, err := GenerateToken(userPayload, 15*time.Minute, accessTokenSecret)
+	accessToken, err := generateSessionToken(userPayload, AccessTokenTTL, accessTokenSecret, uuid.NewString(), sessionID)
 	if err != nil {
 		return "", "", fmt.Errorf("error generating access token: %w", err)
 	}
 
 	// Generate refresh token
-	refreshToken, err := GenerateToken(userPayload, 30*24*time.Hour, refreshTokenSecret)
+	refreshToken, err := generateSessionToken(userPayload, RefreshTokenTTL, refreshTokenSecret, refreshTokenID, sessionID)
 	if err != nil {
 		return "", "", fmt.Errorf("error generating refresh token: %w", err)
 	}
@@ -37,16 +47,9 @@ func GenerateTokens(userID string, role any) (string, string, error) {
 
 // ValidateToken validate any given token with its type
 func ValidateToken(tokenString string, tokenType string) (map[string]interface{}, error) {
-	var secret []byte
-	switch tokenType {
-	case "REFRESH":
-		secret = []byte(refreshTokenSecret)
-	case "ACCESS":
-		secret = []byte(accessTokenSecret)
-	case "PASSWORD_RESET":
-		secret = []byte(passwordResetTokenSecret)
-	default:
-		return nil, fmt.Errorf("invalid token type")
+	secret, err := tokenSecret(tokenType)
+	if err != nil {
+		return nil, err
 	}
 
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
@@ -70,24 +73,76 @@ func ValidateToken(tokenString string, tokenType string) (map[string]interface{}
 			return nil, err
 		}
 
+		// session tokens also identify themselves & their session
+		for _, claim := range []string{"jti", "sid"} {
+			if value, ok := claims[claim].(string); ok {
+				decryptedPayload[claim] = value
+			}
+		}
+
 		return decryptedPayload, nil
 	}
 
 	return nil, fmt.Errorf("invalid token")
 }
 
+// tokenSecret : the secret tokens of the given type are signed with
+func tokenSecret(tokenType string) ([]byte, error) {
+	switch tokenType {
+	case "REFRESH":
+		return []byte(refreshTokenSecret), nil
+	case "ACCESS":
+		return []byte(accessTokenSecret), nil
+	case "PASSWORD_RESET":
+		return []byte(passwordResetTokenSecret), nil
+	case "EMAIL_VERIFICATION":
+		return []byte(emailVerificationTokenSecret), nil
+	case "OAUTH_STATE":
+		return []byte(oauthStateSecret), nil
+	default:
+		return nil, fmt.Errorf("invalid token type")
+	}
+}
+
+// GenerateOneTimeToken : creates a token of the given type (e.g. "EMAIL_VERIFICATION") for a user, whose "jti" claim is tokenID,
+// the ID of the server-side record that makes it single use
+func GenerateOneTimeToken(tokenType string, userID, tokenID string, tokenDuration time.Duration) (string, error) {
+	secret, err := tokenSecret(tokenType)
+	if err != nil {
+		return "", err
+	}
+	return generateSessionToken(map[string]interface{}{"userID": userID}, tokenDuration, string(secret), tokenID, "")
+}
+
+// GenerateOAuthStateToken : creates the token carrying the state & PKCE verifier of a sign in with an identity provider,
+// checked with ValidateToken(token, "OAUTH_STATE") when the provider redirects back
+func GenerateOAuthStateToken(state, verifier string, tokenDuration time.Duration) (string, error) {
+	return GenerateToken(map[string]interface{}{"state": state, "verifier": verifier}, tokenDuration, oauthStateSecret)
+}
+
 // GenerateToken : creates a JWT token on given input
 // accepts flexible object input & returns an encrypted string as the token's payload
 func GenerateToken(payload map[string]interface{}, tokenDuration time.Duration, secretKey string) (string, error) {
+	return generateSessionToken(payload, tokenDuration, secretKey, "", "")
+}
+
+// generateSessionToken : like GenerateToken, adding the token's ID (jti) & session ID (sid) claims when given
+func generateSessionToken(payload map[string]interface{}, tokenDuration time.Duration, secretKey, tokenID, sessionID string) (string, error) {
 	encryptedPayload, err := EncryptPayload(payload)
 	if err != nil {
 		return "", fmt.Errorf("error encrypting payload: %w", err)
 	}
 
-	claims := &jwt.MapClaims{
+	claims := jwt.MapClaims{
 		"data": encryptedPayload,
 		"exp":  time.Now().Add(tokenDuration).Unix(),
 	}
+	if tokenID != "" {
+		claims["jti"] = tokenID
+	}
+	if sessionID != "" {
+		claims["sid"] = sessionID
+	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(secretKey))